@@ -0,0 +1,483 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/bensonfx/mcp-liner/internal/audit"
+	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/bensonfx/mcp-liner/tools"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/phuslu/log"
+	"github.com/spf13/cobra"
+)
+
+// exitError 携带进程退出码的错误，错误信息已输出，main 只需按退出码退出
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// generateCmd 离线生成配置的父命令
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "离线生成 liner 配置，输出到 stdout",
+}
+
+// validateCmd 离线验证配置文件
+var validateCmd = &cobra.Command{
+	Use:   "validate [file...]",
+	Short: "验证 liner 配置文件，验证失败时以非零状态退出",
+	Long:  "验证 liner 配置文件。未指定文件或文件为 - 时从 stdin 读取。",
+	RunE:  runValidate,
+}
+
+var (
+	// validateCheckFiles 是否检查配置引用的文件
+	validateCheckFiles bool
+	// validateBaseDir 相对路径的基准目录
+	validateBaseDir string
+)
+
+// mergeBase merge 命令的基础配置文件
+var mergeBase string
+
+// mergeCmd 离线合并配置片段
+var mergeCmd = &cobra.Command{
+	Use:   "merge [--base file] <fragment>...",
+	Short: "将多个配置片段合并为一个 liner 配置，存在冲突时以非零状态退出",
+	Long:  "将多个配置片段合并为一个 liner 配置。文件为 - 时从 stdin 读取。",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var params tools.MergeLinerConfigParams
+		if mergeBase != "" {
+			data, err := readInput(cmd, mergeBase)
+			if err != nil {
+				return err
+			}
+			params.BaseConfig = string(data)
+		}
+		for _, name := range args {
+			data, err := readInput(cmd, name)
+			if err != nil {
+				return err
+			}
+			params.Fragments = append(params.Fragments, string(data))
+		}
+		return runTool(cmd, tools.MergeLinerConfig, params)
+	},
+}
+
+// patchOps、patchDiff patch 命令的参数
+var (
+	patchOps  []tools.PatchOperationParams
+	patchDiff bool
+)
+
+// patchCmd 离线修改配置文件
+var patchCmd = &cobra.Command{
+	Use:   "patch <file> --ops <json>",
+	Short: "对配置文件应用修改操作并输出新配置，修改后验证失败时以非零状态退出",
+	Long: `对配置文件应用修改操作并输出新配置。文件为 - 时从 stdin 读取。
+
+示例:
+  mcp-liner patch config.yaml --ops '[{"op":"replace","path":"tunnel[1].dialer","value":"cloud2"}]'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := readInput(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		params := tools.PatchLinerConfigParams{
+			ConfigContent: string(data),
+			Operations:    patchOps,
+		}
+		if !patchDiff {
+			return runTool(cmd, tools.PatchLinerConfig, params)
+		}
+
+		// 仅输出差异
+		cmd.SilenceUsage = true
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to marshal parameters: %w", err)
+		}
+		result, err := tools.PatchLinerConfig(raw)
+		if err != nil {
+			return err
+		}
+		output, ok := result.Structured.(responses.PatchOutput)
+		if !ok {
+			fmt.Fprintln(cmd.ErrOrStderr(), result.Text())
+			return &exitError{code: 1}
+		}
+		fmt.Fprint(cmd.OutOrStdout(), output.Diff)
+		if result.IsError {
+			return &exitError{code: 1}
+		}
+		return nil
+	},
+}
+
+// diffCmd 离线比较两份配置文件
+var diffCmd = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "按配置项比较两份 liner 配置，输出新增、删除和修改的设置",
+	Long:  "按配置项比较两份 liner 配置，列表条目按监听地址、server_name、remote_listen 匹配。文件为 - 时从 stdin 读取。",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldData, err := readInput(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		newData, err := readInput(cmd, args[1])
+		if err != nil {
+			return err
+		}
+		return runTool(cmd, tools.DiffLinerConfig, tools.DiffLinerConfigParams{
+			OldConfig: string(oldData),
+			NewConfig: string(newData),
+		})
+	},
+}
+
+// auditBaseDir audit 命令读取认证表的基准目录
+var auditBaseDir string
+
+// auditCmd 离线审计配置文件
+var auditCmd = &cobra.Command{
+	Use:   "audit <file>",
+	Short: "审计 liner 配置的安全暴露面，存在 critical 或 high 级别的发现时以非零状态退出",
+	Long:  "审计 liner 配置的安全暴露面。配置引用的本地认证表会被读取以检查弱密码，相对路径默认相对于配置文件所在目录。文件为 - 时从 stdin 读取。",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		data, err := readInput(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		baseDir := auditBaseDir
+		if baseDir == "" && args[0] != "-" {
+			baseDir = filepath.Dir(args[0])
+		}
+
+		params := tools.AuditLinerConfigParams{ConfigContent: string(data)}
+		// 配置无法解析时由工具报告错误
+		if cfg, err := config.FromYAML(params.ConfigContent); err == nil {
+			for _, path := range audit.AuthTablePaths(cfg) {
+				name := path
+				if !filepath.IsAbs(name) {
+					name = filepath.Join(baseDir, name)
+				}
+				content, err := os.ReadFile(name)
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: skipping password check for %s: %v\n", path, err)
+					continue
+				}
+				if params.AuthTables == nil {
+					params.AuthTables = map[string]string{}
+				}
+				params.AuthTables[path] = string(content)
+			}
+		}
+
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to marshal parameters: %w", err)
+		}
+		result, err := tools.AuditLinerConfig(raw)
+		if err != nil {
+			return err
+		}
+		output, ok := result.Structured.(responses.AuditOutput)
+		if !ok {
+			fmt.Fprintln(cmd.ErrOrStderr(), result.Text())
+			return &exitError{code: 1}
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), result.Text())
+		for _, f := range output.Findings {
+			if f.Level == audit.LevelCritical || f.Level == audit.LevelHigh {
+				return &exitError{code: 1}
+			}
+		}
+		return nil
+	},
+}
+
+// docsCmd 离线查询文档
+var docsCmd = &cobra.Command{
+	Use:       "docs <topic>",
+	Short:     "查询 liner 文档",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: tools.DocTopics,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTool(cmd, tools.QueryLinerDocs, tools.QueryLinerDocsParams{Topic: args[0]})
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(
+		newGenerateCmd[tools.GenerateLinerConfigParams]("liner", "生成完整的 liner 配置（基于场景模板）", tools.GenerateLinerConfigInputSchema, tools.GenerateLinerConfig),
+		newGenerateCmd[tools.GenerateGlobalConfigParams]("global", "生成全局配置", tools.GenerateGlobalConfigInputSchema, tools.GenerateGlobalConfig),
+		newGenerateCmd[tools.GenerateHTTPConfigParams]("http", "生成 HTTP/HTTPS 配置", tools.GenerateHTTPConfigInputSchema, tools.GenerateHTTPConfig),
+		newGenerateCmd[tools.GenerateTunnelConfigParams]("tunnel", "生成隧道配置", tools.GenerateTunnelConfigInputSchema, tools.GenerateTunnelConfig),
+		newGenerateCmd[tools.GenerateDNSConfigParams]("dns", "生成 DNS 配置", tools.GenerateDNSConfigInputSchema, tools.GenerateDNSConfig),
+		newGenerateCmd[tools.GenerateDialerConfigParams]("dialer", "生成拨号器配置", tools.GenerateDialerConfigInputSchema, tools.GenerateDialerConfig),
+		newGenerateCmd[tools.GeneratePolicyExamplesParams]("policy", "生成 Policy 模板示例", tools.GeneratePolicyExamplesInputSchema, tools.GeneratePolicyExamples),
+		newGenerateCmd[tools.GenerateRedsocksConfigParams]("redsocks", "生成 Redsocks 透明代理配置", tools.GenerateRedsocksConfigInputSchema, tools.GenerateRedsocksConfig),
+		newGenerateCmd[tools.GenerateRedsocksIptablesParams]("iptables", "生成 Redsocks 防火墙规则", tools.GenerateRedsocksIptablesInputSchema, tools.GenerateRedsocksIptables),
+		newGenerateCmd[tools.GenerateSniConfigParams]("sni", "生成 SNI 路由配置", tools.GenerateSniConfigInputSchema, tools.GenerateSniConfig),
+		newGenerateCmd[tools.GenerateStreamConfigParams]("stream", "生成 Stream 转发配置", tools.GenerateStreamConfigInputSchema, tools.GenerateStreamConfig),
+		newGenerateCmd[tools.GenerateWebshellConfigParams]("webshell", "生成 Web Shell 配置", tools.GenerateWebshellConfigInputSchema, tools.GenerateWebshellConfig),
+		newGenerateCmd[tools.GenerateAuthUserConfigParams]("auth", "生成用户认证表 CSV", tools.GenerateAuthUserConfigInputSchema, tools.GenerateAuthUserConfig),
+		newGenerateCmd[tools.GenerateSSHConfigParams]("ssh", "生成 SSH 服务配置", tools.GenerateSSHConfigInputSchema, tools.GenerateSSHConfig),
+	)
+
+	validateCmd.Flags().BoolVar(&validateCheckFiles, "check-files", false, "检查配置引用的证书、私钥、认证表和目录")
+	validateCmd.Flags().StringVar(&validateBaseDir, "base-dir", "", "相对路径的基准目录，默认为配置文件所在目录")
+	mergeCmd.Flags().StringVar(&mergeBase, "base", "", "基础配置文件")
+	auditCmd.Flags().StringVar(&auditBaseDir, "base-dir", "", "认证表相对路径的基准目录，默认为配置文件所在目录")
+	patchCmd.Flags().Var(&jsonFlag{target: &patchOps}, "ops", "修改操作列表 (JSON)")
+	patchCmd.Flags().BoolVar(&patchDiff, "diff", false, "只输出修改前后的差异")
+	_ = patchCmd.MarkFlagRequired("ops")
+
+	for _, cmd := range []*cobra.Command{generateCmd, validateCmd, mergeCmd, patchCmd, diffCmd, auditCmd, docsCmd} {
+		// 离线命令的输出面向脚本，结果和错误已写到 stdout/stderr，只保留错误日志
+		cmd.PersistentPreRun = func(*cobra.Command, []string) {
+			log.DefaultLogger.SetLevel(log.ErrorLevel)
+		}
+		rootCmd.AddCommand(cmd)
+	}
+}
+
+// newGenerateCmd 根据工具参数结构体 P 创建 generate 子命令
+// P 的每个字段对应一个命令行参数，参数名为 json 名称（下划线替换为连字符）
+func newGenerateCmd[P any](name, short string, schema func() *jsonschema.Schema, handler func(json.RawMessage) (*responses.Result, error)) *cobra.Command {
+	var params P
+
+	cmd := &cobra.Command{
+		Use:   name,
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runTool(cmd, handler, params)
+		},
+	}
+	bindParamFlags(cmd, &params, schema())
+
+	return cmd
+}
+
+// runTool 调用工具并将结果写到 stdout，工具返回错误时写到 stderr 并返回 exitError
+func runTool(cmd *cobra.Command, handler func(json.RawMessage) (*responses.Result, error), params any) error {
+	cmd.SilenceUsage = true
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to marshal parameters: %w", err)
+	}
+
+	result, err := handler(data)
+	if err != nil {
+		return err
+	}
+
+	if result.IsError {
+		fmt.Fprintln(cmd.ErrOrStderr(), result.Text())
+		return &exitError{code: 1}
+	}
+
+	return writeOutput(cmd.OutOrStdout(), result)
+}
+
+// writeOutput 输出工具结果的主体内容（YAML、CSV、规则或文档），不包含说明文字
+func writeOutput(w io.Writer, result *responses.Result) error {
+	var content string
+	switch out := result.Structured.(type) {
+	case responses.ConfigOutput:
+		content = out.YAML
+	case responses.PatchOutput:
+		content = out.YAML
+	case responses.DiffOutput:
+		// 配置相同时不输出任何内容
+		if out.Identical {
+			return nil
+		}
+		content = out.Summary
+	case responses.ContentOutput:
+		content = out.Content
+	case responses.DocumentationOutput:
+		content = out.Content
+	default:
+		content = result.Text()
+	}
+
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	_, err := io.WriteString(w, content)
+	return err
+}
+
+// runValidate 逐个验证配置文件，任意文件验证失败时返回 exitError
+func runValidate(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+
+	failed := false
+	for _, name := range args {
+		data, err := readInput(cmd, name)
+		if err != nil {
+			return err
+		}
+
+		// 相对路径默认相对于配置文件所在目录
+		baseDir := validateBaseDir
+		if baseDir == "" && name != "-" {
+			baseDir = filepath.Dir(name)
+		}
+		params, err := json.Marshal(tools.ValidateLinerConfigParams{
+			ConfigContent: string(data),
+			CheckFiles:    validateCheckFiles,
+			BaseDir:       baseDir,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal parameters: %w", err)
+		}
+		result, err := tools.ValidateLinerConfig(params)
+		if err != nil {
+			return err
+		}
+
+		output, ok := result.Structured.(responses.ValidationOutput)
+		if !ok {
+			// 非验证结果（如参数错误）
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s\n", name, result.Text())
+			failed = true
+			continue
+		}
+		if !output.Valid {
+			failed = true
+		}
+		// 与编译器相同的 file:line:column 格式，便于编辑器跳转
+		for _, e := range output.Errors {
+			location := name
+			if e.Line > 0 {
+				location = fmt.Sprintf("%s:%d:%d", name, e.Line, e.Column)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s: %s: %s [%s]\n", location, e.Severity, e.Field, e.Message, e.Code)
+		}
+		if output.Valid {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: ok\n", name)
+		}
+	}
+
+	if failed {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+// readInput 读取文件内容，文件名为 - 时从 stdin 读取
+func readInput(cmd *cobra.Command, name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(cmd.InOrStdin())
+	}
+	return os.ReadFile(name)
+}
+
+// bindParamFlags 为参数结构体的每个字段注册命令行参数
+// 基本类型直接绑定，其余类型（map、结构体切片）以 JSON 字符串传入
+func bindParamFlags(cmd *cobra.Command, params any, schema *jsonschema.Schema) {
+	v := reflect.ValueOf(params).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "" || jsonName == "-" {
+			continue
+		}
+		name := strings.ReplaceAll(jsonName, "_", "-")
+		usage := flagUsage(field.Tag.Get("jsonschema"), schema.Properties[jsonName])
+
+		flags := cmd.Flags()
+		switch p := v.Field(i).Addr().Interface().(type) {
+		case *string:
+			flags.StringVar(p, name, "", usage)
+		case *[]string:
+			flags.StringSliceVar(p, name, nil, usage)
+		case *bool:
+			flags.BoolVar(p, name, false, usage)
+		case *int:
+			flags.IntVar(p, name, 0, usage)
+		case *int64:
+			flags.Int64Var(p, name, 0, usage)
+		case *uint:
+			flags.UintVar(p, name, 0, usage)
+		case *[]int:
+			flags.IntSliceVar(p, name, nil, usage)
+		default:
+			flags.Var(&jsonFlag{target: p}, name, usage+" (JSON)")
+		}
+
+		for _, required := range schema.Required {
+			if required == jsonName {
+				_ = cmd.MarkFlagRequired(name)
+			}
+		}
+	}
+}
+
+// flagUsage 根据字段描述和schema生成参数说明，附带可选值和默认值
+func flagUsage(description string, prop *jsonschema.Schema) string {
+	if prop == nil {
+		return description
+	}
+	if len(prop.Enum) > 0 {
+		values := make([]string, 0, len(prop.Enum))
+		for _, e := range prop.Enum {
+			values = append(values, fmt.Sprint(e))
+		}
+		description += " [" + strings.Join(values, "|") + "]"
+	}
+	if len(prop.Default) > 0 {
+		description += " (default " + string(prop.Default) + ")"
+	}
+	return description
+}
+
+// jsonFlag 以 JSON 字符串形式设置的命令行参数
+type jsonFlag struct {
+	target any
+	value  string
+}
+
+func (f *jsonFlag) String() string {
+	return f.value
+}
+
+func (f *jsonFlag) Set(value string) error {
+	if err := json.Unmarshal([]byte(value), f.target); err != nil {
+		return errors.New("invalid JSON: " + err.Error())
+	}
+	f.value = value
+	return nil
+}
+
+func (f *jsonFlag) Type() string {
+	return "json"
+}
@@ -1,13 +1,22 @@
 package main
 
-import "C"
+import (
+	"C"
+	"errors"
+	"fmt"
+	"os"
+)
 
 //export Run
 func Run() {
 	if err := rootCmd.Execute(); err != nil {
 		// In a C-shared library, we probably shouldn't os.Exit(1) directly if we want to handle errors gracefully in Python,
-		// but since the original main calls os.Exit, we'll keep it simple for now or just let it return.
-		// However, cobra's Execute prints errors to stderr by default.
+		// so we just report the error and return. rootCmd silences cobra's own error output, and
+		// exitError messages have already been written by the subcommand.
+		var exitErr *exitError
+		if !errors.As(err, &exitErr) {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
 		return
 	}
 }
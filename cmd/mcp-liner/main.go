@@ -5,12 +5,14 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 
+	"github.com/bensonfx/mcp-liner/internal/responses"
 	"github.com/bensonfx/mcp-liner/tools"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/phuslu/log"
@@ -25,6 +27,9 @@ var (
 	appVersion = "0.0.0"
 	cancelFunc context.CancelFunc
 	mu         sync.Mutex
+
+	transportType string
+	listenAddr    string
 )
 
 var rootCmd = &cobra.Command{
@@ -33,9 +38,14 @@ var rootCmd = &cobra.Command{
 	Long:    `mcp-liner 是一个 MCP Server，用于生成和管理 liner 配置文件。`,
 	Version: appVersion,
 	Run:     runServer,
+	// 错误由 main 统一输出，避免重复打印
+	SilenceErrors: true,
 }
 
 func init() {
+	rootCmd.Flags().StringVar(&transportType, "transport", transportStdio, "传输方式: stdio|http|sse")
+	rootCmd.Flags().StringVar(&listenAddr, "listen", "127.0.0.1:8080", "http/sse 传输的监听地址")
+
 	// 设置日志
 	log.DefaultLogger = log.Logger{
 		Level:      log.InfoLevel,
@@ -50,8 +60,9 @@ func init() {
 }
 
 // wrapToolHandler 包装工具处理函数以符合MCP SDK的类型要求
-func wrapToolHandler(handler func(json.RawMessage) (string, error)) mcp.ToolHandlerFor[map[string]interface{}, interface{}] {
-	return func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]interface{}]) (*mcp.CallToolResultFor[interface{}], error) {
+// In 为工具的参数结构体，SDK 会按其输入schema解析和校验参数
+func wrapToolHandler[In any](handler func(json.RawMessage) (*responses.Result, error)) mcp.ToolHandlerFor[In, interface{}] {
+	return func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[In]) (*mcp.CallToolResultFor[interface{}], error) {
 		// 将参数转换为JSON
 		jsonData, err := json.Marshal(params.Arguments)
 		if err != nil {
@@ -65,20 +76,11 @@ func wrapToolHandler(handler func(json.RawMessage) (string, error)) mcp.ToolHand
 		}
 
 		// 返回结果
-		return &mcp.CallToolResultFor[interface{}]{
-			Content: []mcp.Content{
-				&mcp.TextContent{
-					Text: result,
-				},
-			},
-		}, nil
+		return result.CallToolResult(), nil
 	}
 }
 
-func runServer(cmd *cobra.Command, args []string) {
-	log.Info().Str("version", appVersion).Msg("starting mcp-liner server")
-
-	// 创建 MCP server
+func newServer() *mcp.Server {
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    appName,
 		Version: appVersion,
@@ -91,97 +93,174 @@ func runServer(cmd *cobra.Command, args []string) {
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_liner_config",
 		Description: "生成完整的 liner 配置文件，支持多种场景模板（http_forward, tunnel_server, tunnel_client, dns, full）",
-	}, wrapToolHandler(tools.GenerateLinerConfig))
+		InputSchema: tools.GenerateLinerConfigInputSchema(),
+	}, wrapToolHandler[tools.GenerateLinerConfigParams](tools.GenerateLinerConfig))
 
 	// 2. validate_liner_config - 验证配置文件
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "validate_liner_config",
-		Description: "验证 liner 配置文件的正确性，检查语法和逻辑错误",
-	}, wrapToolHandler(tools.ValidateLinerConfig))
+		Description: "验证 liner 配置文件的正确性，检查语法、未知键、类型和逻辑错误，并给出带严重程度、规则代码、行列号和修复建议的发现",
+		InputSchema: tools.ValidateLinerConfigInputSchema(),
+	}, wrapToolHandler[tools.ValidateLinerConfigParams](tools.ValidateLinerConfig))
 
 	// 3. generate_global_config - 生成全局配置
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_global_config",
 		Description: "生成 liner 全局配置（日志、DNS、连接池等设置）",
-	}, wrapToolHandler(tools.GenerateGlobalConfig))
+		InputSchema: tools.GenerateGlobalConfigInputSchema(),
+	}, wrapToolHandler[tools.GenerateGlobalConfigParams](tools.GenerateGlobalConfig))
 
 	// 4. generate_http_config - 生成 HTTP/HTTPS 转发配置
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_http_config",
 		Description: "生成 HTTP/HTTPS 转发配置，支持 forward 策略、tunnel 和 web 服务",
-	}, wrapToolHandler(tools.GenerateHTTPConfig))
+		InputSchema: tools.GenerateHTTPConfigInputSchema(),
+	}, wrapToolHandler[tools.GenerateHTTPConfigParams](tools.GenerateHTTPConfig))
 
 	// 5. generate_tunnel_config - 生成内网穿透配置
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_tunnel_config",
 		Description: "生成内网穿透配置，支持 server（服务端）和 client（客户端）两种角色",
-	}, wrapToolHandler(tools.GenerateTunnelConfig))
+		InputSchema: tools.GenerateTunnelConfigInputSchema(),
+	}, wrapToolHandler[tools.GenerateTunnelConfigParams](tools.GenerateTunnelConfig))
 
 	// 6. generate_dns_config - 生成 DNS 配置
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_dns_config",
 		Description: "生成 DNS 服务配置，支持 DNS/DoT/DoH，可配置策略和上游服务器",
-	}, wrapToolHandler(tools.GenerateDNSConfig))
+		InputSchema: tools.GenerateDNSConfigInputSchema(),
+	}, wrapToolHandler[tools.GenerateDNSConfigParams](tools.GenerateDNSConfig))
 
 	// 7. generate_dialer_config - 生成代理拨号器配置
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_dialer_config",
 		Description: "生成代理拨号器配置，支持 local、socks5、http2、http3、ssh、wss 等类型",
-	}, wrapToolHandler(tools.GenerateDialerConfig))
+		InputSchema: tools.GenerateDialerConfigInputSchema(),
+	}, wrapToolHandler[tools.GenerateDialerConfigParams](tools.GenerateDialerConfig))
 
 	// 8. query_liner_docs - 查询 liner 文档
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "query_liner_docs",
 		Description: "查询 liner 文档和使用说明，支持按主题查询（global, http, tunnel, dns, dialer, policy）",
-	}, wrapToolHandler(tools.QueryLinerDocs))
+		InputSchema: tools.QueryLinerDocsInputSchema(),
+	}, wrapToolHandler[tools.QueryLinerDocsParams](tools.QueryLinerDocs))
 
 	// 9. generate_policy_examples - 生成 Policy 模板示例
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_policy_examples",
 		Description: "生成 Policy 模板示例和文档，支持 GeoIP、Geosite、域名匹配、IP范围、文件匹配等多种路由策略",
-	}, wrapToolHandler(tools.GeneratePolicyExamples))
+		InputSchema: tools.GeneratePolicyExamplesInputSchema(),
+	}, wrapToolHandler[tools.GeneratePolicyExamplesParams](tools.GeneratePolicyExamples))
 
 	// 10. generate_redsocks_config - 生成 Redsocks 透明代理配置
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_redsocks_config",
-		Description: "生成 Redsocks 透明代理配置（仅限Linux），支持通过 iptables 重定向 TCP 流量",
-	}, wrapToolHandler(tools.GenerateRedsocksConfig))
+		Description: "生成 Redsocks 透明代理配置（仅限Linux），通过 iptables REDIRECT 代理 TCP 流量，不支持 TPROXY 和 UDP",
+		InputSchema: tools.GenerateRedsocksConfigInputSchema(),
+	}, wrapToolHandler[tools.GenerateRedsocksConfigParams](tools.GenerateRedsocksConfig))
 
 	// 11. generate_redsocks_iptables - 生成 Redsocks iptables 规则
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_redsocks_iptables",
-		Description: "生成 Redsocks 透明代理的 iptables 规则，支持 iptables-save 和 shell 脚本两种格式，包含路由循环防护",
-	}, wrapToolHandler(tools.GenerateRedsocksIptables))
+		Description: "生成 Redsocks 透明代理的 iptables 规则，支持 iptables-save、ip6tables-save、shell 脚本和 nftables 格式，使用 REDIRECT 仅代理 TCP，包含路由循环防护",
+		InputSchema: tools.GenerateRedsocksIptablesInputSchema(),
+	}, wrapToolHandler[tools.GenerateRedsocksIptablesParams](tools.GenerateRedsocksIptables))
 
 	// 12. generate_sni_config - 生成 SNI 路由配置
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_sni_config",
 		Description: "生成 SNI（Server Name Indication）路由配置，支持基于 TLS ClientHello 的流量路由",
-	}, wrapToolHandler(tools.GenerateSniConfig))
+		InputSchema: tools.GenerateSniConfigInputSchema(),
+	}, wrapToolHandler[tools.GenerateSniConfigParams](tools.GenerateSniConfig))
 
 	// 13. generate_stream_config - 生成 Stream 转发配置
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_stream_config",
 		Description: "生成 Stream 转发配置，支持 TCP/TLS 端口转发和 PROXY 协议",
-	}, wrapToolHandler(tools.GenerateStreamConfig))
+		InputSchema: tools.GenerateStreamConfigInputSchema(),
+	}, wrapToolHandler[tools.GenerateStreamConfigParams](tools.GenerateStreamConfig))
 
 	// 14. generate_webshell_config - 生成 Web Shell 配置
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_webshell_config",
 		Description: "生成 Web Shell 配置，支持通过浏览器访问终端，可配置命令和认证",
-	}, wrapToolHandler(tools.GenerateWebshellConfig))
+		InputSchema: tools.GenerateWebshellConfigInputSchema(),
+	}, wrapToolHandler[tools.GenerateWebshellConfigParams](tools.GenerateWebshellConfig))
 
 	// 15. generate_auth_user_config - 生成用户认证配置
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_auth_user_config",
-		Description: "生成 auth_user.csv 用户认证配置，包含用户名、密码和权限设置",
-	}, wrapToolHandler(tools.GenerateAuthUserConfig))
+		Description: "生成 auth_user.csv 用户认证配置，包含用户名、密码、权限设置和额外属性列，可为空密码生成随机密码",
+		InputSchema: tools.GenerateAuthUserConfigInputSchema(),
+	}, wrapToolHandler[tools.GenerateAuthUserConfigParams](tools.GenerateAuthUserConfig))
 
 	// 16. generate_ssh_config - 生成 SSH Server 配置
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "generate_ssh_config",
 		Description: "生成 SSH Server 配置，支持端口转发、Shell访问和密钥认证",
-	}, wrapToolHandler(tools.GenerateSSHConfig))
+		InputSchema: tools.GenerateSSHConfigInputSchema(),
+	}, wrapToolHandler[tools.GenerateSSHConfigParams](tools.GenerateSSHConfig))
+
+	// 17. merge_liner_config - 合并配置片段
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "merge_liner_config",
+		Description: "将 generate_* 工具生成的多个配置片段合并为一个完整的 liner 配置，列表配置段追加去重，dialer 合并，并报告拨号器和监听地址冲突",
+		InputSchema: tools.MergeLinerConfigInputSchema(),
+	}, wrapToolHandler[tools.MergeLinerConfigParams](tools.MergeLinerConfig))
+
+	// 18. patch_liner_config - 修改现有配置
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "patch_liner_config",
+		Description: "对现有 liner 配置应用 add/replace/remove 操作（路径如 https[0].web 或 JSON Pointer），重新验证并返回新配置和差异",
+		InputSchema: tools.PatchLinerConfigInputSchema(),
+	}, wrapToolHandler[tools.PatchLinerConfigParams](tools.PatchLinerConfig))
+
+	// 19. diff_liner_config - 比较两份配置
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "diff_liner_config",
+		Description: "按配置项比较两份 liner 配置，列表条目按监听地址、server_name、remote_listen 匹配，返回新增、删除和修改的设置",
+		InputSchema: tools.DiffLinerConfigInputSchema(),
+	}, wrapToolHandler[tools.DiffLinerConfigParams](tools.DiffLinerConfig))
+
+	// 20. simulate_policy - 模拟执行policy模板
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "simulate_policy",
+		Description: "用离线数据模拟执行 policy 模板（内联或取自配置），返回每个模拟请求选择的拨号器或动作，并标记配置中未定义的拨号器",
+		InputSchema: tools.SimulatePolicyInputSchema(),
+	}, wrapToolHandler[tools.SimulatePolicyParams](tools.SimulatePolicy))
+
+	// 21. audit_liner_config - 安全审计
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "audit_liner_config",
+		Description: "审计 liner 配置的安全暴露面：开放代理、无认证的隧道/web shell/WebDAV/SSH、明文凭据、psk 复用、弱密码和 TLS 1.1，返回评分和按风险排序的修复建议",
+		InputSchema: tools.AuditLinerConfigInputSchema(),
+	}, wrapToolHandler[tools.AuditLinerConfigParams](tools.AuditLinerConfig))
+
+	// 22. manage_auth_table - 管理已有的用户认证表
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "manage_auth_table",
+		Description: "修改已有的 auth_user.csv：添加、更新、删除用户，批量轮换密码和修改权限（allow_tunnel/allow_ssh/allow_webdav、speed_limit），保持原有列顺序并返回变更摘要",
+		InputSchema: tools.ManageAuthTableInputSchema(),
+	}, wrapToolHandler[tools.ManageAuthTableParams](tools.ManageAuthTable))
+
+	// 注册文档和Policy示例资源
+	addResources(server)
+
+	// 注册部署场景提示
+	addPrompts(server)
+
+	return server
+}
+
+func runServer(cmd *cobra.Command, args []string) {
+	log.Info().Str("version", appVersion).Str("transport", transportType).Msg("starting mcp-liner server")
+
+	if !isValidTransport(transportType) {
+		log.Error().Str("transport", transportType).Msg("unknown transport, must be one of: stdio, http, sse")
+		os.Exit(1)
+	}
+
+	server := newServer()
 
 	// 创建一个可以被信号取消的 context
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
@@ -193,8 +272,8 @@ func runServer(cmd *cobra.Command, args []string) {
 
 	// 运行服务器
 	log.Info().Msg("mcp server is running, waiting for connections...")
-	if err := server.Run(ctx, mcp.NewStdioTransport()); err != nil {
-		if err != context.Canceled {
+	if err := serve(ctx, server, transportType, listenAddr); err != nil {
+		if !errors.Is(err, context.Canceled) {
 			log.Error().Err(err).Msg("server error")
 			os.Exit(1)
 		}
@@ -204,7 +283,12 @@ func runServer(cmd *cobra.Command, args []string) {
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		// exitError 的错误信息已由子命令输出
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err)
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/tools"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connectInMemory 通过内存传输连接到 newServer 创建的 server
+func connectInMemory(t *testing.T) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := newServer().Connect(ctx, serverTransport); err != nil {
+		t.Fatalf("server.Connect() error: %v", err)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	session, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("client.Connect() error: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func TestToolInputSchemasMatchParams(t *testing.T) {
+	paramTypes := map[string]reflect.Type{
+		"generate_liner_config":      reflect.TypeFor[tools.GenerateLinerConfigParams](),
+		"validate_liner_config":      reflect.TypeFor[tools.ValidateLinerConfigParams](),
+		"generate_global_config":     reflect.TypeFor[tools.GenerateGlobalConfigParams](),
+		"generate_http_config":       reflect.TypeFor[tools.GenerateHTTPConfigParams](),
+		"generate_tunnel_config":     reflect.TypeFor[tools.GenerateTunnelConfigParams](),
+		"generate_dns_config":        reflect.TypeFor[tools.GenerateDNSConfigParams](),
+		"generate_dialer_config":     reflect.TypeFor[tools.GenerateDialerConfigParams](),
+		"query_liner_docs":           reflect.TypeFor[tools.QueryLinerDocsParams](),
+		"generate_policy_examples":   reflect.TypeFor[tools.GeneratePolicyExamplesParams](),
+		"generate_redsocks_config":   reflect.TypeFor[tools.GenerateRedsocksConfigParams](),
+		"generate_redsocks_iptables": reflect.TypeFor[tools.GenerateRedsocksIptablesParams](),
+		"generate_sni_config":        reflect.TypeFor[tools.GenerateSniConfigParams](),
+		"generate_stream_config":     reflect.TypeFor[tools.GenerateStreamConfigParams](),
+		"generate_webshell_config":   reflect.TypeFor[tools.GenerateWebshellConfigParams](),
+		"generate_auth_user_config":  reflect.TypeFor[tools.GenerateAuthUserConfigParams](),
+		"generate_ssh_config":        reflect.TypeFor[tools.GenerateSSHConfigParams](),
+		"merge_liner_config":         reflect.TypeFor[tools.MergeLinerConfigParams](),
+		"patch_liner_config":         reflect.TypeFor[tools.PatchLinerConfigParams](),
+		"diff_liner_config":          reflect.TypeFor[tools.DiffLinerConfigParams](),
+		"simulate_policy":            reflect.TypeFor[tools.SimulatePolicyParams](),
+		"audit_liner_config":         reflect.TypeFor[tools.AuditLinerConfigParams](),
+		"manage_auth_table":          reflect.TypeFor[tools.ManageAuthTableParams](),
+	}
+
+	session := connectInMemory(t)
+	list, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools() error: %v", err)
+	}
+
+	if len(list.Tools) != len(paramTypes) {
+		t.Errorf("server advertises %d tools, want %d", len(list.Tools), len(paramTypes))
+	}
+
+	for _, tool := range list.Tools {
+		t.Run(tool.Name, func(t *testing.T) {
+			typ, ok := paramTypes[tool.Name]
+			if !ok {
+				t.Fatalf("no params struct registered in test for tool %q", tool.Name)
+			}
+			checkSchemaMatchesStruct(t, tool.InputSchema, typ)
+		})
+	}
+}
+
+// checkSchemaMatchesStruct 检查schema的属性与结构体的json字段一一对应，且都有描述
+func checkSchemaMatchesStruct(t *testing.T, s *jsonschema.Schema, typ reflect.Type) {
+	t.Helper()
+
+	if s == nil || s.Type != "object" {
+		t.Fatalf("input schema for %s is not an object schema", typ)
+	}
+
+	var fields []string
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		fields = append(fields, name)
+
+		prop, ok := s.Properties[name]
+		if !ok {
+			t.Errorf("field %s.%s (%q) is missing from the input schema", typ, field.Name, name)
+			continue
+		}
+		if prop.Description == "" {
+			t.Errorf("property %q has no description", name)
+		}
+		if field.Type.Kind() == reflect.Struct {
+			checkSchemaMatchesStruct(t, prop, field.Type)
+		}
+		if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct {
+			checkSchemaMatchesStruct(t, prop.Items, field.Type.Elem())
+		}
+	}
+
+	for name := range s.Properties {
+		if !slices.Contains(fields, name) {
+			t.Errorf("schema property %q has no matching field in %s", name, typ)
+		}
+	}
+	for _, name := range s.Required {
+		if !slices.Contains(fields, name) {
+			t.Errorf("required property %q has no matching field in %s", name, typ)
+		}
+	}
+}
+
+func TestToolInputSchemaConstraints(t *testing.T) {
+	session := connectInMemory(t)
+	list, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools() error: %v", err)
+	}
+
+	schemas := make(map[string]*jsonschema.Schema)
+	for _, tool := range list.Tools {
+		schemas[tool.Name] = tool.InputSchema
+	}
+
+	tests := []struct {
+		tool     string
+		property string
+		enum     []any
+		required bool
+		def      string
+	}{
+		{tool: "generate_dialer_config", property: "type", enum: []any{"local", "socks5", "http", "https", "http2", "http3", "ssh", "wss"}, required: true},
+		{tool: "generate_dialer_config", property: "name", required: true},
+		{tool: "generate_policy_examples", property: "policy_type", enum: []any{"geoip", "geosite", "domain_match", "ip_range", "file_based", "fetch_based", "custom"}, def: `"geoip"`},
+		{tool: "generate_redsocks_iptables", property: "format", enum: []any{"iptables-save", "ip6tables-save", "shell-script", "nft"}, def: `"iptables-save"`},
+		{tool: "generate_redsocks_iptables", property: "redsocks_port", def: `12345`},
+		{tool: "generate_tunnel_config", property: "role", enum: []any{"server", "client"}, required: true},
+		{tool: "generate_stream_config", property: "proxy_pass", required: true},
+		{tool: "generate_http_config", property: "listen", def: `[":443"]`},
+		{tool: "validate_liner_config", property: "config_content", required: true},
+		{tool: "manage_auth_table", property: "operations", required: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool+"."+tt.property, func(t *testing.T) {
+			s, ok := schemas[tt.tool]
+			if !ok {
+				t.Fatalf("tool %q not found", tt.tool)
+			}
+			prop, ok := s.Properties[tt.property]
+			if !ok {
+				t.Fatalf("property %q not found", tt.property)
+			}
+			if tt.enum != nil && !reflect.DeepEqual(prop.Enum, tt.enum) {
+				t.Errorf("enum = %v, want %v", prop.Enum, tt.enum)
+			}
+			if got := slices.Contains(s.Required, tt.property); got != tt.required {
+				t.Errorf("required = %v, want %v", got, tt.required)
+			}
+			if tt.def != "" && string(prop.Default) != tt.def {
+				t.Errorf("default = %s, want %s", prop.Default, tt.def)
+			}
+		})
+	}
+}
+
+func TestToolArgumentsValidatedAgainstSchema(t *testing.T) {
+	session := connectInMemory(t)
+	ctx := context.Background()
+
+	// 不符合schema的参数会被SDK直接拒绝
+	rejected := []struct {
+		name string
+		args map[string]any
+	}{
+		{name: "generate_dialer_config", args: map[string]any{"name": "cloud"}},              // 缺少必填字段
+		{name: "generate_redsocks_iptables", args: map[string]any{"format": "pf"}},           // 非法枚举值
+		{name: "generate_http_config", args: map[string]any{"listen": ":443"}},               // 类型错误
+		{name: "query_liner_docs", args: map[string]any{"topic": "tunnel", "verbose": true}}, // 未知字段
+		{name: "generate_redsocks_config", args: map[string]any{"udp": true}},                // 不支持UDP，没有该字段
+		{name: "generate_redsocks_iptables", args: map[string]any{"mode": "tproxy"}},         // 只生成REDIRECT规则
+	}
+	for _, r := range rejected {
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: r.name, Arguments: r.args})
+		if err == nil && !res.IsError {
+			t.Errorf("%s(%v) was accepted, expected a schema validation error", r.name, r.args)
+		}
+	}
+
+	// 省略可选字段时使用schema中的默认值
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "generate_redsocks_iptables",
+		Arguments: map[string]any{},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("unexpected error result: %#v", res.Content)
+	}
+	if text := resultText(res); !strings.Contains(text, "REDIRECT --to-ports 12345") {
+		t.Errorf("expected default redsocks port in output, got:\n%s", text)
+	}
+}
+
+// resultText 拼接工具结果中的所有文本内容块
+func resultText(res *mcp.CallToolResult) string {
+	var texts []string
+	for _, c := range res.Content {
+		if text, ok := c.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+func TestToolResultsAreStructured(t *testing.T) {
+	session := connectInMemory(t)
+	ctx := context.Background()
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "generate_http_config",
+		Arguments: map[string]any{"server_name": []string{"a.example"}},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("unexpected error result: %s", resultText(res))
+	}
+	if len(res.Content) != 2 {
+		t.Fatalf("expected description and YAML content blocks, got %d blocks", len(res.Content))
+	}
+	yamlText := res.Content[1].(*mcp.TextContent).Text
+	if strings.Contains(yamlText, "```") || !strings.Contains(yamlText, "a.example") {
+		t.Errorf("YAML block should contain the raw config, got:\n%s", yamlText)
+	}
+
+	structured, ok := res.StructuredContent.(map[string]any)
+	if !ok {
+		t.Fatalf("StructuredContent = %#v, want an object", res.StructuredContent)
+	}
+	if structured["yaml"] != yamlText {
+		t.Error("structured yaml does not match the YAML content block")
+	}
+	cfg, ok := structured["config"].(map[string]any)
+	if !ok || cfg["https"] == nil {
+		t.Errorf("structured config should contain the parsed https section, got %#v", structured["config"])
+	}
+
+	// 工具内部的错误在协议层通过 IsError 表示
+	res, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "validate_liner_config",
+		Arguments: map[string]any{"config_content": "global: [unclosed"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error: %v", err)
+	}
+	if !res.IsError {
+		t.Error("expected IsError for invalid YAML")
+	}
+	if text := resultText(res); strings.Contains(text, `"isError"`) || !strings.Contains(text, "YAML syntax error") {
+		t.Errorf("error text should be plain, got:\n%s", text)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bensonfx/mcp-liner/internal/schema"
+	"github.com/bensonfx/mcp-liner/tools"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// 资源URI
+const (
+	resourceScheme         = "liner"
+	docsResourceTemplate   = "liner://docs/{topic}"
+	policyResourceTemplate = "liner://policy/{policy_type}{?config_type}"
+)
+
+// addResources 注册文档、Policy示例和配置schema资源
+// 每个主题/类型都注册为独立资源以便客户端列出，同时注册资源模板供客户端构造URI
+func addResources(server *mcp.Server) {
+	for _, topic := range tools.DocTopics {
+		server.AddResource(&mcp.Resource{
+			Name:        "docs-" + topic,
+			Title:       fmt.Sprintf("Liner %s documentation", topic),
+			Description: fmt.Sprintf("liner %s 配置文档和示例", topic),
+			MIMEType:    "text/markdown",
+			URI:         docsURI(topic),
+		}, readDocResource)
+	}
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "docs",
+		Title:       "Liner documentation",
+		Description: fmt.Sprintf("liner 配置文档，topic 可选: %s", strings.Join(tools.DocTopics, ", ")),
+		MIMEType:    "text/markdown",
+		URITemplate: docsResourceTemplate,
+	}, readDocResource)
+
+	for _, policyType := range tools.PolicyTypes {
+		server.AddResource(&mcp.Resource{
+			Name:        "policy-" + policyType,
+			Title:       fmt.Sprintf("Liner %s policy examples", policyType),
+			Description: fmt.Sprintf("%s 路由的 Policy 模板示例", policyType),
+			MIMEType:    "text/plain",
+			URI:         policyURI(policyType),
+		}, readPolicyResource)
+	}
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:  "policy",
+		Title: "Liner policy examples",
+		Description: fmt.Sprintf("Policy 模板示例，policy_type 可选: %s；config_type 可选: %s（默认 http_forward）",
+			strings.Join(tools.PolicyTypes, ", "), strings.Join(tools.PolicyConfigTypes, ", ")),
+		MIMEType:    "text/plain",
+		URITemplate: policyResourceTemplate,
+	}, readPolicyResource)
+
+	server.AddResource(&mcp.Resource{
+		Name:        "schema",
+		Title:       "Liner configuration schema",
+		Description: "liner 配置的 JSON Schema，由与 liner/config.go 对应的配置结构体生成，用于检查未知键和值的类型",
+		MIMEType:    "application/schema+json",
+		URI:         schema.ID,
+	}, readSchemaResource)
+}
+
+// docsURI 返回文档资源的URI
+func docsURI(topic string) string {
+	return resourceScheme + "://docs/" + topic
+}
+
+// policyURI 返回Policy示例资源的URI
+func policyURI(policyType string) string {
+	return resourceScheme + "://policy/" + policyType
+}
+
+// readDocResource 读取 liner://docs/{topic}
+func readDocResource(_ context.Context, _ *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+	topic, _, ok := parseResourceURI(params.URI, "docs")
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(params.URI)
+	}
+	content, ok := tools.LinerDoc(topic)
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(params.URI)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{
+			URI:  params.URI,
+			Text: fmt.Sprintf("# Liner Documentation: %s\n\n%s", topic, content),
+		}},
+	}, nil
+}
+
+// readPolicyResource 读取 liner://policy/{policy_type}{?config_type}
+func readPolicyResource(_ context.Context, _ *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+	policyType, query, ok := parseResourceURI(params.URI, "policy")
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(params.URI)
+	}
+	configType := query.Get("config_type")
+	if configType == "" {
+		configType = "http_forward"
+	}
+	content, ok := tools.PolicyExample(policyType, configType)
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(params.URI)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{
+			URI:  params.URI,
+			Text: content,
+		}},
+	}, nil
+}
+
+// readSchemaResource 读取 liner://schema
+func readSchemaResource(_ context.Context, _ *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{
+			URI:  params.URI,
+			Text: string(schema.JSON()),
+		}},
+	}, nil
+}
+
+// parseResourceURI 解析 liner://<kind>/<name>?<query> 形式的URI
+func parseResourceURI(uri string, kind string) (string, url.Values, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != resourceScheme || u.Host != kind {
+		return "", nil, false
+	}
+	name := strings.TrimPrefix(u.Path, "/")
+	if name == "" || strings.Contains(name, "/") {
+		return "", nil, false
+	}
+	return name, u.Query(), true
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/tools"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestListResources(t *testing.T) {
+	session := connectInMemory(t)
+	ctx := context.Background()
+
+	list, err := session.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResources() error: %v", err)
+	}
+	uris := make(map[string]bool)
+	for _, r := range list.Resources {
+		uris[r.URI] = true
+	}
+	for _, topic := range tools.DocTopics {
+		if !uris["liner://docs/"+topic] {
+			t.Errorf("resource liner://docs/%s is not listed", topic)
+		}
+	}
+	for _, policyType := range tools.PolicyTypes {
+		if !uris["liner://policy/"+policyType] {
+			t.Errorf("resource liner://policy/%s is not listed", policyType)
+		}
+	}
+
+	if !uris["liner://schema"] {
+		t.Error("resource liner://schema is not listed")
+	}
+
+	templates, err := session.ListResourceTemplates(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResourceTemplates() error: %v", err)
+	}
+	found := make(map[string]bool)
+	for _, tmpl := range templates.ResourceTemplates {
+		found[tmpl.URITemplate] = true
+	}
+	for _, want := range []string{docsResourceTemplate, policyResourceTemplate} {
+		if !found[want] {
+			t.Errorf("resource template %s is not listed", want)
+		}
+	}
+}
+
+func TestReadResource(t *testing.T) {
+	session := connectInMemory(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		uri         string
+		mimeType    string
+		wantContain string
+	}{
+		{uri: "liner://docs/tunnel", mimeType: "text/markdown", wantContain: "remote_listen"},
+		{uri: "liner://docs/dialer", mimeType: "text/markdown", wantContain: "socks5://"},
+		{uri: "liner://policy/geoip", mimeType: "text/plain", wantContain: "GeoIP-based Routing Policy"},
+		{uri: "liner://policy/custom?config_type=dns", mimeType: "text/plain", wantContain: ".Question.Name"},
+		{uri: "liner://schema", mimeType: "application/schema+json", wantContain: `"$defs"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: tt.uri})
+			if err != nil {
+				t.Fatalf("ReadResource() error: %v", err)
+			}
+			if len(res.Contents) != 1 {
+				t.Fatalf("expected 1 content, got %d", len(res.Contents))
+			}
+			c := res.Contents[0]
+			if c.URI != tt.uri {
+				t.Errorf("URI = %q, want %q", c.URI, tt.uri)
+			}
+			if c.MIMEType != tt.mimeType {
+				t.Errorf("MIMEType = %q, want %q", c.MIMEType, tt.mimeType)
+			}
+			if !strings.Contains(c.Text, tt.wantContain) {
+				t.Errorf("content does not contain %q", tt.wantContain)
+			}
+		})
+	}
+}
+
+func TestReadUnknownResource(t *testing.T) {
+	session := connectInMemory(t)
+	ctx := context.Background()
+
+	for _, uri := range []string{"liner://docs/unknown", "liner://policy/unknown", "liner://other/tunnel"} {
+		if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri}); err == nil {
+			t.Errorf("ReadResource(%q) should fail", uri)
+		}
+	}
+}
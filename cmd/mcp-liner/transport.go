@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/phuslu/log"
+)
+
+// 支持的传输方式
+const (
+	transportStdio = "stdio"
+	transportHTTP  = "http"
+	transportSSE   = "sse"
+)
+
+// shutdownTimeout HTTP 服务优雅关闭的最长等待时间
+const shutdownTimeout = 5 * time.Second
+
+// isValidTransport 检查传输方式是否受支持
+func isValidTransport(transport string) bool {
+	switch transport {
+	case transportStdio, transportHTTP, transportSSE:
+		return true
+	}
+	return false
+}
+
+// serve 按指定的传输方式运行 MCP server，直到 ctx 被取消
+func serve(ctx context.Context, server *mcp.Server, transport string, listen string) error {
+	if transport == transportStdio {
+		return server.Run(ctx, mcp.NewStdioTransport())
+	}
+
+	handler, err := newHTTPHandler(server, transport)
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", listen, err)
+	}
+
+	return serveHTTP(ctx, ln, handler)
+}
+
+// newHTTPHandler 为 http/sse 传输创建 http.Handler，所有会话共享同一个 server
+func newHTTPHandler(server *mcp.Server, transport string) (http.Handler, error) {
+	getServer := func(*http.Request) *mcp.Server { return server }
+
+	switch transport {
+	case transportHTTP:
+		return mcp.NewStreamableHTTPHandler(getServer, nil), nil
+	case transportSSE:
+		return mcp.NewSSEHandler(getServer), nil
+	default:
+		return nil, fmt.Errorf("unsupported http transport: %s", transport)
+	}
+}
+
+// serveHTTP 在 ln 上提供 handler 服务，ctx 取消后优雅关闭
+// 请求的 context 派生自 ctx，这样 SSE 等长连接也会随之结束
+func serveHTTP(ctx context.Context, ln net.Listener, handler http.Handler) error {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info().Str("addr", ln.Addr().String()).Msg("mcp http server listening")
+		errCh <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info().Msg("shutting down mcp http server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Warn().Err(err).Msg("graceful shutdown timed out, closing connections")
+		_ = srv.Close()
+	}
+
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return ctx.Err()
+}
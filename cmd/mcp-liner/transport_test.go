@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// startHTTPServer 在随机端口上启动 http/sse 传输，返回服务地址和关闭函数
+func startHTTPServer(t *testing.T, transport string) (string, func() error) {
+	t.Helper()
+
+	handler, err := newHTTPHandler(newServer(), transport)
+	if err != nil {
+		t.Fatalf("newHTTPHandler(%q) error: %v", transport, err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveHTTP(ctx, ln, handler)
+	}()
+
+	stop := func() error {
+		cancel()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(2 * shutdownTimeout):
+			return errors.New("server did not shut down")
+		}
+	}
+
+	return "http://" + ln.Addr().String(), stop
+}
+
+func TestHTTPTransports(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport string
+		client    func(url string) mcp.Transport
+	}{
+		{
+			name:      "streamable http",
+			transport: transportHTTP,
+			client: func(url string) mcp.Transport {
+				return mcp.NewStreamableClientTransport(url, nil)
+			},
+		},
+		{
+			name:      "sse",
+			transport: transportSSE,
+			client: func(url string) mcp.Transport {
+				return mcp.NewSSEClientTransport(url, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, stop := startHTTPServer(t, tt.transport)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+			session, err := client.Connect(ctx, tt.client(url))
+			if err != nil {
+				t.Fatalf("client.Connect() error: %v", err)
+			}
+
+			list, err := session.ListTools(ctx, nil)
+			if err != nil {
+				t.Fatalf("ListTools() error: %v", err)
+			}
+			if len(list.Tools) != 22 {
+				t.Errorf("ListTools() returned %d tools, want 22", len(list.Tools))
+			}
+
+			res, err := session.CallTool(ctx, &mcp.CallToolParams{
+				Name:      "query_liner_docs",
+				Arguments: map[string]any{"topic": "tunnel"},
+			})
+			if err != nil {
+				t.Fatalf("CallTool() error: %v", err)
+			}
+			if len(res.Content) == 0 {
+				t.Fatal("CallTool() returned no content")
+			}
+			text, ok := res.Content[0].(*mcp.TextContent)
+			if !ok || !strings.Contains(text.Text, "remote_listen") {
+				t.Errorf("CallTool() returned unexpected content: %#v", res.Content[0])
+			}
+
+			if err := session.Close(); err != nil {
+				t.Logf("session.Close() error: %v", err)
+			}
+
+			if err := stop(); !errors.Is(err, context.Canceled) {
+				t.Errorf("serveHTTP() returned %v, want context.Canceled", err)
+			}
+		})
+	}
+}
+
+func TestServeHTTPShutdownWithOpenSSESession(t *testing.T) {
+	url, stop := startHTTPServer(t, transportSSE)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 保持 SSE 会话不关闭，确认 shutdown 不会被挂起的 GET 请求阻塞
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	if _, err := client.Connect(ctx, mcp.NewSSEClientTransport(url, nil)); err != nil {
+		t.Fatalf("client.Connect() error: %v", err)
+	}
+
+	start := time.Now()
+	if err := stop(); !errors.Is(err, context.Canceled) {
+		t.Errorf("serveHTTP() returned %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed >= shutdownTimeout {
+		t.Errorf("shutdown took %v, expected it to finish before the %v timeout", elapsed, shutdownTimeout)
+	}
+}
+
+func TestIsValidTransport(t *testing.T) {
+	for _, tr := range []string{"stdio", "http", "sse"} {
+		if !isValidTransport(tr) {
+			t.Errorf("isValidTransport(%q) = false, want true", tr)
+		}
+	}
+	if isValidTransport("websocket") {
+		t.Error("isValidTransport(\"websocket\") = true, want false")
+	}
+}
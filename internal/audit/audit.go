@@ -0,0 +1,477 @@
+// Package audit 对liner配置做安全审计，评估暴露面并给出修复建议
+package audit
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/bensonfx/mcp-liner/internal/validation"
+)
+
+// Level 风险等级
+type Level string
+
+const (
+	LevelCritical Level = "critical" // 可被任何人直接利用
+	LevelHigh     Level = "high"     // 凭据泄露或可被滥用
+	LevelMedium   Level = "medium"   // 扩大攻击面
+	LevelLow      Level = "low"      // 加固建议
+)
+
+// levelWeights 每个等级的扣分，levelRanks 排序用的权重
+var (
+	levelWeights = map[Level]int{LevelCritical: 40, LevelHigh: 20, LevelMedium: 10, LevelLow: 3}
+	levelRanks   = map[Level]int{LevelCritical: 0, LevelHigh: 1, LevelMedium: 2, LevelLow: 3}
+)
+
+// 审计规则代码
+const (
+	CodeOpenForwardProxy    = "open-forward-proxy"
+	CodeTunnelNoAuth        = "tunnel-no-auth"
+	CodeTunnelAllowAll      = "tunnel-allow-all"
+	CodeWebShellNoAuth      = "webshell-no-auth"
+	CodeWebDAVNoAuth        = "webdav-no-auth"
+	CodeSSHNoAuth           = "ssh-no-auth"
+	CodePlaintextCredential = "plaintext-credentials"
+	CodePSKReuse            = "psk-reuse"
+	CodeWeakPassword        = "weak-password"
+	CodeTLS11Enabled        = "tls11-enabled"
+	CodeTLSInsecure         = "tls-insecure"
+	CodeLocalAddrAllowed    = "local-addr-allowed"
+	CodePlainDNS            = "plain-dns-server"
+)
+
+// validationLevels 纳入审计的验证警告及其风险等级
+var validationLevels = map[string]Level{
+	validation.CodeTLSInsecure:      LevelHigh,
+	validation.CodeLocalAddrAllowed: LevelHigh,
+	validation.CodeWebShellNoAuth:   LevelCritical,
+	validation.CodePlainDNSServer:   LevelLow,
+}
+
+// Finding 一条审计发现
+type Finding struct {
+	Code        string `json:"code"`
+	Level       Level  `json:"level"`
+	Field       string `json:"field"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation"`
+}
+
+// Report 审计报告
+// Score 为 0-100 的暴露评分，越高越安全；Findings 按风险从高到低排列
+type Report struct {
+	Score    int       `json:"score"`
+	Grade    string    `json:"grade"`
+	Findings []Finding `json:"findings"`
+	// Valid 为配置是否通过 validation.ValidateConfig，ValidationErrors 为错误数量
+	Valid            bool `json:"valid"`
+	ValidationErrors int  `json:"validation_errors"`
+	// AuthTablesChecked 已检查密码强度的认证表路径
+	AuthTablesChecked []string `json:"auth_tables_checked"`
+}
+
+// Options 审计选项
+type Options struct {
+	// AuthTables 认证表路径（与配置中 auth_table 的写法一致）到CSV内容，用于检查弱密码
+	AuthTables map[string]string
+}
+
+// Audit 审计配置的暴露面
+func Audit(cfg *config.Config, opts Options) *Report {
+	a := &auditor{cfg: cfg}
+
+	result := validation.ValidateConfig(cfg)
+	for _, e := range result.Errors {
+		if level, ok := validationLevels[e.Code]; ok {
+			a.add(e.Code, level, e.Field, e.Message, e.Suggestion)
+		}
+	}
+
+	a.httpSections("https", cfg.Https)
+	a.httpSections("http", cfg.Http)
+	a.socks()
+	a.ssh()
+	a.pskReuse()
+	checked := a.authTables(opts.AuthTables)
+
+	sort.SliceStable(a.findings, func(i, j int) bool {
+		return levelRanks[a.findings[i].Level] < levelRanks[a.findings[j].Level]
+	})
+
+	score := 100
+	for _, f := range a.findings {
+		score -= levelWeights[f.Level]
+	}
+	if score < 0 {
+		score = 0
+	}
+
+	return &Report{
+		Score:             score,
+		Grade:             grade(score),
+		Findings:          a.findings,
+		Valid:             result.Valid,
+		ValidationErrors:  result.Count(validation.SeverityError),
+		AuthTablesChecked: checked,
+	}
+}
+
+// AuthTablePaths 返回配置中引用的本地认证表路径，按出现顺序去重，URL形式的认证表不包括在内
+func AuthTablePaths(cfg *config.Config) []string {
+	var paths []string
+	for _, ref := range authTableRefs(cfg) {
+		if !slices.Contains(paths, ref.path) {
+			paths = append(paths, ref.path)
+		}
+	}
+	return paths
+}
+
+// auditor 收集审计发现
+type auditor struct {
+	cfg      *config.Config
+	findings []Finding
+}
+
+func (a *auditor) add(code string, level Level, field, message, remediation string) {
+	a.findings = append(a.findings, Finding{
+		Code:        code,
+		Level:       level,
+		Field:       field,
+		Message:     message,
+		Remediation: remediation,
+	})
+}
+
+// httpSections 检查 http/https 的正向代理、隧道服务端、WebDAV、明文凭据和 TLS 1.1
+func (a *auditor) httpSections(section string, httpCfgs []config.HTTPConfig) {
+	for i, httpCfg := range httpCfgs {
+		prefix := fmt.Sprintf("%s[%d]", section, i)
+		public := firstPublic(httpCfg.Listen)
+
+		if httpCfg.Forward.Policy != "" && httpCfg.Forward.AuthTable == "" && public != "" {
+			a.add(CodeOpenForwardProxy, LevelCritical, prefix+".forward.auth_table",
+				fmt.Sprintf("forward proxy on public address %q has no auth_table, anyone can relay traffic through it", public),
+				"set forward.auth_table, or listen on 127.0.0.1 / a private address only")
+		}
+
+		if httpCfg.Tunnel.Enabled {
+			if httpCfg.Tunnel.AuthTable == "" {
+				a.add(CodeTunnelNoAuth, LevelCritical, prefix+".tunnel.auth_table",
+					"tunnel server has no auth_table, anyone can open reverse tunnels on this host",
+					"set tunnel.auth_table and grant allow_tunnel only to tunnel clients")
+			}
+			for j, allow := range httpCfg.Tunnel.AllowListens {
+				if allowsAll(allow) {
+					a.add(CodeTunnelAllowAll, LevelHigh, fmt.Sprintf("%s.tunnel.allow_listens[%d]", prefix, j),
+						fmt.Sprintf("allow_listens %q lets tunnel clients expose ports on every address of the server", allow),
+						"restrict allow_listens to 127.0.0.1 or the specific address tunnels should bind")
+				}
+			}
+		}
+
+		for j, webCfg := range httpCfg.Web {
+			if webCfg.Dav.Enabled && webCfg.Dav.AuthTable == "" {
+				a.add(CodeWebDAVNoAuth, LevelHigh, fmt.Sprintf("%s.web[%d].dav.auth_table", prefix, j),
+					fmt.Sprintf("WebDAV at location %q has no auth_table, anyone can read and write %s", webCfg.Location, webCfg.Dav.Root),
+					"set dav.auth_table and grant allow_webdav to the users that need it")
+			}
+		}
+
+		// http 使用 Basic 认证，凭据以明文传输
+		if section == "http" {
+			if table := firstAuthTable(httpCfg); table != "" {
+				if exposed := firstNonLoopback(httpCfg.Listen); exposed != "" {
+					a.add(CodePlaintextCredential, LevelHigh, prefix+".listen",
+						fmt.Sprintf("plaintext http listener %q asks for credentials from %s, passwords can be sniffed on the network", exposed, table),
+						"move the authenticated services to an https listener, or bind the http listener to 127.0.0.1")
+				}
+			}
+		}
+
+		if section == "https" {
+			var names []string
+			for _, name := range httpCfg.ServerName {
+				if !httpCfg.ServerConfig[name].DisableTls11 {
+					names = append(names, name)
+				}
+			}
+			if len(names) > 0 {
+				a.add(CodeTLS11Enabled, LevelLow, prefix+".server_config",
+					fmt.Sprintf("TLS 1.1 is still accepted for %s", strings.Join(names, ", ")),
+					"set server_config.<server_name>.disable_tls11: true")
+			}
+		}
+	}
+}
+
+// socks 检查没有认证的公网 socks 代理
+func (a *auditor) socks() {
+	for i, socksCfg := range a.cfg.Socks {
+		if public := firstPublic(socksCfg.Listen); public != "" && socksCfg.Forward.AuthTable == "" {
+			a.add(CodeOpenForwardProxy, LevelCritical, fmt.Sprintf("socks[%d].forward.auth_table", i),
+				fmt.Sprintf("socks proxy on public address %q has no auth_table, anyone can relay traffic through it", public),
+				"set forward.auth_table, or listen on 127.0.0.1 / a private address only")
+		}
+	}
+}
+
+// ssh 检查没有认证方式的 ssh 服务
+func (a *auditor) ssh() {
+	for i, sshCfg := range a.cfg.Ssh {
+		if sshCfg.AuthTable == "" && sshCfg.AuthorizedKeys == "" {
+			a.add(CodeSSHNoAuth, LevelHigh, fmt.Sprintf("ssh[%d].auth_table", i),
+				"ssh server has neither auth_table nor authorized_keys configured",
+				"set authorized_keys (preferred) or auth_table with allow_ssh users")
+		}
+	}
+}
+
+// pskReuse 检查多个监听共用同一个 psk
+func (a *auditor) pskReuse() {
+	type use struct {
+		field string
+		psk   string
+	}
+	var uses []use
+	for i, httpsCfg := range a.cfg.Https {
+		uses = append(uses, use{fmt.Sprintf("https[%d].psk", i), httpsCfg.PSK})
+	}
+	for i, httpCfg := range a.cfg.Http {
+		uses = append(uses, use{fmt.Sprintf("http[%d].psk", i), httpCfg.PSK})
+	}
+	for i, socksCfg := range a.cfg.Socks {
+		uses = append(uses, use{fmt.Sprintf("socks[%d].psk", i), socksCfg.PSK})
+	}
+
+	for i, u := range uses {
+		if u.psk == "" {
+			continue
+		}
+		for _, prev := range uses[:i] {
+			if prev.psk == u.psk {
+				a.add(CodePSKReuse, LevelMedium, u.field,
+					fmt.Sprintf("psk is the same as %s, a leak of one listener's key exposes both", prev.field),
+					"generate a separate random psk for each listener, e.g. openssl rand -hex 32")
+				break
+			}
+		}
+	}
+}
+
+// authTableRef 配置中对认证表的一次引用
+type authTableRef struct {
+	field string
+	path  string
+}
+
+// authTableRefs 返回配置中所有本地认证表引用
+func authTableRefs(cfg *config.Config) []authTableRef {
+	var refs []authTableRef
+	add := func(field, path string) {
+		if path != "" && !strings.Contains(path, "://") {
+			refs = append(refs, authTableRef{field, path})
+		}
+	}
+	checkHTTP := func(section string, httpCfgs []config.HTTPConfig) {
+		for i, httpCfg := range httpCfgs {
+			prefix := fmt.Sprintf("%s[%d]", section, i)
+			add(prefix+".forward.auth_table", httpCfg.Forward.AuthTable)
+			add(prefix+".tunnel.auth_table", httpCfg.Tunnel.AuthTable)
+			for j, webCfg := range httpCfg.Web {
+				webPrefix := fmt.Sprintf("%s.web[%d]", prefix, j)
+				add(webPrefix+".dav.auth_table", webCfg.Dav.AuthTable)
+				add(webPrefix+".proxy.auth_table", webCfg.Proxy.AuthTable)
+				add(webPrefix+".shell.auth_table", webCfg.Shell.AuthTable)
+			}
+		}
+	}
+	checkHTTP("https", cfg.Https)
+	checkHTTP("http", cfg.Http)
+	for i, socksCfg := range cfg.Socks {
+		add(fmt.Sprintf("socks[%d].forward.auth_table", i), socksCfg.Forward.AuthTable)
+	}
+	for i, sshCfg := range cfg.Ssh {
+		add(fmt.Sprintf("ssh[%d].auth_table", i), sshCfg.AuthTable)
+	}
+	return refs
+}
+
+// authTables 检查提供了内容的认证表中的弱密码，返回已检查的路径
+func (a *auditor) authTables(tables map[string]string) []string {
+	checked := []string{}
+	for _, ref := range authTableRefs(a.cfg) {
+		content, ok := tables[ref.path]
+		if !ok || slices.Contains(checked, ref.path) {
+			continue
+		}
+		checked = append(checked, ref.path)
+
+		weak, err := weakPasswords(content)
+		if err != nil {
+			a.add(CodeWeakPassword, LevelMedium, ref.field,
+				fmt.Sprintf("%s could not be parsed: %v", ref.path, err),
+				"use a CSV with a username,password header, see generate_auth_user")
+			continue
+		}
+		if len(weak) > 0 {
+			a.add(CodeWeakPassword, LevelHigh, ref.field,
+				fmt.Sprintf("%s has weak passwords for: %s", ref.path, strings.Join(weak, "; ")),
+				fmt.Sprintf("use random passwords of at least %d characters, e.g. openssl rand -base64 18", minPasswordLength))
+		}
+	}
+	return checked
+}
+
+// minPasswordLength 明文密码的最小长度
+const minPasswordLength = 12
+
+// commonPasswords 常见弱密码
+var commonPasswords = []string{
+	"password", "passw0rd", "123456", "12345678", "123456789", "1234567890", "qwerty", "abc123",
+	"111111", "000000", "admin", "root", "letmein", "welcome", "iloveyou", "changeme", "secret", "test",
+}
+
+// weakPasswords 返回认证表中使用弱密码的用户及原因
+// liner 按表中的值比较密码，所有值都按密码检查
+func weakPasswords(content string) ([]string, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("empty auth table")
+		}
+		return nil, err
+	}
+	usernameCol, passwordCol := -1, -1
+	for i, h := range header {
+		switch strings.TrimSpace(h) {
+		case "username":
+			usernameCol = i
+		case "password":
+			passwordCol = i
+		}
+	}
+	if usernameCol < 0 || passwordCol < 0 {
+		return nil, fmt.Errorf("header %q has no username or password column", strings.Join(header, ","))
+	}
+
+	var weak []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return weak, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if usernameCol >= len(record) || passwordCol >= len(record) {
+			continue
+		}
+		username, password := record[usernameCol], record[passwordCol]
+		if reason := passwordWeakness(username, password); reason != "" {
+			weak = append(weak, fmt.Sprintf("%s (%s)", username, reason))
+		}
+	}
+}
+
+// passwordWeakness 返回密码的弱点，密码足够强时返回空字符串
+func passwordWeakness(username, password string) string {
+	lower := strings.ToLower(password)
+	switch {
+	case password == "":
+		return "empty"
+	case strings.EqualFold(password, username):
+		return "same as username"
+	case slices.Contains(commonPasswords, lower):
+		return "common password"
+	case len(password) < minPasswordLength:
+		return fmt.Sprintf("shorter than %d characters", minPasswordLength)
+	case strings.Count(password, password[:1]) == len(password):
+		return "single repeated character"
+	}
+	return ""
+}
+
+// firstPublic 返回第一个可能暴露在公网的监听地址
+func firstPublic(listen []string) string {
+	for _, addr := range listen {
+		if validation.IsPublicListen(addr) {
+			return addr
+		}
+	}
+	return ""
+}
+
+// firstNonLoopback 返回第一个不是回环地址的监听地址
+func firstNonLoopback(listen []string) string {
+	for _, addr := range listen {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(host, "localhost") {
+			continue
+		}
+		if ip, err := netip.ParseAddr(host); err == nil && ip.Unmap().IsLoopback() {
+			continue
+		}
+		return addr
+	}
+	return ""
+}
+
+// firstAuthTable 返回 http 配置中第一个要求认证的 auth_table
+func firstAuthTable(httpCfg config.HTTPConfig) string {
+	if httpCfg.Forward.AuthTable != "" {
+		return httpCfg.Forward.AuthTable
+	}
+	if httpCfg.Tunnel.Enabled && httpCfg.Tunnel.AuthTable != "" {
+		return httpCfg.Tunnel.AuthTable
+	}
+	for _, webCfg := range httpCfg.Web {
+		for _, table := range []string{webCfg.Dav.AuthTable, webCfg.Proxy.AuthTable, webCfg.Shell.AuthTable} {
+			if table != "" {
+				return table
+			}
+		}
+	}
+	return ""
+}
+
+// allowsAll 判断 allow_listens 条目是否允许监听所有地址
+func allowsAll(allow string) bool {
+	if ip, err := netip.ParseAddr(allow); err == nil {
+		return ip.IsUnspecified()
+	}
+	if prefix, err := netip.ParsePrefix(allow); err == nil {
+		return prefix.Bits() == 0 || prefix.Addr().IsUnspecified()
+	}
+	return false
+}
+
+// grade 将评分转换为等级
+func grade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 75:
+		return "B"
+	case score >= 60:
+		return "C"
+	case score >= 40:
+		return "D"
+	default:
+		return "F"
+	}
+}
@@ -0,0 +1,142 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+)
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		opts Options
+		// want 为按顺序排列的 规则代码@字段
+		want []string
+	}{
+		{
+			name: "hardened config",
+			cfg: &config.Config{
+				Global: config.GlobalConfig{ForbidLocalAddr: true, DnsServer: "https://1.1.1.1/dns-query"},
+				Https: []config.HTTPConfig{{
+					Listen:       []string{":443"},
+					ServerName:   []string{"a.example"},
+					ServerConfig: map[string]config.ServerConfig{"a.example": {DisableTls11: true}},
+					Forward:      config.HTTPForwardConfig{Policy: "proxy_pass", AuthTable: "auth.csv"},
+				}},
+			},
+			opts: Options{AuthTables: map[string]string{"auth.csv": "username,password\nalice,Vx9#kq2LmZ7pWe\n"}},
+		},
+		{
+			name: "open proxies ranked by risk",
+			cfg: &config.Config{
+				Global: config.GlobalConfig{ForbidLocalAddr: true},
+				Https: []config.HTTPConfig{{
+					Listen:     []string{":443"},
+					ServerName: []string{"a.example"},
+					PSK:        "shared",
+					Tunnel:     config.HTTPTunnelConfig{Enabled: true, AuthTable: "auth.csv", AllowListens: []string{"127.0.0.1", "0.0.0.0/0"}},
+				}},
+				Socks: []config.SocksConfig{
+					{Listen: []string{"127.0.0.1:1080"}, PSK: "shared"},
+					{Listen: []string{":1081"}},
+				},
+			},
+			want: []string{
+				"open-forward-proxy@socks[1].forward.auth_table",
+				"tunnel-allow-all@https[0].tunnel.allow_listens[1]",
+				"psk-reuse@socks[0].psk",
+				"tls11-enabled@https[0].server_config",
+			},
+		},
+		{
+			name: "services without auth",
+			cfg: &config.Config{
+				Global: config.GlobalConfig{ForbidLocalAddr: true},
+				Http: []config.HTTPConfig{{
+					Listen: []string{"127.0.0.1:80"},
+					Tunnel: config.HTTPTunnelConfig{Enabled: true},
+					Web: []config.HTTPWebConfig{
+						{Location: "/dav/", Dav: config.HTTPWebDavConfig{Enabled: true, Root: "/srv"}},
+						{Location: "/shell/", Shell: config.HTTPWebShellConfig{Enabled: true, Command: "bash"}},
+					},
+				}},
+				Ssh: []config.SshConfig{{Listen: []string{":2022"}}},
+			},
+			want: []string{
+				"webshell-no-auth@http[0].web[1].shell.auth_table",
+				"tunnel-no-auth@http[0].tunnel.auth_table",
+				"webdav-no-auth@http[0].web[0].dav.auth_table",
+				"ssh-no-auth@ssh[0].auth_table",
+			},
+		},
+		{
+			name: "plaintext credentials and weak passwords",
+			cfg: &config.Config{
+				Global: config.GlobalConfig{TlsInsecure: true, ForbidLocalAddr: true},
+				Http: []config.HTTPConfig{{
+					Listen:  []string{"127.0.0.1:8080", "192.168.1.1:8080"},
+					Forward: config.HTTPForwardConfig{Policy: "proxy_pass", AuthTable: "auth.csv"},
+				}},
+			},
+			opts: Options{AuthTables: map[string]string{
+				"auth.csv": "username,password\nalice,alice\nbob,123456\ncarol,short\ndave,$2y$10$abcdefghijklmnopqrstuv\nerin,!x\n",
+			}},
+			want: []string{
+				"tls-insecure@global.tls_insecure",
+				"plaintext-credentials@http[0].listen",
+				"weak-password@http[0].forward.auth_table",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Audit(tt.cfg, tt.opts)
+			got := []string{}
+			for _, f := range report.Findings {
+				got = append(got, f.Code+"@"+f.Field)
+				if f.Remediation == "" {
+					t.Errorf("%s has no remediation", f.Code)
+				}
+			}
+			want := tt.want
+			if want == nil {
+				want = []string{}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("findings = %q, want %q", got, want)
+			}
+			if len(want) == 0 && report.Score != 100 {
+				t.Errorf("Score = %d, want 100", report.Score)
+			}
+		})
+	}
+}
+
+func TestWeakPasswords(t *testing.T) {
+	// liner 按表中的值比较密码，以 $、{ 或 ! 开头的值同样按密码检查
+	weak, err := weakPasswords("# comment\nusername,password,speed_limit\nalice,alice,0\nbob,,0\ncarol,Correct-Horse-Battery,0\ndave,$abc,0\nerin,!x,0\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"alice (same as username)", "bob (empty)", "dave (shorter than 12 characters)", "erin (shorter than 12 characters)"}
+	if !reflect.DeepEqual(weak, want) {
+		t.Errorf("weakPasswords() = %q, want %q", weak, want)
+	}
+
+	if _, err := weakPasswords("name,secret\n"); err == nil {
+		t.Error("expected an error for a header without username and password")
+	}
+}
+
+func TestScoreAndGrade(t *testing.T) {
+	cfg := &config.Config{
+		Socks: []config.SocksConfig{{Listen: []string{":1080"}}, {Listen: []string{":1081"}}, {Listen: []string{":1082"}}},
+	}
+	report := Audit(cfg, Options{})
+	if report.Score != 0 || report.Grade != "F" {
+		t.Errorf("Score = %d, Grade = %s, want 0, F", report.Score, report.Grade)
+	}
+}
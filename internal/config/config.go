@@ -7,262 +7,262 @@ import (
 
 // GlobalConfig 全局配置结构，完全对应 liner/config.go Config.Global
 type GlobalConfig struct {
-	LogDir           string `json:"log_dir" yaml:"log_dir"`
-	LogLevel         string `json:"log_level" yaml:"log_level"`
-	LogBackups       int    `json:"log_backups" yaml:"log_backups"`
-	LogMaxsize       int64  `json:"log_maxsize" yaml:"log_maxsize"`
-	LogLocaltime     bool   `json:"log_localtime" yaml:"log_localtime"`
-	LogChannelSize   uint   `json:"log_channel_size" yaml:"log_channel_size"`
-	ForbidLocalAddr  bool   `json:"forbid_local_addr" yaml:"forbid_local_addr"`
-	DialTimeout      int    `json:"dial_timeout" yaml:"dial_timeout"`
-	DialReadBuffer   int    `json:"dial_read_buffer" yaml:"dial_read_buffer"` // Danger, see https://issues.apache.org/jira/browse/KAFKA-16496
-	DialWriteBuffer  int    `json:"dial_write_buffer" yaml:"dial_write_buffer"`
-	DnsServer        string `json:"dns_server" yaml:"dns_server"`
-	DnsCacheDuration string `json:"dns_cache_duration" yaml:"dns_cache_duration"`
-	DnsCacheSize     int    `json:"dns_cache_size" yaml:"dns_cache_size"`
-	TcpReadBuffer    int    `json:"tcp_read_buffer" yaml:"tcp_read_buffer"`
-	TcpWriteBuffer   int    `json:"tcp_write_buffer" yaml:"tcp_write_buffer"`
-	TlsInsecure      bool   `json:"tls_insecure" yaml:"tls_insecure"`
-	AutocertDir      string `json:"autocert_dir" yaml:"autocert_dir"`
-	GeoipDir         string `json:"geoip_dir" yaml:"geoip_dir"`
-	GeoipCacheSize   int    `json:"geoip_cache_size" yaml:"geoip_cache_size"`
-	GeositeDisabled  bool   `json:"geosite_disabled" yaml:"geosite_disabled"`
-	GeositeCacheSize int    `json:"geosite_cache_size" yaml:"geosite_cache_size"`
-	IdleConnTimeout  int    `json:"idle_conn_timeout" yaml:"idle_conn_timeout"`
-	MaxIdleConns     int    `json:"max_idle_conns" yaml:"max_idle_conns"`
-	DisableHttp3     bool   `json:"disable_http3" yaml:"disable_http3"`
-	SetProcessName   string `json:"set_process_name" yaml:"set_process_name"`
+	LogDir           string `json:"log_dir" yaml:"log_dir,omitempty"`
+	LogLevel         string `json:"log_level" yaml:"log_level,omitempty"`
+	LogBackups       int    `json:"log_backups" yaml:"log_backups,omitempty"`
+	LogMaxsize       int64  `json:"log_maxsize" yaml:"log_maxsize,omitempty"`
+	LogLocaltime     bool   `json:"log_localtime" yaml:"log_localtime,omitempty"`
+	LogChannelSize   uint   `json:"log_channel_size" yaml:"log_channel_size,omitempty"`
+	ForbidLocalAddr  bool   `json:"forbid_local_addr" yaml:"forbid_local_addr,omitempty"`
+	DialTimeout      int    `json:"dial_timeout" yaml:"dial_timeout,omitempty"`
+	DialReadBuffer   int    `json:"dial_read_buffer" yaml:"dial_read_buffer,omitempty"` // Danger, see https://issues.apache.org/jira/browse/KAFKA-16496
+	DialWriteBuffer  int    `json:"dial_write_buffer" yaml:"dial_write_buffer,omitempty"`
+	DnsServer        string `json:"dns_server" yaml:"dns_server,omitempty"`
+	DnsCacheDuration string `json:"dns_cache_duration" yaml:"dns_cache_duration,omitempty"`
+	DnsCacheSize     int    `json:"dns_cache_size" yaml:"dns_cache_size,omitempty"`
+	TcpReadBuffer    int    `json:"tcp_read_buffer" yaml:"tcp_read_buffer,omitempty"`
+	TcpWriteBuffer   int    `json:"tcp_write_buffer" yaml:"tcp_write_buffer,omitempty"`
+	TlsInsecure      bool   `json:"tls_insecure" yaml:"tls_insecure,omitempty"`
+	AutocertDir      string `json:"autocert_dir" yaml:"autocert_dir,omitempty"`
+	GeoipDir         string `json:"geoip_dir" yaml:"geoip_dir,omitempty"`
+	GeoipCacheSize   int    `json:"geoip_cache_size" yaml:"geoip_cache_size,omitempty"`
+	GeositeDisabled  bool   `json:"geosite_disabled" yaml:"geosite_disabled,omitempty"`
+	GeositeCacheSize int    `json:"geosite_cache_size" yaml:"geosite_cache_size,omitempty"`
+	IdleConnTimeout  int    `json:"idle_conn_timeout" yaml:"idle_conn_timeout,omitempty"`
+	MaxIdleConns     int    `json:"max_idle_conns" yaml:"max_idle_conns,omitempty"`
+	DisableHttp3     bool   `json:"disable_http3" yaml:"disable_http3,omitempty"`
+	SetProcessName   string `json:"set_process_name" yaml:"set_process_name,omitempty"`
 }
 
 // HTTPForwardConfig HTTP转发配置，对应 liner/config.go HTTPConfig.Forward
 type HTTPForwardConfig struct {
-	Policy           string `json:"policy" yaml:"policy"`
-	AuthTable        string `json:"auth_table" yaml:"auth_table"`
-	Dialer           string `json:"dialer" yaml:"dialer"`
-	TcpCongestion    string `json:"tcp_congestion" yaml:"tcp_congestion"`
-	DenyDomainsTable string `json:"deny_domains_table" yaml:"deny_domains_table"`
-	SpeedLimit       int64  `json:"speed_limit" yaml:"speed_limit"`
-	DisableIpv6      bool   `json:"disable_ipv6" yaml:"disable_ipv6"`
-	PreferIpv6       bool   `json:"prefer_ipv6" yaml:"prefer_ipv6"`
-	Log              bool   `json:"log" yaml:"log"`
-	LogInterval      int64  `json:"log_interval" yaml:"log_interval"`
-	IoCopyBuffer     int    `json:"io_copy_buffer" yaml:"io_copy_buffer"`
-	IdleTimeout      int64  `json:"idle_timeout" yaml:"idle_timeout"`
+	Policy           string `json:"policy" yaml:"policy,omitempty"`
+	AuthTable        string `json:"auth_table" yaml:"auth_table,omitempty"`
+	Dialer           string `json:"dialer" yaml:"dialer,omitempty"`
+	TcpCongestion    string `json:"tcp_congestion" yaml:"tcp_congestion,omitempty"`
+	DenyDomainsTable string `json:"deny_domains_table" yaml:"deny_domains_table,omitempty"`
+	SpeedLimit       int64  `json:"speed_limit" yaml:"speed_limit,omitempty"`
+	DisableIpv6      bool   `json:"disable_ipv6" yaml:"disable_ipv6,omitempty"`
+	PreferIpv6       bool   `json:"prefer_ipv6" yaml:"prefer_ipv6,omitempty"`
+	Log              bool   `json:"log" yaml:"log,omitempty"`
+	LogInterval      int64  `json:"log_interval" yaml:"log_interval,omitempty"`
+	IoCopyBuffer     int    `json:"io_copy_buffer" yaml:"io_copy_buffer,omitempty"`
+	IdleTimeout      int64  `json:"idle_timeout" yaml:"idle_timeout,omitempty"`
 }
 
 // HTTPTunnelConfig HTTP隧道配置，对应 liner/config.go HTTPConfig.Tunnel
 type HTTPTunnelConfig struct {
-	Enabled         bool     `json:"enabled" yaml:"enabled"`
-	AuthTable       string   `json:"auth_table" yaml:"auth_table"`
-	AllowListens    []string `json:"allow_listens" yaml:"allow_listens"`
-	SpeedLimit      int64    `json:"speed_limit" yaml:"speed_limit"`
-	EnableKeepAlive bool     `json:"enable_keep_alive" yaml:"enable_keep_alive"`
-	Log             bool     `json:"log" yaml:"log"`
+	Enabled         bool     `json:"enabled" yaml:"enabled,omitempty"`
+	AuthTable       string   `json:"auth_table" yaml:"auth_table,omitempty"`
+	AllowListens    []string `json:"allow_listens" yaml:"allow_listens,omitempty"`
+	SpeedLimit      int64    `json:"speed_limit" yaml:"speed_limit,omitempty"`
+	EnableKeepAlive bool     `json:"enable_keep_alive" yaml:"enable_keep_alive,omitempty"`
+	Log             bool     `json:"log" yaml:"log,omitempty"`
 }
 
 // HTTPWebIndexConfig Web Index配置
 type HTTPWebIndexConfig struct {
-	Root    string `json:"root" yaml:"root"`
-	Headers string `json:"headers" yaml:"headers"`
-	Charset string `json:"charset" yaml:"charset"`
-	Body    string `json:"body" yaml:"body"`
-	File    string `json:"file" yaml:"file"`
+	Root    string `json:"root" yaml:"root,omitempty"`
+	Headers string `json:"headers" yaml:"headers,omitempty"`
+	Charset string `json:"charset" yaml:"charset,omitempty"`
+	Body    string `json:"body" yaml:"body,omitempty"`
+	File    string `json:"file" yaml:"file,omitempty"`
 }
 
 // HTTPWebProxyConfig Web Proxy配置
 type HTTPWebProxyConfig struct {
-	Pass        string `json:"pass" yaml:"pass"`
-	AuthTable   string `json:"auth_table" yaml:"auth_table"`
-	StripPrefix string `json:"strip_prefix" yaml:"strip_prefix"`
-	SetHeaders  string `json:"set_headers" yaml:"set_headers"`
-	DumpFailure bool   `json:"dump_failure" yaml:"dump_failure"`
+	Pass        string `json:"pass" yaml:"pass,omitempty"`
+	AuthTable   string `json:"auth_table" yaml:"auth_table,omitempty"`
+	StripPrefix string `json:"strip_prefix" yaml:"strip_prefix,omitempty"`
+	SetHeaders  string `json:"set_headers" yaml:"set_headers,omitempty"`
+	DumpFailure bool   `json:"dump_failure" yaml:"dump_failure,omitempty"`
 }
 
 // HTTPWebDohConfig Web DoH配置
 type HTTPWebDohConfig struct {
-	Enabled   bool   `json:"enabled" yaml:"enabled"`
-	Policy    string `json:"policy" yaml:"policy"`
-	ProxyPass string `json:"proxy_pass" yaml:"proxy_pass"`
-	CacheSize int    `json:"cache_size" yaml:"cache_size"`
+	Enabled   bool   `json:"enabled" yaml:"enabled,omitempty"`
+	Policy    string `json:"policy" yaml:"policy,omitempty"`
+	ProxyPass string `json:"proxy_pass" yaml:"proxy_pass,omitempty"`
+	CacheSize int    `json:"cache_size" yaml:"cache_size,omitempty"`
 }
 
 // HTTPWebFastcgiConfig FastCGI配置
 type HTTPWebFastcgiConfig struct {
-	Enabled    bool   `json:"enabled" yaml:"enabled"`
-	Root       string `json:"root" yaml:"root"`
-	DefaultAPP string `json:"default_app" yaml:"default_app"`
+	Enabled    bool   `json:"enabled" yaml:"enabled,omitempty"`
+	Root       string `json:"root" yaml:"root,omitempty"`
+	DefaultAPP string `json:"default_app" yaml:"default_app,omitempty"`
 }
 
 // HTTPWebDavConfig WebDAV配置
 type HTTPWebDavConfig struct {
-	Enabled   bool   `json:"enabled" yaml:"enabled"`
-	Root      string `json:"root" yaml:"root"`
-	AuthTable string `json:"auth_table" yaml:"auth_table"`
+	Enabled   bool   `json:"enabled" yaml:"enabled,omitempty"`
+	Root      string `json:"root" yaml:"root,omitempty"`
+	AuthTable string `json:"auth_table" yaml:"auth_table,omitempty"`
 }
 
 // HTTPWebShellConfig Web Shell配置
 type HTTPWebShellConfig struct {
-	Enabled   bool              `json:"enabled" yaml:"enabled"`
-	AuthTable string            `json:"auth_table" yaml:"auth_table"`
-	Command   string            `json:"command" yaml:"command"`
-	Home      string            `json:"home" yaml:"home"`
-	Template  map[string]string `json:"template" yaml:"template"`
+	Enabled   bool              `json:"enabled" yaml:"enabled,omitempty"`
+	AuthTable string            `json:"auth_table" yaml:"auth_table,omitempty"`
+	Command   string            `json:"command" yaml:"command,omitempty"`
+	Home      string            `json:"home" yaml:"home,omitempty"`
+	Template  map[string]string `json:"template" yaml:"template,omitempty"`
 }
 
 // HTTPWebConfig 对应 liner/config.go HTTPConfig.Web
 type HTTPWebConfig struct {
-	Location      string               `json:"location" yaml:"location"`
-	TcpCongestion string               `json:"tcp_congestion" yaml:"tcp_congestion"`
-	Fastcgi       HTTPWebFastcgiConfig `json:"fastcgi" yaml:"fastcgi"`
-	Dav           HTTPWebDavConfig     `json:"dav" yaml:"dav"`
-	Doh           HTTPWebDohConfig     `json:"doh" yaml:"doh"`
-	Index         HTTPWebIndexConfig   `json:"index" yaml:"index"`
-	Proxy         HTTPWebProxyConfig   `json:"proxy" yaml:"proxy"`
-	Shell         HTTPWebShellConfig   `json:"shell" yaml:"shell"`
+	Location      string               `json:"location" yaml:"location,omitempty"`
+	TcpCongestion string               `json:"tcp_congestion" yaml:"tcp_congestion,omitempty"`
+	Fastcgi       HTTPWebFastcgiConfig `json:"fastcgi" yaml:"fastcgi,omitempty"`
+	Dav           HTTPWebDavConfig     `json:"dav" yaml:"dav,omitempty"`
+	Doh           HTTPWebDohConfig     `json:"doh" yaml:"doh,omitempty"`
+	Index         HTTPWebIndexConfig   `json:"index" yaml:"index,omitempty"`
+	Proxy         HTTPWebProxyConfig   `json:"proxy" yaml:"proxy,omitempty"`
+	Shell         HTTPWebShellConfig   `json:"shell" yaml:"shell,omitempty"`
 }
 
 // ServerConfig 对应 liner/config.go HTTPConfig.ServerConfig 中的值
 type ServerConfig struct {
-	Keyfile        string `json:"keyfile" yaml:"keyfile"`
-	Certfile       string `json:"certfile" yaml:"certfile"`
-	DisableHttp2   bool   `json:"disable_http2" yaml:"disable_http2"`
-	DisableHttp3   bool   `json:"disable_http3" yaml:"disable_http3"`
-	DisableTls11   bool   `json:"disable_tls11" yaml:"disable_tls11"`
-	DisableOcsp    bool   `json:"disable_ocsp" yaml:"disable_ocsp"`
-	PreferChacha20 bool   `json:"prefer_chacha20" yaml:"prefer_chacha20"`
+	Keyfile        string `json:"keyfile" yaml:"keyfile,omitempty"`
+	Certfile       string `json:"certfile" yaml:"certfile,omitempty"`
+	DisableHttp2   bool   `json:"disable_http2" yaml:"disable_http2,omitempty"`
+	DisableHttp3   bool   `json:"disable_http3" yaml:"disable_http3,omitempty"`
+	DisableTls11   bool   `json:"disable_tls11" yaml:"disable_tls11,omitempty"`
+	DisableOcsp    bool   `json:"disable_ocsp" yaml:"disable_ocsp,omitempty"`
+	PreferChacha20 bool   `json:"prefer_chacha20" yaml:"prefer_chacha20,omitempty"`
 }
 
 // HTTPConfig HTTP/HTTPS配置，对应 liner/config.go HTTPConfig
 type HTTPConfig struct {
-	Listen       []string                `json:"listen" yaml:"listen"`
-	ServerName   []string                `json:"server_name" yaml:"server_name"`
-	Keyfile      string                  `json:"keyfile" yaml:"keyfile"`
-	Certfile     string                  `json:"certfile" yaml:"certfile"`
-	PSK          string                  `json:"psk" yaml:"psk"`
-	ServerConfig map[string]ServerConfig `json:"server_config" yaml:"server_config"`
-	Forward      HTTPForwardConfig       `json:"forward" yaml:"forward"`
-	Tunnel       HTTPTunnelConfig        `json:"tunnel" yaml:"tunnel"`
-	Web          []HTTPWebConfig         `json:"web" yaml:"web"`
+	Listen       []string                `json:"listen" yaml:"listen,omitempty"`
+	ServerName   []string                `json:"server_name" yaml:"server_name,omitempty"`
+	Keyfile      string                  `json:"keyfile" yaml:"keyfile,omitempty"`
+	Certfile     string                  `json:"certfile" yaml:"certfile,omitempty"`
+	PSK          string                  `json:"psk" yaml:"psk,omitempty"`
+	ServerConfig map[string]ServerConfig `json:"server_config" yaml:"server_config,omitempty"`
+	Forward      HTTPForwardConfig       `json:"forward" yaml:"forward,omitempty"`
+	Tunnel       HTTPTunnelConfig        `json:"tunnel" yaml:"tunnel,omitempty"`
+	Web          []HTTPWebConfig         `json:"web" yaml:"web,omitempty"`
 }
 
 // TunnelConfig 隧道配置，对应 liner/config.go TunnelConfig
 type TunnelConfig struct {
-	RemoteListen    []string `json:"remote_listen" yaml:"remote_listen"`
-	ProxyPass       string   `json:"proxy_pass" yaml:"proxy_pass"`
-	Resolver        string   `json:"resolver" yaml:"resolver"`
-	DialTimeout     int      `json:"dial_timeout" yaml:"dial_timeout"`
-	Dialer          string   `json:"dialer" yaml:"dialer"`
-	SpeedLimit      int64    `json:"speed_limit" yaml:"speed_limit"`
-	EnableKeepAlive bool     `json:"enable_keep_alive" yaml:"enable_keep_alive"`
-	Log             bool     `json:"log" yaml:"log"`
+	RemoteListen    []string `json:"remote_listen" yaml:"remote_listen,omitempty"`
+	ProxyPass       string   `json:"proxy_pass" yaml:"proxy_pass,omitempty"`
+	Resolver        string   `json:"resolver" yaml:"resolver,omitempty"`
+	DialTimeout     int      `json:"dial_timeout" yaml:"dial_timeout,omitempty"`
+	Dialer          string   `json:"dialer" yaml:"dialer,omitempty"`
+	SpeedLimit      int64    `json:"speed_limit" yaml:"speed_limit,omitempty"`
+	EnableKeepAlive bool     `json:"enable_keep_alive" yaml:"enable_keep_alive,omitempty"`
+	Log             bool     `json:"log" yaml:"log,omitempty"`
 }
 
 // DnsConfig DNS配置，对应 liner/config.go DnsConfig
 type DnsConfig struct {
-	Listen    []string `json:"listen" yaml:"listen"`
-	Keyfile   string   `json:"keyfile" yaml:"keyfile"`
-	Policy    string   `json:"policy" yaml:"policy"`
-	ProxyPass string   `json:"proxy_pass" yaml:"proxy_pass"`
-	CacheSize int      `json:"cache_size" yaml:"cache_size"`
-	Log       bool     `json:"log" yaml:"log"`
+	Listen    []string `json:"listen" yaml:"listen,omitempty"`
+	Keyfile   string   `json:"keyfile" yaml:"keyfile,omitempty"`
+	Policy    string   `json:"policy" yaml:"policy,omitempty"`
+	ProxyPass string   `json:"proxy_pass" yaml:"proxy_pass,omitempty"`
+	CacheSize int      `json:"cache_size" yaml:"cache_size,omitempty"`
+	Log       bool     `json:"log" yaml:"log,omitempty"`
 }
 
 // SocksForwardConfig Socks转发配置
 type SocksForwardConfig struct {
-	Policy           string `json:"policy" yaml:"policy"`
-	AuthTable        string `json:"auth_table" yaml:"auth_table"`
-	Dialer           string `json:"dialer" yaml:"dialer"`
-	DenyDomainsTable string `json:"deny_domains_table" yaml:"deny_domains_table"`
-	SpeedLimit       int64  `json:"speed_limit" yaml:"speed_limit"`
-	DisableIpv6      bool   `json:"disable_ipv6" yaml:"disable_ipv6"`
-	PreferIpv6       bool   `json:"prefer_ipv6" yaml:"prefer_ipv6"`
-	Log              bool   `json:"log" yaml:"log"`
+	Policy           string `json:"policy" yaml:"policy,omitempty"`
+	AuthTable        string `json:"auth_table" yaml:"auth_table,omitempty"`
+	Dialer           string `json:"dialer" yaml:"dialer,omitempty"`
+	DenyDomainsTable string `json:"deny_domains_table" yaml:"deny_domains_table,omitempty"`
+	SpeedLimit       int64  `json:"speed_limit" yaml:"speed_limit,omitempty"`
+	DisableIpv6      bool   `json:"disable_ipv6" yaml:"disable_ipv6,omitempty"`
+	PreferIpv6       bool   `json:"prefer_ipv6" yaml:"prefer_ipv6,omitempty"`
+	Log              bool   `json:"log" yaml:"log,omitempty"`
 }
 
 // SocksConfig Socks代理配置，对应 liner/config.go SocksConfig
 type SocksConfig struct {
-	Listen  []string           `json:"listen" yaml:"listen"`
-	PSK     string             `json:"psk" yaml:"psk"`
-	Forward SocksForwardConfig `json:"forward" yaml:"forward"`
+	Listen  []string           `json:"listen" yaml:"listen,omitempty"`
+	PSK     string             `json:"psk" yaml:"psk,omitempty"`
+	Forward SocksForwardConfig `json:"forward" yaml:"forward,omitempty"`
 }
 
 // SniForwardConfig SNI转发配置
 type SniForwardConfig struct {
-	Policy      string `json:"policy" yaml:"policy"`
-	Dialer      string `json:"dialer" yaml:"dialer"`
-	DisableIpv6 bool   `json:"disable_ipv6" yaml:"disable_ipv6"`
-	PreferIpv6  bool   `json:"prefer_ipv6" yaml:"prefer_ipv6"`
-	Log         bool   `json:"log" yaml:"log"`
+	Policy      string `json:"policy" yaml:"policy,omitempty"`
+	Dialer      string `json:"dialer" yaml:"dialer,omitempty"`
+	DisableIpv6 bool   `json:"disable_ipv6" yaml:"disable_ipv6,omitempty"`
+	PreferIpv6  bool   `json:"prefer_ipv6" yaml:"prefer_ipv6,omitempty"`
+	Log         bool   `json:"log" yaml:"log,omitempty"`
 }
 
 // SniConfig SNI配置 (对应 liner/config.go SniConfig)
 type SniConfig struct {
-	Enabled bool             `json:"enabled" yaml:"enabled"`
-	Forward SniForwardConfig `json:"forward" yaml:"forward"`
+	Enabled bool             `json:"enabled" yaml:"enabled,omitempty"`
+	Forward SniForwardConfig `json:"forward" yaml:"forward,omitempty"`
 }
 
 // RedsocksForwardConfig Redsocks转发配置
 type RedsocksForwardConfig struct {
-	Dialer string `json:"dialer" yaml:"dialer"`
-	Log    bool   `json:"log" yaml:"log"`
+	Dialer string `json:"dialer" yaml:"dialer,omitempty"`
+	Log    bool   `json:"log" yaml:"log,omitempty"`
 }
 
 // RedsocksConfig Redsocks配置 (对应 liner/config.go RedsocksConfig)
 type RedsocksConfig struct {
-	Listen  []string              `json:"listen" yaml:"listen"`
-	Forward RedsocksForwardConfig `json:"forward" yaml:"forward"`
+	Listen  []string              `json:"listen" yaml:"listen,omitempty"`
+	Forward RedsocksForwardConfig `json:"forward" yaml:"forward,omitempty"`
 }
 
 // StreamConfig 流转发配置 (对应 liner/config.go StreamConfig)
 type StreamConfig struct {
-	Listen        []string `json:"listen" yaml:"listen"`
-	Keyfile       string   `json:"keyfile" yaml:"keyfile"`
-	Certfile      string   `json:"certfile" yaml:"certfile"`
-	ProxyPass     string   `json:"proxy_pass" yaml:"proxy_pass"`
-	ProxyProtocol uint     `json:"proxy_protocol" yaml:"proxy_protocol"`
-	DialTimeout   int      `json:"dial_timeout" yaml:"dial_timeout"`
-	Dialer        string   `json:"dialer" yaml:"dialer"`
-	SpeedLimit    int64    `json:"speed_limit" yaml:"speed_limit"`
-	Log           bool     `json:"log" yaml:"log"`
+	Listen        []string `json:"listen" yaml:"listen,omitempty"`
+	Keyfile       string   `json:"keyfile" yaml:"keyfile,omitempty"`
+	Certfile      string   `json:"certfile" yaml:"certfile,omitempty"`
+	ProxyPass     string   `json:"proxy_pass" yaml:"proxy_pass,omitempty"`
+	ProxyProtocol uint     `json:"proxy_protocol" yaml:"proxy_protocol,omitempty"`
+	DialTimeout   int      `json:"dial_timeout" yaml:"dial_timeout,omitempty"`
+	Dialer        string   `json:"dialer" yaml:"dialer,omitempty"`
+	SpeedLimit    int64    `json:"speed_limit" yaml:"speed_limit,omitempty"`
+	Log           bool     `json:"log" yaml:"log,omitempty"`
 }
 
 // SshConfig SSH配置 (对应 liner/config.go SshConfig)
 type SshConfig struct {
-	Listen           []string `json:"listen" yaml:"listen"`
-	ServerVersion    string   `json:"server_version" yaml:"server_version"`
-	TcpReadBuffer    int      `json:"tcp_read_buffer" yaml:"tcp_read_buffer"`
-	TcpWriteBuffer   int      `json:"tcp_write_buffer" yaml:"tcp_write_buffer"`
-	DisableKeepalive bool     `json:"disable_keepalive" yaml:"disable_keepalive"`
-	BannerFile       string   `json:"banner_file" yaml:"banner_file"`
-	HostKey          string   `json:"host_key" yaml:"host_key"`
-	AuthTable        string   `json:"auth_table" yaml:"auth_table"`
-	AuthorizedKeys   string   `json:"authorized_keys" yaml:"authorized_keys"`
-	Shell            string   `json:"shell" yaml:"shell"`
-	Home             string   `json:"home" yaml:"home"`
-	EnvFile          string   `json:"env_file" yaml:"env_file"`
-	Log              bool     `json:"log" yaml:"log"`
+	Listen           []string `json:"listen" yaml:"listen,omitempty"`
+	ServerVersion    string   `json:"server_version" yaml:"server_version,omitempty"`
+	TcpReadBuffer    int      `json:"tcp_read_buffer" yaml:"tcp_read_buffer,omitempty"`
+	TcpWriteBuffer   int      `json:"tcp_write_buffer" yaml:"tcp_write_buffer,omitempty"`
+	DisableKeepalive bool     `json:"disable_keepalive" yaml:"disable_keepalive,omitempty"`
+	BannerFile       string   `json:"banner_file" yaml:"banner_file,omitempty"`
+	HostKey          string   `json:"host_key" yaml:"host_key,omitempty"`
+	AuthTable        string   `json:"auth_table" yaml:"auth_table,omitempty"`
+	AuthorizedKeys   string   `json:"authorized_keys" yaml:"authorized_keys,omitempty"`
+	Shell            string   `json:"shell" yaml:"shell,omitempty"`
+	Home             string   `json:"home" yaml:"home,omitempty"`
+	EnvFile          string   `json:"env_file" yaml:"env_file,omitempty"`
+	Log              bool     `json:"log" yaml:"log,omitempty"`
 }
 
 // CronConfig (对应 liner/config.go Config.Cron)
 type CronConfig struct {
-	Spec    string `json:"spec" yaml:"spec"`
-	Command string `json:"command" yaml:"command"`
+	Spec    string `json:"spec" yaml:"spec,omitempty"`
+	Command string `json:"command" yaml:"command,omitempty"`
 }
 
 // Config liner完整配置结构，对应 liner/config.go Config
 type Config struct {
-	Global   GlobalConfig      `json:"global" yaml:"global"`
-	Cron     []CronConfig      `json:"cron" yaml:"cron"`
-	Dialer   map[string]string `json:"dialer" yaml:"dialer"`
-	Sni      SniConfig         `json:"sni" yaml:"sni"`
-	Https    []HTTPConfig      `json:"https" yaml:"https"`
-	Http     []HTTPConfig      `json:"http" yaml:"http"`
-	Socks    []SocksConfig     `json:"socks" yaml:"socks"`
-	Redsocks []RedsocksConfig  `json:"redsocks" yaml:"redsocks"`
-	Tunnel   []TunnelConfig    `json:"tunnel" yaml:"tunnel"`
-	Stream   []StreamConfig    `json:"stream" yaml:"stream"`
-	Ssh      []SshConfig       `json:"ssh" yaml:"ssh"`
-	Dns      []DnsConfig       `json:"dns" yaml:"dns"`
+	Global   GlobalConfig      `json:"global" yaml:"global,omitempty"`
+	Cron     []CronConfig      `json:"cron" yaml:"cron,omitempty"`
+	Dialer   map[string]string `json:"dialer" yaml:"dialer,omitempty"`
+	Sni      SniConfig         `json:"sni" yaml:"sni,omitempty"`
+	Https    []HTTPConfig      `json:"https" yaml:"https,omitempty"`
+	Http     []HTTPConfig      `json:"http" yaml:"http,omitempty"`
+	Socks    []SocksConfig     `json:"socks" yaml:"socks,omitempty"`
+	Redsocks []RedsocksConfig  `json:"redsocks" yaml:"redsocks,omitempty"`
+	Tunnel   []TunnelConfig    `json:"tunnel" yaml:"tunnel,omitempty"`
+	Stream   []StreamConfig    `json:"stream" yaml:"stream,omitempty"`
+	Ssh      []SshConfig       `json:"ssh" yaml:"ssh,omitempty"`
+	Dns      []DnsConfig       `json:"dns" yaml:"dns,omitempty"`
 }
 
 // NewDefaultGlobalConfig 创建默认全局配置
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/bensonfx/mcp-liner/internal/dialer"
+)
+
+// 差异类型
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change 两份配置之间的一处差异
+//
+// Path 中列表条目用标识而不是下标表示，如 tunnel[127.0.0.1:10022].dialer、
+// https[:443 example.com].forward.policy、dialer[cloud]。
+// 新增条目的 Old 为空，删除条目的 New 为空；拨号器URL中的密码和密钥参数已隐藏。
+type Change struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// String 返回可读的差异描述
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s: added %s", c.Path, describeValue(c.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s: removed %s", c.Path, describeValue(c.Old))
+	default:
+		// 多行值（如 policy 模板）只提示已变更
+		if isMultiline(c.Old) || isMultiline(c.New) {
+			return fmt.Sprintf("~ %s: changed (multi-line value)", c.Path)
+		}
+		return fmt.Sprintf("~ %s: %s → %s", c.Path, describeValue(c.Old), describeValue(c.New))
+	}
+}
+
+// identities 列表条目的标识函数，按标识而不是下标匹配新旧配置中的条目
+var identities = map[reflect.Type]func(v reflect.Value) string{
+	reflect.TypeOf(HTTPConfig{}): func(v reflect.Value) string {
+		c := v.Interface().(HTTPConfig)
+		return joinIdentity(strings.Join(c.Listen, ","), strings.Join(c.ServerName, ","))
+	},
+	reflect.TypeOf(TunnelConfig{}): func(v reflect.Value) string {
+		return strings.Join(v.Interface().(TunnelConfig).RemoteListen, ",")
+	},
+	reflect.TypeOf(HTTPWebConfig{}): func(v reflect.Value) string {
+		return v.Interface().(HTTPWebConfig).Location
+	},
+	reflect.TypeOf(CronConfig{}): func(v reflect.Value) string {
+		c := v.Interface().(CronConfig)
+		return joinIdentity(c.Spec, c.Command)
+	},
+	reflect.TypeOf(SocksConfig{}):    listenIdentity,
+	reflect.TypeOf(RedsocksConfig{}): listenIdentity,
+	reflect.TypeOf(StreamConfig{}):   listenIdentity,
+	reflect.TypeOf(SshConfig{}):      listenIdentity,
+	reflect.TypeOf(DnsConfig{}):      listenIdentity,
+}
+
+// Diff 比较两份配置，返回从 a 到 b 的差异，按配置段顺序排列
+// 列表条目按监听地址、server_name、remote_listen 等标识匹配，调整顺序不会产生差异
+func Diff(a, b *Config) []Change {
+	if a == nil {
+		a = &Config{}
+	}
+	if b == nil {
+		b = &Config{}
+	}
+	var changes []Change
+	diffValue(&changes, "", reflect.ValueOf(*a), reflect.ValueOf(*b))
+	for i := range changes {
+		if strings.HasPrefix(changes[i].Path, "dialer[") {
+			changes[i].Old = redactDialer(changes[i].Old)
+			changes[i].New = redactDialer(changes[i].New)
+		}
+	}
+	return changes
+}
+
+// redactDialer 隐藏拨号器URL中的密码和密钥参数，差异会出现在摘要和工具响应中
+func redactDialer(value any) any {
+	if s, ok := value.(string); ok && s != "" {
+		return dialer.Redact(s)
+	}
+	return value
+}
+
+// diffValue 递归比较两个值
+func diffValue(changes *[]Change, path string, a, b reflect.Value) {
+	switch a.Kind() {
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			diffValue(changes, joinPath(path, name), a.Field(i), b.Field(i))
+		}
+	case reflect.Map:
+		keys := map[string]bool{}
+		for _, k := range a.MapKeys() {
+			keys[k.String()] = true
+		}
+		for _, k := range b.MapKeys() {
+			keys[k.String()] = true
+		}
+		for _, k := range sortedKeys(keys) {
+			key := reflect.ValueOf(k)
+			diffEntry(changes, fmt.Sprintf("%s[%s]", path, k), a.MapIndex(key), b.MapIndex(key))
+		}
+	case reflect.Slice:
+		identity, ok := identities[a.Type().Elem()]
+		if !ok {
+			// 字符串列表等作为整体比较
+			diffLeaf(changes, path, a, b)
+			return
+		}
+		aEntries, aOrder := indexEntries(a, identity)
+		bEntries, bOrder := indexEntries(b, identity)
+		for _, id := range aOrder {
+			diffEntry(changes, fmt.Sprintf("%s[%s]", path, id), aEntries[id], bEntries[id])
+		}
+		for _, id := range bOrder {
+			if _, ok := aEntries[id]; !ok {
+				diffEntry(changes, fmt.Sprintf("%s[%s]", path, id), reflect.Value{}, bEntries[id])
+			}
+		}
+	default:
+		diffLeaf(changes, path, a, b)
+	}
+}
+
+// diffEntry 比较映射或列表中的条目，任一侧不存在时整体记为新增或删除
+func diffEntry(changes *[]Change, path string, a, b reflect.Value) {
+	switch {
+	case !a.IsValid():
+		*changes = append(*changes, Change{Kind: ChangeAdded, Path: path, New: b.Interface()})
+	case !b.IsValid():
+		*changes = append(*changes, Change{Kind: ChangeRemoved, Path: path, Old: a.Interface()})
+	default:
+		diffValue(changes, path, a, b)
+	}
+}
+
+// diffLeaf 比较不再展开的值，零值视为未设置
+func diffLeaf(changes *[]Change, path string, a, b reflect.Value) {
+	aZero, bZero := isEmptyValue(a), isEmptyValue(b)
+	switch {
+	case aZero && bZero:
+	case aZero:
+		*changes = append(*changes, Change{Kind: ChangeAdded, Path: path, New: b.Interface()})
+	case bZero:
+		*changes = append(*changes, Change{Kind: ChangeRemoved, Path: path, Old: a.Interface()})
+	case !reflect.DeepEqual(a.Interface(), b.Interface()):
+		*changes = append(*changes, Change{Kind: ChangeChanged, Path: path, Old: a.Interface(), New: b.Interface()})
+	}
+}
+
+// indexEntries 按标识索引列表条目，返回索引和出现顺序
+// 没有标识的条目使用下标，重复的标识追加序号以区分
+func indexEntries(v reflect.Value, identity func(reflect.Value) string) (map[string]reflect.Value, []string) {
+	entries := make(map[string]reflect.Value, v.Len())
+	order := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		id := identity(v.Index(i))
+		if id == "" {
+			id = fmt.Sprintf("#%d", i)
+		}
+		base := id
+		for n := 2; ; n++ {
+			if _, dup := entries[id]; !dup {
+				break
+			}
+			id = fmt.Sprintf("%s#%d", base, n)
+		}
+		entries[id] = v.Index(i)
+		order = append(order, id)
+	}
+	return entries, order
+}
+
+// listenIdentity 以监听地址作为条目标识
+func listenIdentity(v reflect.Value) string {
+	return strings.Join(v.FieldByName("Listen").Interface().([]string), ",")
+}
+
+// joinIdentity 拼接非空的标识片段
+func joinIdentity(parts ...string) string {
+	nonEmpty := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
+
+// joinPath 拼接字段路径
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// isEmptyValue 判断值是否为零值，空列表和空映射也视为零值
+func isEmptyValue(v reflect.Value) bool {
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+		return true
+	}
+	return v.IsZero()
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys(keys map[string]bool) []string {
+	result := make([]string, 0, len(keys))
+	for k := range keys {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// describeValue 返回值的简短描述，结构体只列出已设置的字段名
+func describeValue(value any) string {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		if strings.Contains(v.String(), "\n") {
+			return "multi-line value"
+		}
+		return fmt.Sprintf("%q", v.String())
+	case reflect.Struct:
+		var fields []string
+		for i := 0; i < v.NumField(); i++ {
+			if isEmptyValue(v.Field(i)) {
+				continue
+			}
+			name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+			field := v.Field(i)
+			if (field.Kind() == reflect.String && !strings.Contains(field.String(), "\n")) || field.Kind() == reflect.Slice {
+				fields = append(fields, fmt.Sprintf("%s=%v", name, field.Interface()))
+				continue
+			}
+			fields = append(fields, name)
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// isMultiline 判断值是否为多行字符串
+func isMultiline(value any) bool {
+	s, ok := value.(string)
+	return ok && strings.Contains(s, "\n")
+}
@@ -0,0 +1,309 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultIndent 新文档和无法识别缩进时使用的缩进宽度，与 ToYAML 一致
+const defaultIndent = 4
+
+// Document 基于 yaml.Node 的配置文档，用于在修改配置时保留原文件的格式
+//
+// 通过 Config 读取类型化的配置，修改后用 Update 写回：
+// 注释、键顺序、标量的引号风格，以及 Config 未建模的字段（如新版本 liner 支持的配置项）都会保留，
+// 只有实际变化的值会被改写。
+type Document struct {
+	root   *yaml.Node // DocumentNode
+	indent int
+}
+
+// ParseDocument 解析YAML配置文档，content 为空时返回空文档
+func ParseDocument(content string) (*Document, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &root); err != nil {
+		return nil, err
+	}
+	if root.Kind == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode}
+	}
+	if len(root.Content) == 0 {
+		root.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	if root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("config must be a YAML mapping, got %s", nodeKindName(root.Content[0].Kind))
+	}
+
+	return &Document{
+		root:   &root,
+		indent: detectIndent(content),
+	}, nil
+}
+
+// Config 将文档解码为配置结构
+func (d *Document) Config() (*Config, error) {
+	var cfg Config
+	if err := d.root.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Update 将配置写回文档，仅修改与 cfg 不一致的部分
+func (d *Document) Update(cfg *Config) error {
+	var updated yaml.Node
+	if err := updated.Encode(cfg); err != nil {
+		return err
+	}
+	mergeNode(d.root.Content[0], &updated, reflect.TypeOf(Config{}))
+	return nil
+}
+
+// String 将文档序列化为YAML，缩进宽度与原文件一致
+func (d *Document) String() (string, error) {
+	// 空文档编码为 {}，与空配置的 ToYAML 输出保持一致
+	if len(d.root.Content[0].Content) == 0 {
+		return "{}\n", nil
+	}
+	return encodeYAML(d.root, d.indent)
+}
+
+// encodeYAML 以指定缩进序列化
+func encodeYAML(v any, indent int) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// mergeNode 将 updated 合并到 node，t 为节点对应的Go类型
+func mergeNode(node, updated *yaml.Node, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case node.Kind == yaml.MappingNode && updated.Kind == yaml.MappingNode:
+		mergeMapping(node, updated, t)
+	case node.Kind == yaml.SequenceNode && updated.Kind == yaml.SequenceNode && t.Kind() == reflect.Slice:
+		mergeSequence(node, updated, t.Elem())
+	case node.Kind == yaml.ScalarNode && updated.Kind == yaml.ScalarNode:
+		if node.Value == updated.Value && node.ShortTag() == updated.ShortTag() {
+			return
+		}
+		style := updated.Style
+		// 原值带引号时，新的字符串值沿用原来的引号风格
+		if style == 0 && updated.ShortTag() == "!!str" && node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 {
+			style = node.Style
+		}
+		node.Value = updated.Value
+		node.Tag = updated.Tag
+		node.Style = style
+	default:
+		// 类型不同（或原节点为别名）时，值相同则保留原节点，否则整体替换并保留注释
+		if nodesEqual(node, updated, t) {
+			return
+		}
+		replaceNode(node, updated)
+	}
+}
+
+// mergeSequence 合并列表节点，elem 为条目的Go类型
+// 有标识的条目（见 identities）按监听地址、server_name 等标识与原条目匹配，字符串按值匹配，其余条目按下标匹配；
+// 匹配到的原条目就地合并并保留注释，没有匹配的新条目直接加入，配置中已删除的原条目连同注释一起移除
+func mergeSequence(node, updated *yaml.Node, elem reflect.Type) {
+	matched := make([]*yaml.Node, len(updated.Content))
+	used := make([]bool, len(node.Content))
+
+	identity, ok := identities[elem]
+	if !ok && elem.Kind() == reflect.String {
+		// 字符串列表（如 server_name）以值作为标识
+		identity, ok = func(v reflect.Value) string { return v.String() }, true
+	}
+	if ok {
+		ids := make([]string, len(node.Content))
+		for i, item := range node.Content {
+			ids[i] = nodeIdentity(item, elem, identity)
+		}
+		for i, item := range updated.Content {
+			id := nodeIdentity(item, elem, identity)
+			if id == "" {
+				continue
+			}
+			for j := range node.Content {
+				if !used[j] && ids[j] == id {
+					matched[i], used[j] = node.Content[j], true
+					break
+				}
+			}
+		}
+	}
+	// 标识改变或没有标识的条目，与同一位置尚未匹配的原条目合并
+	for i := range updated.Content {
+		if matched[i] == nil && i < len(node.Content) && !used[i] {
+			matched[i], used[i] = node.Content[i], true
+		}
+	}
+
+	content := make([]*yaml.Node, 0, len(updated.Content))
+	for i, item := range updated.Content {
+		if matched[i] == nil {
+			content = append(content, item)
+			continue
+		}
+		mergeNode(matched[i], item, elem)
+		content = append(content, matched[i])
+	}
+	node.Content = content
+}
+
+// nodeIdentity 将条目节点解码为 elem 后计算标识，无法解码时返回空字符串
+func nodeIdentity(node *yaml.Node, elem reflect.Type, identity func(reflect.Value) string) string {
+	v := reflect.New(elem)
+	if err := node.Decode(v.Interface()); err != nil {
+		return ""
+	}
+	return identity(v.Elem())
+}
+
+// mergeMapping 合并映射节点
+// 原文档中的键保持原有顺序；新增的键追加到末尾；
+// 配置中已删除（零值）的键被移除，除非原值本身就是零值；Config 未建模的键原样保留
+func mergeMapping(node, updated *yaml.Node, t reflect.Type) {
+	updatedValues := make(map[string]*yaml.Node, len(updated.Content)/2)
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		updatedValues[updated.Content[i].Value] = updated.Content[i+1]
+	}
+
+	fields := knownFields(t)
+	existing := make(map[string]bool, len(node.Content)/2)
+	content := make([]*yaml.Node, 0, len(node.Content))
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		existing[key.Value] = true
+
+		fieldType, known := fields(key.Value)
+		if newValue, ok := updatedValues[key.Value]; ok {
+			mergeNode(value, newValue, fieldType)
+			content = append(content, key, value)
+			continue
+		}
+		if !known || isZeroNode(value, fieldType) {
+			content = append(content, key, value)
+		}
+	}
+
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		if !existing[updated.Content[i].Value] {
+			content = append(content, updated.Content[i], updated.Content[i+1])
+		}
+	}
+
+	node.Content = content
+}
+
+// knownFields 返回查询映射键对应Go类型的函数，第二个返回值表示该键是否被建模
+func knownFields(t reflect.Type) func(string) (reflect.Type, bool) {
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			if name != "-" {
+				fields[name] = field.Type
+			}
+		}
+		return func(key string) (reflect.Type, bool) {
+			ft, ok := fields[key]
+			if !ok {
+				return reflect.TypeOf((*any)(nil)).Elem(), false
+			}
+			return ft, true
+		}
+	case reflect.Map:
+		return func(string) (reflect.Type, bool) { return t.Elem(), true }
+	default:
+		return func(string) (reflect.Type, bool) { return reflect.TypeOf((*any)(nil)).Elem(), false }
+	}
+}
+
+// isZeroNode 判断节点解码为 t 后是否为零值
+func isZeroNode(node *yaml.Node, t reflect.Type) bool {
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return true
+	}
+	v := reflect.New(t)
+	if err := node.Decode(v.Interface()); err != nil {
+		return false
+	}
+	elem := v.Elem()
+	// 空列表、空映射也视为零值
+	if (elem.Kind() == reflect.Slice || elem.Kind() == reflect.Map) && elem.Len() == 0 {
+		return true
+	}
+	return elem.IsZero()
+}
+
+// nodesEqual 判断两个节点解码为 t 后是否相等
+func nodesEqual(a, b *yaml.Node, t reflect.Type) bool {
+	va, vb := reflect.New(t), reflect.New(t)
+	if a.Decode(va.Interface()) != nil || b.Decode(vb.Interface()) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va.Elem().Interface(), vb.Elem().Interface())
+}
+
+// replaceNode 用 updated 替换 node 的内容，保留 node 上的注释
+func replaceNode(node, updated *yaml.Node) {
+	head, line, foot := node.HeadComment, node.LineComment, node.FootComment
+	*node = *updated
+	node.HeadComment, node.LineComment, node.FootComment = head, line, foot
+}
+
+// detectIndent 根据原文件推断缩进宽度，取最小的非零行首空格数
+func detectIndent(content string) int {
+	indent := 0
+	for _, l := range strings.Split(content, "\n") {
+		trimmed := strings.TrimLeft(l, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		n := len(l) - len(trimmed)
+		if n > 0 && (indent == 0 || n < indent) {
+			indent = n
+		}
+	}
+	if indent < 2 || indent > 8 {
+		return defaultIndent
+	}
+	return indent
+}
+
+// nodeKindName 返回节点类型名称
+func nodeKindName(kind yaml.Kind) string {
+	switch kind {
+	case yaml.SequenceNode:
+		return "sequence"
+	case yaml.ScalarNode:
+		return "scalar"
+	case yaml.AliasNode:
+		return "alias"
+	default:
+		return "mapping"
+	}
+}
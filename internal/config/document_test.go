@@ -0,0 +1,269 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocumentRoundTrip(t *testing.T) {
+	const original = `# liner 配置
+global:
+  log_level: info # 日志级别
+  max_idle_conns: 16
+  future_option: keep-me
+https:
+  - listen: [':443']
+    server_name: ['example.com']
+    forward:
+      policy: "bypass_auth"
+      dialer: cloud
+dialer:
+  cloud: "socks5://1.2.3.4:1080"
+`
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "unchanged",
+			modify: func(cfg *Config) {},
+			want:   []string{original},
+		},
+		{
+			name: "change scalar keeps comments and quotes",
+			modify: func(cfg *Config) {
+				cfg.Global.LogLevel = "debug"
+				cfg.Https[0].Forward.Policy = "proxy_auth"
+			},
+			want: []string{
+				"# liner 配置\n",
+				"log_level: debug # 日志级别\n",
+				"      policy: \"proxy_auth\"\n",
+				"  future_option: keep-me\n",
+				"  - listen: [':443']\n",
+			},
+		},
+		{
+			name: "new keys are appended in order",
+			modify: func(cfg *Config) {
+				cfg.Global.DialTimeout = 30
+				cfg.Dialer["local"] = "local"
+			},
+			want: []string{
+				"  future_option: keep-me\n  dial_timeout: 30\nhttps:",
+				"  cloud: \"socks5://1.2.3.4:1080\"\n  local: local\n",
+			},
+		},
+		{
+			name: "cleared values are removed",
+			modify: func(cfg *Config) {
+				cfg.Global.MaxIdleConns = 0
+				cfg.Https[0].Forward.Dialer = ""
+				delete(cfg.Dialer, "cloud")
+			},
+			notWant: []string{"max_idle_conns", "dialer: cloud", "socks5://"},
+			want:    []string{"future_option: keep-me"},
+		},
+		{
+			name: "list items appended and removed",
+			modify: func(cfg *Config) {
+				cfg.Https[0].ServerName = append(cfg.Https[0].ServerName, "www.example.com")
+				cfg.Https[0].Listen = nil
+			},
+			want:    []string{"server_name: ['example.com', www.example.com]"},
+			notWant: []string{"listen:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument(original)
+			if err != nil {
+				t.Fatalf("ParseDocument() error = %v", err)
+			}
+			cfg, err := doc.Config()
+			if err != nil {
+				t.Fatalf("Config() error = %v", err)
+			}
+			tt.modify(cfg)
+			if err := doc.Update(cfg); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			got, err := doc.String()
+			if err != nil {
+				t.Fatalf("String() error = %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output missing %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("output should not contain %q:\n%s", notWant, got)
+				}
+			}
+
+			// 修改后的文档应能解码回相同的配置
+			reparsed, err := FromYAML(got)
+			if err != nil {
+				t.Fatalf("FromYAML() error = %v", err)
+			}
+			if reparsed.Global != cfg.Global || len(reparsed.Https) != len(cfg.Https) || len(reparsed.Dialer) != len(cfg.Dialer) {
+				t.Errorf("round-tripped config differs:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestDocumentSequenceEntries(t *testing.T) {
+	const original = `https:
+  # 主站
+  - listen: [':443']
+    server_name:
+      - example.com # 主域名
+      - www.example.com # 备用域名
+    future_option: primary
+  # API
+  - listen: [':443']
+    server_name: [api.example.com]
+    future_option: api
+tunnel:
+  # SSH 隧道
+  - remote_listen: ['127.0.0.1:10022']
+    proxy_pass: 127.0.0.1:22
+  # RDP 隧道
+  - remote_listen: ['127.0.0.1:13389']
+    proxy_pass: 127.0.0.1:3389
+`
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "removed entry takes its comments and unknown keys with it",
+			modify: func(cfg *Config) { cfg.Https = cfg.Https[1:] },
+			want: []string{
+				"https:\n  # API\n  - listen: [':443']\n    server_name: [api.example.com]\n    future_option: api\n",
+			},
+			notWant: []string{"主站", "primary", "主域名"},
+		},
+		{
+			name: "entries are matched by identity when reordered",
+			modify: func(cfg *Config) {
+				cfg.Tunnel[0], cfg.Tunnel[1] = cfg.Tunnel[1], cfg.Tunnel[0]
+				cfg.Tunnel[0].ProxyPass = "10.0.0.2:3389"
+			},
+			want: []string{
+				"  # RDP 隧道\n  - remote_listen: ['127.0.0.1:13389']\n    proxy_pass: 10.0.0.2:3389\n  # SSH 隧道\n  - remote_listen: ['127.0.0.1:10022']\n    proxy_pass: 127.0.0.1:22\n",
+			},
+		},
+		{
+			name: "changed identity keeps the entry in place",
+			modify: func(cfg *Config) {
+				cfg.Tunnel[0].RemoteListen = []string{"127.0.0.1:20022"}
+			},
+			want: []string{"  # SSH 隧道\n  - remote_listen: ['127.0.0.1:20022']\n"},
+		},
+		{
+			name:    "removed string keeps the comments of the remaining ones",
+			modify:  func(cfg *Config) { cfg.Https[0].ServerName = cfg.Https[0].ServerName[1:] },
+			want:    []string{"      - www.example.com # 备用域名\n    future_option: primary\n"},
+			notWant: []string{"主域名"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument(original)
+			if err != nil {
+				t.Fatalf("ParseDocument() error = %v", err)
+			}
+			cfg, err := doc.Config()
+			if err != nil {
+				t.Fatalf("Config() error = %v", err)
+			}
+			tt.modify(cfg)
+			if err := doc.Update(cfg); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			got, err := doc.String()
+			if err != nil {
+				t.Fatalf("String() error = %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output missing %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("output should not contain %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", content: "", want: "{}\n"},
+		{name: "sequence root", content: "- a\n", wantErr: true},
+		{name: "invalid", content: "global: [", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseDocument(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDocument() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got, err := doc.String()
+			if err != nil {
+				t.Fatalf("String() error = %v", err)
+			}
+			if !strings.Contains(got, strings.TrimSpace(tt.want)) {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentNewConfig(t *testing.T) {
+	doc, err := ParseDocument("")
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	cfg := &Config{
+		Global: GlobalConfig{LogLevel: "info"},
+		Dialer: map[string]string{"local": "local"},
+	}
+	if err := doc.Update(cfg); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err := doc.String()
+	if err != nil {
+		t.Fatalf("String() error = %v", err)
+	}
+	// 零值字段不输出
+	want := "global:\n    log_level: info\ndialer:\n    local: local\n"
+	if got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
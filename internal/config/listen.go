@@ -0,0 +1,209 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Listener 配置中的一个监听地址
+type Listener struct {
+	Section   string   // 所在配置段，如 https
+	Field     string   // 字段路径，如 https[0].listen[1]
+	Addr      string   // 原始地址
+	Host      string   // 为空时监听所有地址
+	Port      int      // 端口
+	Protocols []string // tcp、udp
+	// ServerNames 非空时表示 https 监听，server_name 不相交的 https 监听可以通过 SNI 共享端口
+	ServerNames []string
+}
+
+// RemoteListener 隧道在服务端请求监听的地址，经同一 dialer 的隧道由同一服务端监听
+type RemoteListener struct {
+	Listener
+	Dialer string
+}
+
+// ListenError 无法解析的监听地址
+type ListenError struct {
+	Field string
+	Err   error
+}
+
+// dnsListenSchemes DNS 监听地址支持的前缀及对应的协议，无前缀时同时监听 UDP 和 TCP
+var dnsListenSchemes = map[string][]string{
+	"udp":   {"udp"},
+	"tcp":   {"tcp"},
+	"tls":   {"tcp"},
+	"https": {"tcp"},
+}
+
+// Listeners 按配置段顺序返回所有监听地址，无法解析的地址放在第二个返回值中
+func Listeners(cfg *Config) ([]Listener, []ListenError) {
+	var listeners []Listener
+	var errs []ListenError
+	add := func(section string, index int, addrs []string, protocols []string, serverNames []string) {
+		for i, addr := range addrs {
+			l := Listener{
+				Section:     section,
+				Field:       fmt.Sprintf("%s[%d].listen[%d]", section, index, i),
+				Addr:        addr,
+				Protocols:   protocols,
+				ServerNames: serverNames,
+			}
+			hostPort := addr
+			if section == "dns" {
+				if scheme, rest, ok := strings.Cut(addr, "://"); ok {
+					if l.Protocols, ok = dnsListenSchemes[scheme]; !ok {
+						errs = append(errs, ListenError{l.Field, fmt.Errorf("unsupported scheme %q in listen address %q, must be one of: udp, tcp, tls, https", scheme, addr)})
+						continue
+					}
+					hostPort = rest
+				}
+			}
+			var err error
+			if l.Host, l.Port, err = ParseListenAddress(hostPort); err != nil {
+				errs = append(errs, ListenError{l.Field, err})
+				continue
+			}
+			listeners = append(listeners, l)
+		}
+	}
+
+	// https 默认同时在同一端口上通过 UDP 提供 HTTP/3
+	httpsProtocols := []string{"tcp", "udp"}
+	if cfg.Global.DisableHttp3 {
+		httpsProtocols = []string{"tcp"}
+	}
+	for i, httpsCfg := range cfg.Https {
+		add("https", i, httpsCfg.Listen, httpsProtocols, httpsServerNames(httpsCfg))
+	}
+	for i, httpCfg := range cfg.Http {
+		add("http", i, httpCfg.Listen, []string{"tcp"}, nil)
+	}
+	for i, socksCfg := range cfg.Socks {
+		add("socks", i, socksCfg.Listen, []string{"tcp"}, nil)
+	}
+	for i, redsocksCfg := range cfg.Redsocks {
+		add("redsocks", i, redsocksCfg.Listen, []string{"tcp"}, nil)
+	}
+	for i, streamCfg := range cfg.Stream {
+		add("stream", i, streamCfg.Listen, []string{"tcp"}, nil)
+	}
+	for i, sshCfg := range cfg.Ssh {
+		add("ssh", i, sshCfg.Listen, []string{"tcp"}, nil)
+	}
+	for i, dnsCfg := range cfg.Dns {
+		add("dns", i, dnsCfg.Listen, []string{"udp", "tcp"}, nil)
+	}
+	return listeners, errs
+}
+
+// RemoteListeners 返回所有隧道的 remote_listen，无法解析的地址放在第二个返回值中
+func RemoteListeners(cfg *Config) ([]RemoteListener, []ListenError) {
+	var remotes []RemoteListener
+	var errs []ListenError
+	for i, tunnelCfg := range cfg.Tunnel {
+		for j, addr := range tunnelCfg.RemoteListen {
+			field := fmt.Sprintf("tunnel[%d].remote_listen[%d]", i, j)
+			host, port, err := ParseListenAddress(addr)
+			if err != nil {
+				errs = append(errs, ListenError{field, err})
+				continue
+			}
+			remotes = append(remotes, RemoteListener{
+				Listener: Listener{Section: "tunnel", Field: field, Addr: addr, Host: host, Port: port, Protocols: []string{"tcp"}},
+				Dialer:   tunnelCfg.Dialer,
+			})
+		}
+	}
+	return remotes, errs
+}
+
+// Conflict 判断两个监听是否会绑定同一地址和端口，冲突时返回共有的协议，否则返回空字符串
+func (l Listener) Conflict(other Listener) string {
+	proto := sharedProtocol(l.Protocols, other.Protocols)
+	if proto == "" || l.Port != other.Port || !HostsOverlap(l.Host, other.Host) || sniShareable(l, other) {
+		return ""
+	}
+	return proto
+}
+
+// Conflict 判断两个 remote_listen 是否会在同一服务端上绑定同一地址和端口
+func (r RemoteListener) Conflict(other RemoteListener) bool {
+	return r.Dialer == other.Dialer && r.Listener.Conflict(other.Listener) != ""
+}
+
+// ParseListenAddress 解析 host:port 形式的监听地址，host 可为空、IP或主机名
+func ParseListenAddress(addr string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid listen address %q, expected host:port such as \":443\" or \"127.0.0.1:443\"", addr)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid port %q in listen address %q, must be between 1 and 65535", portStr, addr)
+	}
+	if host != "" && strings.ContainsAny(host, " /") {
+		return "", 0, fmt.Errorf("invalid host %q in listen address %q", host, addr)
+	}
+	return host, port, nil
+}
+
+// HostsOverlap 判断两个监听主机是否会绑定到同一地址
+// 空主机和 :: 监听所有地址，0.0.0.0 监听所有IPv4地址
+func HostsOverlap(a, b string) bool {
+	if strings.EqualFold(a, b) || a == "" || b == "" || a == "::" || b == "::" {
+		return true
+	}
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	switch {
+	case errA == nil && errB == nil:
+		addrA, addrB = addrA.Unmap(), addrB.Unmap()
+		if addrA.Is4() && addrB.Is4() && (addrA.IsUnspecified() || addrB.IsUnspecified()) {
+			return true
+		}
+		return addrA == addrB
+	case errA == nil && addrA.IsUnspecified(), errB == nil && addrB.IsUnspecified():
+		// 0.0.0.0 与主机名：主机名可能解析为IPv4地址
+		return true
+	default:
+		return false
+	}
+}
+
+// sniShareable 判断两个 https 监听能否通过 SNI 共享同一地址
+func sniShareable(a, b Listener) bool {
+	if len(a.ServerNames) == 0 || len(b.ServerNames) == 0 {
+		return false
+	}
+	for _, name := range a.ServerNames {
+		if slices.Contains(b.ServerNames, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// httpsServerNames 返回小写的 server_name，用于判断 SNI 共享
+func httpsServerNames(httpsCfg HTTPConfig) []string {
+	names := make([]string, 0, len(httpsCfg.ServerName))
+	for _, name := range httpsCfg.ServerName {
+		names = append(names, strings.ToLower(name))
+	}
+	return names
+}
+
+// sharedProtocol 返回两个协议列表共有的第一个协议，没有时返回空字符串
+func sharedProtocol(a, b []string) string {
+	for _, p := range a {
+		if slices.Contains(b, p) {
+			return p
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHostsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"", "127.0.0.1", true},
+		{"0.0.0.0", "192.168.1.1", true},
+		{"0.0.0.0", "::1", false},
+		{"::", "127.0.0.1", true},
+		{"::ffff:127.0.0.1", "127.0.0.1", true},
+		{"127.0.0.1", "127.0.0.2", false},
+		{"Example.org", "example.org", true},
+		{"0.0.0.0", "example.org", true},
+		{"example.org", "127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := HostsOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("HostsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestListeners(t *testing.T) {
+	cfg := &Config{
+		Global: GlobalConfig{DisableHttp3: true},
+		Https:  []HTTPConfig{{Listen: []string{":443"}, ServerName: []string{"A.example"}}},
+		Dns:    []DnsConfig{{Listen: []string{":53", "tls://:853", "quic://:853", "udp://:99999"}}},
+	}
+
+	listeners, errs := Listeners(cfg)
+	var got []string
+	for _, l := range listeners {
+		got = append(got, l.Field+" "+strings.Join(l.Protocols, "/")+" "+strings.Join(l.ServerNames, ","))
+	}
+	want := []string{
+		"https[0].listen[0] tcp a.example",
+		"dns[0].listen[0] udp/tcp ",
+		"dns[0].listen[1] tcp ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Listeners() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if len(errs) != 2 || errs[0].Field != "dns[0].listen[2]" || !strings.Contains(errs[0].Err.Error(), `unsupported scheme "quic"`) ||
+		errs[1].Field != "dns[0].listen[3]" || !strings.Contains(errs[1].Err.Error(), "must be between 1 and 65535") {
+		t.Errorf("Listeners() errors = %v", errs)
+	}
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// MergeConflict 合并配置时发现的冲突
+type MergeConflict struct {
+	Section string // 冲突所在的配置段，如 dialer、https
+	Key     string // 冲突的键，如拨号器名称、监听地址
+	Message string // 冲突说明
+}
+
+// String 返回冲突的可读描述
+func (c MergeConflict) String() string {
+	if c.Key == "" {
+		return fmt.Sprintf("%s: %s", c.Section, c.Message)
+	}
+	return fmt.Sprintf("%s[%s]: %s", c.Section, c.Key, c.Message)
+}
+
+// Merge 将多个配置片段依次合并到 base，返回合并后的新配置和冲突列表
+//
+// 合并规则：
+//   - 列表配置段（https、http、socks、redsocks、tunnel、stream、ssh、dns、cron）追加，完全相同的条目只保留一份
+//   - dialer 合并，同名但URL不同视为冲突，保留先出现的URL
+//   - 不同条目的监听地址冲突（与验证相同的规则：同一端口上地址重叠且有共同的协议，
+//     server_name 不相交的 https 条目可以共用地址）；经同一 dialer 的隧道 remote_listen 冲突
+//   - global、sni 为单值配置段，片段中的默认值（生成器输出的默认配置）会被忽略；
+//     两个不同的非默认值视为冲突，保留先出现的值
+//
+// base 和 fragments 不会被修改；base 为 nil 时从空配置开始合并。
+func Merge(base *Config, fragments ...*Config) (*Config, []MergeConflict) {
+	m := &merger{result: &Config{}}
+	if base != nil {
+		m.merge(base)
+	}
+	for _, fragment := range fragments {
+		if fragment != nil {
+			m.merge(fragment)
+		}
+	}
+	return m.result, m.conflicts
+}
+
+// merger 合并过程中的状态
+type merger struct {
+	result    *Config
+	conflicts []MergeConflict
+	listeners []Listener       // 已合并条目的监听地址
+	remotes   []RemoteListener // 已合并隧道的 remote_listen
+}
+
+// merge 合并一个配置
+func (m *merger) merge(c *Config) {
+	m.mergeGlobal(c.Global)
+	m.mergeSni(c.Sni)
+	m.mergeDialer(c.Dialer)
+
+	// 只检查本次新加入的条目
+	added := &Config{Global: m.result.Global}
+	m.result.Cron, _ = appendUnique(m.result.Cron, c.Cron)
+	m.result.Https, added.Https = appendUnique(m.result.Https, c.Https)
+	m.result.Http, added.Http = appendUnique(m.result.Http, c.Http)
+	m.result.Socks, added.Socks = appendUnique(m.result.Socks, c.Socks)
+	m.result.Redsocks, added.Redsocks = appendUnique(m.result.Redsocks, c.Redsocks)
+	m.result.Tunnel, added.Tunnel = appendUnique(m.result.Tunnel, c.Tunnel)
+	m.result.Stream, added.Stream = appendUnique(m.result.Stream, c.Stream)
+	m.result.Ssh, added.Ssh = appendUnique(m.result.Ssh, c.Ssh)
+	m.result.Dns, added.Dns = appendUnique(m.result.Dns, c.Dns)
+	m.checkListens(added)
+}
+
+// mergeGlobal 合并全局配置
+func (m *merger) mergeGlobal(global GlobalConfig) {
+	if isDefaultGlobal(global) {
+		return
+	}
+	if isDefaultGlobal(m.result.Global) {
+		m.result.Global = global
+		return
+	}
+	if m.result.Global != global {
+		m.conflicts = append(m.conflicts, MergeConflict{
+			Section: "global",
+			Message: "fragments define different global settings, keeping the first one",
+		})
+	}
+}
+
+// mergeSni 合并SNI配置
+func (m *merger) mergeSni(sni SniConfig) {
+	if sni == (SniConfig{}) {
+		return
+	}
+	if m.result.Sni == (SniConfig{}) {
+		m.result.Sni = sni
+		return
+	}
+	if m.result.Sni != sni {
+		m.conflicts = append(m.conflicts, MergeConflict{
+			Section: "sni",
+			Message: "fragments define different sni settings, keeping the first one",
+		})
+	}
+}
+
+// mergeDialer 合并拨号器
+func (m *merger) mergeDialer(dialers map[string]string) {
+	names := make([]string, 0, len(dialers))
+	for name := range dialers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		url := dialers[name]
+		if m.result.Dialer == nil {
+			m.result.Dialer = make(map[string]string)
+		}
+		existing, ok := m.result.Dialer[name]
+		if !ok {
+			m.result.Dialer[name] = url
+			continue
+		}
+		if existing != url {
+			m.conflicts = append(m.conflicts, MergeConflict{
+				Section: "dialer",
+				Key:     name,
+				Message: fmt.Sprintf("defined with different URLs %q and %q, keeping the first one", existing, url),
+			})
+		}
+	}
+}
+
+// checkListens 检查新加入条目的监听地址和 remote_listen 是否与已合并的条目冲突
+// 无法解析的地址由验证报告，这里跳过
+func (m *merger) checkListens(added *Config) {
+	listeners, _ := Listeners(added)
+	for _, l := range listeners {
+		for _, owner := range m.listeners {
+			if proto := owner.Conflict(l); proto != "" {
+				m.conflicts = append(m.conflicts, MergeConflict{
+					Section: l.Section,
+					Key:     l.Addr,
+					Message: fmt.Sprintf("listen address conflicts with %s listen %q on %s port %d", owner.Section, owner.Addr, proto, l.Port),
+				})
+				break
+			}
+		}
+		m.listeners = append(m.listeners, l)
+	}
+
+	remotes, _ := RemoteListeners(added)
+	for _, r := range remotes {
+		for _, owner := range m.remotes {
+			if owner.Conflict(r) {
+				m.conflicts = append(m.conflicts, MergeConflict{
+					Section: "tunnel",
+					Key:     r.Addr,
+					Message: fmt.Sprintf("remote_listen conflicts with %q on the server reached through dialer '%s'", owner.Addr, r.Dialer),
+				})
+				break
+			}
+		}
+		m.remotes = append(m.remotes, r)
+	}
+}
+
+// appendUnique 将 items 追加到 dst，跳过与已有条目完全相同的条目，返回追加后的列表和新加入的条目
+func appendUnique[T any](dst []T, items []T) ([]T, []T) {
+	var added []T
+	for _, item := range items {
+		duplicate := false
+		for _, existing := range dst {
+			if reflect.DeepEqual(existing, item) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		dst = append(dst, item)
+		added = append(added, item)
+	}
+	return dst, added
+}
+
+// isDefaultGlobal 判断全局配置是否为空或生成器输出的默认值
+func isDefaultGlobal(global GlobalConfig) bool {
+	return global == (GlobalConfig{}) || global == NewDefaultGlobalConfig()
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	customGlobal := NewDefaultGlobalConfig()
+	customGlobal.LogLevel = "debug"
+	otherGlobal := NewDefaultGlobalConfig()
+	otherGlobal.LogLevel = "error"
+
+	sshConfig := SshConfig{Listen: []string{":2222"}, HostKey: "ssh_host_key"}
+	streamConfig := StreamConfig{Listen: []string{":3389"}, ProxyPass: "10.0.0.1:3389", Dialer: "cloud"}
+
+	tests := []struct {
+		name          string
+		base          *Config
+		fragments     []*Config
+		check         func(t *testing.T, merged *Config)
+		wantConflicts []string
+	}{
+		{
+			name: "combine sections from fragments",
+			base: &Config{Global: customGlobal},
+			fragments: []*Config{
+				{Global: NewDefaultGlobalConfig(), Dialer: map[string]string{"cloud": "socks5://1.2.3.4:1080"}},
+				{Global: NewDefaultGlobalConfig(), Stream: []StreamConfig{streamConfig}},
+				{Global: NewDefaultGlobalConfig(), Ssh: []SshConfig{sshConfig}, Dialer: map[string]string{"local": "local"}},
+			},
+			check: func(t *testing.T, merged *Config) {
+				if merged.Global.LogLevel != "debug" {
+					t.Errorf("global log_level = %q, want base value debug", merged.Global.LogLevel)
+				}
+				if len(merged.Dialer) != 2 {
+					t.Errorf("expected 2 dialers, got %v", merged.Dialer)
+				}
+				if len(merged.Stream) != 1 || len(merged.Ssh) != 1 {
+					t.Errorf("expected 1 stream and 1 ssh, got %d and %d", len(merged.Stream), len(merged.Ssh))
+				}
+			},
+		},
+		{
+			name: "non-default global from fragment replaces default base",
+			base: &Config{Global: NewDefaultGlobalConfig()},
+			fragments: []*Config{
+				{Global: customGlobal},
+			},
+			check: func(t *testing.T, merged *Config) {
+				if merged.Global.LogLevel != "debug" {
+					t.Errorf("global log_level = %q, want debug", merged.Global.LogLevel)
+				}
+			},
+		},
+		{
+			name: "identical entries are de-duplicated",
+			fragments: []*Config{
+				{Ssh: []SshConfig{sshConfig}, Dialer: map[string]string{"cloud": "socks5://1.2.3.4:1080"}},
+				{Ssh: []SshConfig{sshConfig}, Dialer: map[string]string{"cloud": "socks5://1.2.3.4:1080"}},
+			},
+			check: func(t *testing.T, merged *Config) {
+				if len(merged.Ssh) != 1 {
+					t.Errorf("expected duplicate ssh entry to be dropped, got %d", len(merged.Ssh))
+				}
+			},
+		},
+		{
+			name: "https entries may share a listen address",
+			fragments: []*Config{
+				{Https: []HTTPConfig{NewDefaultHTTPConfig([]string{":443"}, []string{"a.example"})}},
+				{Https: []HTTPConfig{NewDefaultHTTPConfig([]string{":443"}, []string{"b.example"})}},
+			},
+			check: func(t *testing.T, merged *Config) {
+				if len(merged.Https) != 2 {
+					t.Errorf("expected 2 https entries, got %d", len(merged.Https))
+				}
+			},
+		},
+		{
+			name: "dialer with different URL",
+			base: &Config{Dialer: map[string]string{"cloud": "socks5://1.2.3.4:1080"}},
+			fragments: []*Config{
+				{Dialer: map[string]string{"cloud": "socks5://9.9.9.9:1080"}},
+			},
+			check: func(t *testing.T, merged *Config) {
+				if merged.Dialer["cloud"] != "socks5://1.2.3.4:1080" {
+					t.Errorf("expected first dialer URL to be kept, got %q", merged.Dialer["cloud"])
+				}
+			},
+			wantConflicts: []string{"dialer[cloud]"},
+		},
+		{
+			name: "duplicate listen address across sections",
+			fragments: []*Config{
+				{Ssh: []SshConfig{sshConfig}},
+				{Stream: []StreamConfig{{Listen: []string{":2222"}, ProxyPass: "127.0.0.1:22"}}},
+				{Http: []HTTPConfig{NewDefaultHTTPConfig([]string{":2222"}, nil)}},
+			},
+			wantConflicts: []string{"stream[:2222]", "http[:2222]"},
+		},
+		{
+			name: "unspecified and empty hosts overlap",
+			fragments: []*Config{
+				{Socks: []SocksConfig{{Listen: []string{":1080"}}}},
+				{Stream: []StreamConfig{{Listen: []string{"0.0.0.0:1080"}, ProxyPass: "10.0.0.1:3389"}}},
+				{Ssh: []SshConfig{{Listen: []string{"127.0.0.1:1080"}}}},
+				{Http: []HTTPConfig{NewDefaultHTTPConfig([]string{"[::1]:1080"}, nil)}},
+			},
+			wantConflicts: []string{`stream[0.0.0.0:1080]: listen address conflicts with socks listen ":1080" on tcp port 1080`, "ssh[127.0.0.1:1080]", "http[[::1]:1080]"},
+		},
+		{
+			name: "tcp and udp listeners on the same port",
+			fragments: []*Config{
+				{Dns: []DnsConfig{{Listen: []string{"udp://:53"}}}},
+				{Socks: []SocksConfig{{Listen: []string{":53"}}}},
+				{Https: []HTTPConfig{NewDefaultHTTPConfig([]string{":443"}, []string{"a.example"})}},
+				{Dns: []DnsConfig{{Listen: []string{"udp://:443"}}}},
+			},
+			wantConflicts: []string{"dns[udp://:443]: listen address conflicts with https listen \":443\" on udp port 443"},
+		},
+		{
+			name: "https entries with a shared server_name",
+			fragments: []*Config{
+				{Https: []HTTPConfig{NewDefaultHTTPConfig([]string{":443"}, []string{"a.example", "b.example"})}},
+				{Https: []HTTPConfig{NewDefaultHTTPConfig([]string{"0.0.0.0:443"}, []string{"B.example"})}},
+			},
+			wantConflicts: []string{"https[0.0.0.0:443]"},
+		},
+		{
+			name: "tunnel remote_listen through the same dialer",
+			fragments: []*Config{
+				{Tunnel: []TunnelConfig{NewDefaultTunnelConfig([]string{"127.0.0.1:10022"}, "127.0.0.1:22", "cloud")}},
+				{Tunnel: []TunnelConfig{NewDefaultTunnelConfig([]string{"127.0.0.1:10022"}, "127.0.0.1:3389", "other")}},
+				{Tunnel: []TunnelConfig{NewDefaultTunnelConfig([]string{":10022"}, "127.0.0.1:8080", "cloud")}},
+			},
+			wantConflicts: []string{`tunnel[:10022]: remote_listen conflicts with "127.0.0.1:10022" on the server reached through dialer 'cloud'`},
+		},
+		{
+			name: "different global settings",
+			fragments: []*Config{
+				{Global: customGlobal},
+				{Global: otherGlobal},
+			},
+			wantConflicts: []string{"global"},
+		},
+		{
+			name: "different sni settings",
+			fragments: []*Config{
+				{Sni: SniConfig{Enabled: true, Forward: SniForwardConfig{Dialer: "local"}}},
+				{Sni: SniConfig{Enabled: true, Forward: SniForwardConfig{Dialer: "cloud"}}},
+			},
+			wantConflicts: []string{"sni"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := Merge(tt.base, tt.fragments...)
+			if tt.check != nil {
+				tt.check(t, merged)
+			}
+
+			if len(conflicts) != len(tt.wantConflicts) {
+				t.Fatalf("expected %d conflicts, got %v", len(tt.wantConflicts), conflicts)
+			}
+			for i, want := range tt.wantConflicts {
+				if !strings.HasPrefix(conflicts[i].String(), want) {
+					t.Errorf("conflict[%d] = %q, want prefix %q", i, conflicts[i].String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeDoesNotModifyInputs(t *testing.T) {
+	base := &Config{
+		Dialer: map[string]string{"cloud": "socks5://1.2.3.4:1080"},
+		Ssh:    []SshConfig{{Listen: []string{":2222"}}},
+	}
+	fragment := &Config{
+		Dialer: map[string]string{"local": "local"},
+		Ssh:    []SshConfig{{Listen: []string{":2223"}}},
+	}
+
+	merged, conflicts := Merge(base, fragment)
+	if len(conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v", conflicts)
+	}
+	if len(merged.Ssh) != 2 || len(merged.Dialer) != 2 {
+		t.Fatalf("unexpected merge result: %+v", merged)
+	}
+	if len(base.Dialer) != 1 || len(base.Ssh) != 1 {
+		t.Errorf("base was modified: %+v", base)
+	}
+}
@@ -0,0 +1,306 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 补丁操作类型
+const (
+	PatchAdd     = "add"
+	PatchReplace = "replace"
+	PatchRemove  = "remove"
+)
+
+// PatchOperation 对配置的一次修改
+//
+// Path 支持两种写法：
+//   - 点号路径，如 https[0].web、dialer.cloud2、tunnel[1].dialer，数组下标 [-] 表示末尾
+//   - JSON Pointer（RFC 6901），如 /https/0/web、/https/0/web/-
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+// Patch 依次对配置应用补丁操作，返回修改后的新配置，cfg 不会被修改
+//
+// 操作语义与 JSON Patch 一致：add 在对象中设置字段、在数组中插入元素，
+// replace 替换已存在的值，remove 删除已存在的值。
+// 为方便追加列表条目，add 的目标为已存在的数组且值不是数组时，将值追加到数组末尾。
+// 任一操作失败时返回错误，错误信息包含操作序号。
+func Patch(cfg *Config, ops []PatchOperation) (*Config, error) {
+	// nil 列表会被编码为 null，先统一为空列表，使 add 能识别列表并追加
+	normalized := *cfg
+	fillNilSlices(reflect.ValueOf(&normalized).Elem())
+
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	for i, op := range ops {
+		tokens, err := parsePatchPath(op.Path)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("operation %d: path must not be empty", i)
+		}
+		// 统一为 JSON 解码后的类型（map[string]any、[]any 等）
+		if op.Value != nil {
+			raw, err := json.Marshal(op.Value)
+			if err != nil {
+				return nil, fmt.Errorf("operation %d: invalid value: %w", i, err)
+			}
+			var value any
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, fmt.Errorf("operation %d: invalid value: %w", i, err)
+			}
+			op.Value = value
+		}
+		doc, err = applyPatch(doc, op, tokens)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode patched config: %w", err)
+	}
+	// 禁止未知字段，拼写错误的路径会在这里被发现
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var patched Config
+	if err := dec.Decode(&patched); err != nil {
+		return nil, fmt.Errorf("patched config does not match liner config structure: %w", err)
+	}
+	return &patched, nil
+}
+
+// Lookup 返回配置中路径对应的值（JSON 解码后的类型），路径写法与 Patch 相同
+func Lookup(cfg *Config, path string) (any, error) {
+	tokens, err := parsePatchPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	var node any
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	for i, token := range tokens {
+		switch n := node.(type) {
+		case map[string]any:
+			child, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q: field %q does not exist", path, token)
+			}
+			if child == nil && i < len(tokens)-1 {
+				return nil, fmt.Errorf("path %q: %q is not set", path, token)
+			}
+			node = child
+		case []any:
+			index, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, fmt.Errorf("path %q: %w", path, err)
+			}
+			node = n[index]
+		default:
+			return nil, fmt.Errorf("path %q: cannot descend into %q", path, token)
+		}
+	}
+	return node, nil
+}
+
+// applyPatch 在 node 上按 tokens 应用一次操作，返回修改后的 node
+func applyPatch(node any, op PatchOperation, tokens []string) (any, error) {
+	token := tokens[0]
+	last := len(tokens) == 1
+
+	switch n := node.(type) {
+	case map[string]any:
+		child, exists := n[token]
+		if !last {
+			if !exists || child == nil {
+				if op.Op != PatchAdd {
+					return nil, fmt.Errorf("path segment %q does not exist", token)
+				}
+				child = newContainer(tokens[1])
+			}
+			updated, err := applyPatch(child, op, tokens[1:])
+			if err != nil {
+				return nil, err
+			}
+			n[token] = updated
+			return n, nil
+		}
+
+		switch op.Op {
+		case PatchAdd:
+			// 目标为已存在的数组且值不是数组时追加
+			if arr, ok := child.([]any); ok {
+				if _, isArray := op.Value.([]any); !isArray {
+					n[token] = append(arr, op.Value)
+					return n, nil
+				}
+			}
+			n[token] = op.Value
+		case PatchReplace:
+			if !exists {
+				return nil, fmt.Errorf("field %q does not exist", token)
+			}
+			n[token] = op.Value
+		case PatchRemove:
+			if !exists {
+				return nil, fmt.Errorf("field %q does not exist", token)
+			}
+			delete(n, token)
+		default:
+			return nil, fmt.Errorf("unknown op %q, must be one of: add, replace, remove", op.Op)
+		}
+		return n, nil
+
+	case []any:
+		index, err := arrayIndex(token, len(n), last && op.Op == PatchAdd)
+		if err != nil {
+			return nil, err
+		}
+		if !last {
+			if index == len(n) {
+				return nil, fmt.Errorf("index %q is out of range", token)
+			}
+			updated, err := applyPatch(n[index], op, tokens[1:])
+			if err != nil {
+				return nil, err
+			}
+			n[index] = updated
+			return n, nil
+		}
+
+		switch op.Op {
+		case PatchAdd:
+			n = append(n, nil)
+			copy(n[index+1:], n[index:])
+			n[index] = op.Value
+		case PatchReplace:
+			n[index] = op.Value
+		case PatchRemove:
+			n = append(n[:index], n[index+1:]...)
+		default:
+			return nil, fmt.Errorf("unknown op %q, must be one of: add, replace, remove", op.Op)
+		}
+		return n, nil
+
+	case nil:
+		// 空列表（如未配置的 https）在 add 时自动创建
+		if op.Op != PatchAdd {
+			return nil, fmt.Errorf("path segment %q does not exist", token)
+		}
+		return applyPatch(newContainer(token), op, tokens)
+
+	default:
+		return nil, fmt.Errorf("cannot descend into %q: parent is not an object or array", token)
+	}
+}
+
+// fillNilSlices 将结构体中的 nil 切片替换为空切片，已有切片会被复制，不修改原数据
+func fillNilSlices(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			fillNilSlices(v.Field(i))
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+			return
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		for i := 0; i < copied.Len(); i++ {
+			fillNilSlices(copied.Index(i))
+		}
+		v.Set(copied)
+	}
+}
+
+// newContainer 根据下一个路径片段创建空的对象或数组
+func newContainer(token string) any {
+	if token == "-" {
+		return []any{}
+	}
+	if _, err := strconv.Atoi(token); err == nil {
+		return []any{}
+	}
+	return map[string]any{}
+}
+
+// arrayIndex 解析数组下标，allowEnd 为 true 时允许下标等于数组长度（追加）
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" {
+		if !allowEnd {
+			return 0, fmt.Errorf("index \"-\" can only be used with add")
+		}
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if index < 0 || index > length || (index == length && !allowEnd) {
+		return 0, fmt.Errorf("index %d is out of range (length %d)", index, length)
+	}
+	return index, nil
+}
+
+// parsePatchPath 将点号路径或 JSON Pointer 解析为路径片段
+func parsePatchPath(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(path, "/") {
+		parts := strings.Split(path[1:], "/")
+		for i, part := range parts {
+			parts[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		}
+		return parts, nil
+	}
+
+	var tokens []string
+	for _, segment := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(segment, "[")
+		if name == "" && rest == "" {
+			return nil, fmt.Errorf("invalid path %q: empty segment", path)
+		}
+		if name != "" {
+			tokens = append(tokens, name)
+		}
+		if rest == "" {
+			continue
+		}
+		// 解析连续的 [n] 下标
+		for _, index := range strings.Split("["+rest, "[")[1:] {
+			idx, ok := strings.CutSuffix(index, "]")
+			if !ok || idx == "" {
+				return nil, fmt.Errorf("invalid path %q: malformed index", path)
+			}
+			tokens = append(tokens, idx)
+		}
+	}
+	return tokens, nil
+}
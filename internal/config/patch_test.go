@@ -0,0 +1,209 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func newPatchTestConfig() *Config {
+	return &Config{
+		Global: NewDefaultGlobalConfig(),
+		Dialer: map[string]string{"cloud": "socks5://1.2.3.4:1080"},
+		Https:  []HTTPConfig{NewDefaultHTTPConfig([]string{":443"}, []string{"a.example"})},
+		Tunnel: []TunnelConfig{
+			NewDefaultTunnelConfig([]string{"127.0.0.1:10022"}, "127.0.0.1:22", "cloud"),
+			NewDefaultTunnelConfig([]string{"127.0.0.1:10080"}, "127.0.0.1:80", "cloud"),
+		},
+	}
+}
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ops     []PatchOperation
+		check   func(t *testing.T, cfg *Config)
+		wantErr string
+	}{
+		{
+			name: "replace field with dotted path",
+			ops:  []PatchOperation{{Op: PatchReplace, Path: "tunnel[1].dialer", Value: "cloud2"}},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Tunnel[1].Dialer != "cloud2" || cfg.Tunnel[0].Dialer != "cloud" {
+					t.Errorf("unexpected tunnel dialers: %q, %q", cfg.Tunnel[0].Dialer, cfg.Tunnel[1].Dialer)
+				}
+			},
+		},
+		{
+			name: "replace field with JSON pointer",
+			ops:  []PatchOperation{{Op: PatchReplace, Path: "/tunnel/0/proxy_pass", Value: "127.0.0.1:2222"}},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Tunnel[0].ProxyPass != "127.0.0.1:2222" {
+					t.Errorf("proxy_pass = %q", cfg.Tunnel[0].ProxyPass)
+				}
+			},
+		},
+		{
+			name: "append web location to existing list",
+			ops: []PatchOperation{{Op: PatchAdd, Path: "https[0].web", Value: HTTPWebConfig{
+				Location: "/dns-query",
+				Doh:      HTTPWebDohConfig{Enabled: true, ProxyPass: "https://1.1.1.1/dns-query"},
+			}}},
+			check: func(t *testing.T, cfg *Config) {
+				if len(cfg.Https[0].Web) != 1 || !cfg.Https[0].Web[0].Doh.Enabled {
+					t.Errorf("expected doh web location, got %+v", cfg.Https[0].Web)
+				}
+			},
+		},
+		{
+			name: "append with dash index",
+			ops: []PatchOperation{
+				{Op: PatchAdd, Path: "https[0].web[-]", Value: map[string]any{"location": "/a"}},
+				{Op: PatchAdd, Path: "/https/0/web/-", Value: map[string]any{"location": "/b"}},
+				{Op: PatchAdd, Path: "https[0].web[0]", Value: map[string]any{"location": "/first"}},
+			},
+			check: func(t *testing.T, cfg *Config) {
+				var locations []string
+				for _, w := range cfg.Https[0].Web {
+					locations = append(locations, w.Location)
+				}
+				if got := strings.Join(locations, ","); got != "/first,/a,/b" {
+					t.Errorf("locations = %s", got)
+				}
+			},
+		},
+		{
+			name: "add creates missing list and map",
+			ops: []PatchOperation{
+				{Op: PatchAdd, Path: "stream[-]", Value: map[string]any{"listen": []string{":3389"}, "proxy_pass": "10.0.0.1:3389"}},
+				{Op: PatchAdd, Path: "dialer.cloud2", Value: "socks5://5.6.7.8:1080"},
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if len(cfg.Stream) != 1 || cfg.Stream[0].ProxyPass != "10.0.0.1:3389" {
+					t.Errorf("unexpected stream: %+v", cfg.Stream)
+				}
+				if cfg.Dialer["cloud2"] != "socks5://5.6.7.8:1080" || cfg.Dialer["cloud"] == "" {
+					t.Errorf("unexpected dialers: %v", cfg.Dialer)
+				}
+			},
+		},
+		{
+			name: "replace whole list with array value",
+			ops:  []PatchOperation{{Op: PatchAdd, Path: "https[0].server_name", Value: []string{"b.example"}}},
+			check: func(t *testing.T, cfg *Config) {
+				if len(cfg.Https[0].ServerName) != 1 || cfg.Https[0].ServerName[0] != "b.example" {
+					t.Errorf("server_name = %v", cfg.Https[0].ServerName)
+				}
+			},
+		},
+		{
+			name: "remove list entry and map key",
+			ops: []PatchOperation{
+				{Op: PatchRemove, Path: "tunnel[0]"},
+				{Op: PatchRemove, Path: "dialer.cloud"},
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if len(cfg.Tunnel) != 1 || cfg.Tunnel[0].ProxyPass != "127.0.0.1:80" {
+					t.Errorf("unexpected tunnels: %+v", cfg.Tunnel)
+				}
+				if _, ok := cfg.Dialer["cloud"]; ok {
+					t.Errorf("dialer cloud was not removed")
+				}
+			},
+		},
+		{
+			name:    "replace missing field",
+			ops:     []PatchOperation{{Op: PatchReplace, Path: "dialer.missing", Value: "local"}},
+			wantErr: `operation 0 (replace dialer.missing): field "missing" does not exist`,
+		},
+		{
+			name:    "index out of range",
+			ops:     []PatchOperation{{Op: PatchReplace, Path: "tunnel[5].dialer", Value: "cloud2"}},
+			wantErr: "out of range",
+		},
+		{
+			name:    "unknown field",
+			ops:     []PatchOperation{{Op: PatchAdd, Path: "https[0].webb", Value: []any{}}},
+			wantErr: `unknown field "webb"`,
+		},
+		{
+			name:    "value of wrong type",
+			ops:     []PatchOperation{{Op: PatchReplace, Path: "tunnel[0].dial_timeout", Value: "soon"}},
+			wantErr: "does not match liner config structure",
+		},
+		{
+			name:    "unknown op",
+			ops:     []PatchOperation{{Op: "move", Path: "tunnel[0].dialer"}},
+			wantErr: `unknown op "move"`,
+		},
+		{
+			name:    "malformed path",
+			ops:     []PatchOperation{{Op: PatchRemove, Path: "tunnel[0"}},
+			wantErr: "malformed index",
+		},
+		{
+			name:    "second operation fails",
+			ops:     []PatchOperation{{Op: PatchRemove, Path: "tunnel[1]"}, {Op: PatchRemove, Path: "tunnel[1]"}},
+			wantErr: "operation 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newPatchTestConfig()
+			patched, err := Patch(cfg, tt.ops)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Patch() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Patch() unexpected error: %v", err)
+			}
+			tt.check(t, patched)
+
+			// 原配置不应被修改
+			if len(cfg.Tunnel) != 2 || cfg.Tunnel[1].Dialer != "cloud" || len(cfg.Dialer) != 1 {
+				t.Errorf("input config was modified: %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	cfg := &Config{
+		Dialer: map[string]string{"cloud": "socks5://1.2.3.4:1080"},
+		Https: []HTTPConfig{{
+			Listen:  []string{":443"},
+			Forward: HTTPForwardConfig{Policy: "proxy_pass"},
+		}},
+	}
+
+	tests := []struct {
+		path    string
+		want    any
+		wantErr string
+	}{
+		{path: "https[0].forward.policy", want: "proxy_pass"},
+		{path: "/https/0/listen/0", want: ":443"},
+		{path: "dialer.cloud", want: "socks5://1.2.3.4:1080"},
+		{path: "https[1].forward.policy", wantErr: "out of range"},
+		{path: "https[0].forward.polcy", wantErr: `field "polcy" does not exist`},
+		{path: "https[0].forward.policy.x", wantErr: "cannot descend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := Lookup(cfg, tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Lookup() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Lookup() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
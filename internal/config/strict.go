@@ -0,0 +1,144 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnknownKey 严格解码时发现的未知键
+type UnknownKey struct {
+	Line       int    // 键所在的行
+	Key        string // 键名
+	Type       string // 键所在的配置结构体，如 HTTPForwardConfig
+	Suggestion string // 编辑距离最近的已知键，没有相近的键时为空
+}
+
+// unknownFieldPattern 匹配 yaml.v3 在 KnownFields 模式下报告的未知字段
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (.*) not found in type (\S+)$`)
+
+// FromYAMLStrict 使用 KnownFields 严格解析配置，未知键不会被静默丢弃，而是逐个返回
+// 其余键照常解码；存在其他解码错误（如类型不符）时返回的 error 只包含这些错误
+func FromYAMLStrict(yamlStr string) (*Config, []UnknownKey, error) {
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader([]byte(yamlStr)))
+	dec.KnownFields(true)
+	err := dec.Decode(&cfg)
+	if errors.Is(err, io.EOF) {
+		return &cfg, nil, nil
+	}
+
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		if err != nil {
+			return nil, nil, err
+		}
+		return &cfg, nil, nil
+	}
+
+	types := configTypes()
+	var unknown []UnknownKey
+	var remaining []string
+	for _, msg := range typeErr.Errors {
+		m := unknownFieldPattern.FindStringSubmatch(msg)
+		if m == nil {
+			remaining = append(remaining, msg)
+			continue
+		}
+		line, _ := strconv.Atoi(m[1])
+		key := UnknownKey{Line: line, Key: m[2], Type: m[3]}
+		if t, ok := types[m[3]]; ok {
+			key.Type = t.Name()
+			key.Suggestion = SuggestKey(t, key.Key)
+		}
+		unknown = append(unknown, key)
+	}
+	if len(remaining) > 0 {
+		return &cfg, unknown, &yaml.TypeError{Errors: remaining}
+	}
+	return &cfg, unknown, nil
+}
+
+// SuggestKey 返回结构体 t 的 yaml 键中与 key 编辑距离最近的一个，距离过大时返回空串
+func SuggestKey(t reflect.Type, key string) string {
+	best, bestDist := "", 0
+	for _, name := range yamlKeys(t) {
+		d := editDistance(strings.ToLower(key), name)
+		if best == "" || d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+	// 允许约三分之一的字符不同，至少容忍两处拼写错误
+	if best == "" || bestDist > max(2, len(key)/3) {
+		return ""
+	}
+	return best
+}
+
+// yamlKeys 返回结构体所有字段的 yaml 键名
+func yamlKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		if name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// configTypes 返回 Config 中出现的所有结构体类型，以 yaml.v3 错误信息中的类型名索引
+func configTypes() map[string]reflect.Type {
+	types := map[string]reflect.Type{}
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			walk(t.Elem())
+		case reflect.Struct:
+			if _, ok := types[t.String()]; ok {
+				return
+			}
+			types[t.String()] = t
+			for i := 0; i < t.NumField(); i++ {
+				walk(t.Field(i).Type)
+			}
+		}
+	}
+	walk(reflect.TypeOf(Config{}))
+	return types
+}
+
+// editDistance 计算两个字符串的 Levenshtein 距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFromYAMLStrict(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantUnknown []UnknownKey
+		wantErr     string
+	}{
+		{name: "empty", content: ""},
+		{name: "known keys", content: "global:\n  log_level: info\nhttp:\n  - listen: [':80']\n"},
+		{
+			name:    "misspelled keys",
+			content: "http:\n  - listen: [':80']\n    forword:\n      policy: proxy_pass\n    server_names: [a.example]\nglobl:\n  log_level: info\n",
+			wantUnknown: []UnknownKey{
+				{Line: 3, Key: "forword", Type: "HTTPConfig", Suggestion: "forward"},
+				{Line: 5, Key: "server_names", Type: "HTTPConfig", Suggestion: "server_name"},
+				{Line: 6, Key: "globl", Type: "Config", Suggestion: "global"},
+			},
+		},
+		{
+			name:        "no close match",
+			content:     "global:\n  completely_different: 1\n",
+			wantUnknown: []UnknownKey{{Line: 2, Key: "completely_different", Type: "GlobalConfig"}},
+		},
+		{
+			name:        "type errors are still returned",
+			content:     "global:\n  log_backups: two\n  log_levle: info\n",
+			wantUnknown: []UnknownKey{{Line: 3, Key: "log_levle", Type: "GlobalConfig", Suggestion: "log_level"}},
+			wantErr:     "cannot unmarshal !!str `two` into int",
+		},
+		{name: "syntax error", content: "global: [", wantErr: "did not find expected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, unknown, err := FromYAMLStrict(tt.content)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if strings.Contains(err.Error(), "not found in type") {
+					t.Errorf("unknown fields should not be part of the error: %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(unknown, tt.wantUnknown) {
+				t.Errorf("unknown = %+v, want %+v", unknown, tt.wantUnknown)
+			}
+			if err == nil && cfg == nil {
+				t.Error("config should be returned")
+			}
+		})
+	}
+
+	// 未知键不影响其他键的解码
+	cfg, _, err := FromYAMLStrict("http:\n  - listen: [':80']\n    forword: {}\n")
+	if err != nil || len(cfg.Http) != 1 || cfg.Http[0].Listen[0] != ":80" {
+		t.Errorf("known keys not decoded: cfg = %+v, err = %v", cfg, err)
+	}
+}
+
+func TestSuggestKey(t *testing.T) {
+	tests := []struct {
+		typ  reflect.Type
+		key  string
+		want string
+	}{
+		{reflect.TypeOf(HTTPForwardConfig{}), "polcy", "policy"},
+		{reflect.TypeOf(HTTPForwardConfig{}), "AuthTable", "auth_table"},
+		{reflect.TypeOf(HTTPForwardConfig{}), "auth-table", "auth_table"},
+		{reflect.TypeOf(GlobalConfig{}), "dns_sever", "dns_server"},
+		{reflect.TypeOf(GlobalConfig{}), "x", ""},
+	}
+	for _, tt := range tests {
+		if got := SuggestKey(tt.typ, tt.key); got != tt.want {
+			t.Errorf("SuggestKey(%s, %q) = %q, want %q", tt.typ.Name(), tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+// Package dialer 解析和校验 liner 的拨号器URL
+package dialer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schemes liner 支持的拨号器类型
+var Schemes = []string{"local", "socks5", "http", "https", "http2", "http3", "ssh", "wss"}
+
+// namePattern 拨号器名称，policy 模板以文本形式输出名称，只允许字母、数字、下划线和连字符
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// optionKind 查询参数的取值类型
+type optionKind int
+
+const (
+	kindBool optionKind = iota
+	kindInt
+	kindString
+	kindIP
+)
+
+// options 所有查询参数及其取值类型
+var options = map[string]optionKind{
+	"insecure":    kindBool,   // 跳过TLS证书或SSH主机密钥校验
+	"ech":         kindBool,   // 启用TLS Encrypted Client Hello
+	"max_clients": kindInt,    // 连接池中的最大连接数
+	"resolve":     kindIP,     // 直接连接该IP，不解析主机名
+	"key":         kindString, // SSH私钥文件路径
+}
+
+// schemeSpec 拨号器类型的规则
+type schemeSpec struct {
+	defaultPort int
+	// userRequired 为 true 时必须提供用户名
+	userRequired bool
+	// pathAllowed 为 true 时允许URL带路径
+	pathAllowed bool
+	options     []string
+}
+
+// specs 各拨号器类型的规则，local 单独处理
+var specs = map[string]schemeSpec{
+	"socks5": {defaultPort: 1080, options: []string{"resolve"}},
+	"http":   {defaultPort: 80, options: []string{"resolve"}},
+	"https":  {defaultPort: 443, options: []string{"insecure", "ech", "resolve"}},
+	"http2":  {defaultPort: 443, options: []string{"insecure", "ech", "max_clients", "resolve"}},
+	"http3":  {defaultPort: 443, options: []string{"insecure", "ech", "resolve"}},
+	"ssh":    {defaultPort: 22, userRequired: true, options: []string{"key", "insecure", "max_clients", "resolve"}},
+	"wss":    {defaultPort: 443, pathAllowed: true, options: []string{"insecure", "ech", "resolve"}},
+}
+
+// Error 拨号器URL错误，Option 为出错的查询参数名，与参数无关时为空
+type Error struct {
+	Option  string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Option != "" {
+		return fmt.Sprintf("option %q: %s", e.Option, e.Message)
+	}
+	return e.Message
+}
+
+// URL 解析后的拨号器URL
+type URL struct {
+	Scheme   string
+	Username string
+	Password string
+	// Host 主机名或IP，local 类型为网卡名（可为空）
+	Host string
+	// Port 未指定时为该类型的默认端口，local 类型为0
+	Port    int
+	Path    string
+	Options map[string]string
+}
+
+// Options 返回拨号器类型支持的查询参数，类型不存在时返回 nil
+func Options(scheme string) []string {
+	spec, ok := specs[scheme]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), spec.options...)
+}
+
+// Parse 解析并校验拨号器URL
+// 裸的 "local" 等同于 "local://"
+func Parse(raw string) (*URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, &Error{Message: "dialer URL cannot be empty"}
+	}
+	if raw == "local" {
+		return &URL{Scheme: "local", Options: map[string]string{}}, nil
+	}
+
+	scheme, _, ok := strings.Cut(raw, "://")
+	if !ok {
+		return nil, &Error{Message: fmt.Sprintf("%q is not a URL, expected scheme://address with scheme one of: %s", raw, strings.Join(Schemes, ", "))}
+	}
+	if scheme != "local" {
+		if _, ok := specs[scheme]; !ok {
+			return nil, &Error{Message: fmt.Sprintf("unsupported scheme %q, must be one of: %s", scheme, strings.Join(Schemes, ", "))}
+		}
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, &Error{Message: strings.TrimPrefix(unwrapURLError(err), "net/url: ")}
+	}
+
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, &Error{Message: fmt.Sprintf("invalid query string: %v", err)}
+	}
+
+	result := &URL{
+		Scheme:  u.Scheme,
+		Host:    u.Hostname(),
+		Path:    u.Path,
+		Options: make(map[string]string, len(query)),
+	}
+	for name, values := range query {
+		result.Options[name] = values[len(values)-1]
+	}
+
+	if u.Scheme == "local" {
+		if err := validateLocal(u, query); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	spec := specs[u.Scheme]
+
+	// 地址
+	if result.Host == "" {
+		return nil, &Error{Message: fmt.Sprintf("%s dialer requires a host", u.Scheme)}
+	}
+	if err := validateHost(result.Host); err != nil {
+		return nil, err
+	}
+	result.Port = spec.defaultPort
+	if p := u.Port(); p != "" {
+		port, err := strconv.Atoi(p)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, &Error{Message: fmt.Sprintf("invalid port %q, must be between 1 and 65535", p)}
+		}
+		result.Port = port
+	}
+	if !spec.pathAllowed && u.Path != "" && u.Path != "/" {
+		return nil, &Error{Message: fmt.Sprintf("%s dialer does not support a path (%q)", u.Scheme, u.Path)}
+	}
+
+	// 认证信息
+	if u.User != nil {
+		result.Username = u.User.Username()
+		result.Password, _ = u.User.Password()
+		if result.Username == "" {
+			return nil, &Error{Message: "username cannot be empty when credentials are given"}
+		}
+	}
+	if spec.userRequired && result.Username == "" {
+		return nil, &Error{Message: fmt.Sprintf("%s dialer requires a username, e.g. %s://user@host", u.Scheme, u.Scheme)}
+	}
+
+	// 查询参数
+	for _, name := range sortedKeys(query) {
+		if !slices.Contains(spec.options, name) {
+			return nil, &Error{Option: name, Message: fmt.Sprintf("not supported by %s dialer, supported options: %s", u.Scheme, strings.Join(spec.options, ", "))}
+		}
+		if err := validateOption(name, query[name]); err != nil {
+			return nil, err
+		}
+	}
+	if u.Scheme == "ssh" && result.Password == "" && result.Options["key"] == "" {
+		return nil, &Error{Message: "ssh dialer requires a password or the key option, e.g. ssh://user@host?key=/path/to/id_ed25519"}
+	}
+
+	return result, nil
+}
+
+// ValidateName 校验拨号器名称
+func ValidateName(name string) error {
+	if name == "" {
+		return &Error{Message: "dialer name cannot be empty"}
+	}
+	if !namePattern.MatchString(name) {
+		return &Error{Message: fmt.Sprintf("invalid dialer name %q, use only letters, digits, '_' and '-'", name)}
+	}
+	return nil
+}
+
+// FromPolicyOutput 从 policy 模板的输出中取出拨号器名称或特殊动作，格式与 liner 一致
+// 支持纯名称、JSON（{"dialer":"x"}）和查询串（dialer=x&disable_ipv6=true）三种格式，
+// JSON 或查询串无法解析时返回空字符串
+func FromPolicyOutput(output string) string {
+	output = strings.TrimSpace(output)
+	if strings.HasPrefix(output, "{") {
+		var v struct {
+			Dialer string `json:"dialer"`
+		}
+		if json.Unmarshal([]byte(output), &v) != nil {
+			return ""
+		}
+		return v.Dialer
+	}
+	if strings.Contains(output, "=") {
+		values, err := url.ParseQuery(output)
+		if err != nil {
+			return ""
+		}
+		return values.Get("dialer")
+	}
+	return output
+}
+
+// Build 由类型、地址和查询参数构建拨号器URL，并校验结果
+// address 为 user:pass@host:port 形式（wss 可带路径），local 类型为网卡名；
+// 用户名和密码按原样给出，其中的 @、:、/ 等字符由 Build 转义
+func Build(scheme, address string, opts map[string]string) (string, error) {
+	u := url.URL{Scheme: scheme, Host: address}
+	// 密码中可能含有 @，以最后一个 @ 分隔认证信息
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		if username, password, ok := strings.Cut(address[:i], ":"); ok {
+			u.User = url.UserPassword(username, password)
+		} else {
+			u.User = url.User(username)
+		}
+		u.Host = address[i+1:]
+	}
+	if host, path, ok := strings.Cut(u.Host, "/"); ok {
+		u.Host, u.Path = host, "/"+path
+	}
+	if len(opts) > 0 {
+		query := url.Values{}
+		for name, value := range opts {
+			query.Set(name, value)
+		}
+		// Encode 按参数名排序，结果稳定
+		u.RawQuery = query.Encode()
+	}
+
+	raw := u.String()
+	if u.Host == "" && u.User == nil && u.Path == "" {
+		// url.URL 省略空主机前的 //，liner 的写法为 local://
+		raw = scheme + "://" + strings.TrimPrefix(raw, scheme+":")
+	}
+	if _, err := Parse(raw); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// redacted 替换密码和密钥参数的占位符，与 url.URL.Redacted 一致
+const redacted = "xxxxx"
+
+// Redact 返回隐藏了密码和密钥参数的拨号器URL，用于日志和差异输出
+// 支持的参数之外的查询参数（如 psk）都可能是密钥，一律隐藏；无法解析的URL整体隐藏
+func Redact(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return redacted
+	}
+	if u.RawQuery != "" {
+		query, err := url.ParseQuery(u.RawQuery)
+		if err != nil {
+			return redacted
+		}
+		for name := range query {
+			if _, ok := options[name]; !ok {
+				query[name] = []string{redacted}
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+	return u.Redacted()
+}
+
+// validateLocal 校验 local://iface 形式的URL
+func validateLocal(u *url.URL, query url.Values) error {
+	if u.User != nil {
+		return &Error{Message: "local dialer does not accept credentials"}
+	}
+	if u.Port() != "" {
+		return &Error{Message: "local dialer does not accept a port, use local://<interface>"}
+	}
+	if u.Path != "" && u.Path != "/" {
+		return &Error{Message: fmt.Sprintf("local dialer does not support a path (%q)", u.Path)}
+	}
+	if len(query) > 0 {
+		return &Error{Option: sortedKeys(query)[0], Message: "local dialer does not accept options"}
+	}
+	return nil
+}
+
+// validateHost 校验主机名或IP
+func validateHost(host string) error {
+	if _, err := netip.ParseAddr(host); err == nil {
+		return nil
+	}
+	if len(host) > 253 {
+		return &Error{Message: fmt.Sprintf("host %q is too long", host)}
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return &Error{Message: fmt.Sprintf("invalid host %q", host)}
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return &Error{Message: fmt.Sprintf("invalid host %q", host)}
+			}
+		}
+	}
+	return nil
+}
+
+// validateOption 按参数类型校验取值
+func validateOption(name string, values []string) error {
+	if len(values) > 1 {
+		return &Error{Option: name, Message: "specified more than once"}
+	}
+	value := values[0]
+	switch options[name] {
+	case kindBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return &Error{Option: name, Message: fmt.Sprintf("invalid boolean %q, use true/false or 1/0", value)}
+		}
+	case kindInt:
+		if n, err := strconv.Atoi(value); err != nil || n < 1 {
+			return &Error{Option: name, Message: fmt.Sprintf("invalid value %q, must be a positive integer", value)}
+		}
+	case kindIP:
+		if _, err := netip.ParseAddr(value); err != nil {
+			return &Error{Option: name, Message: fmt.Sprintf("invalid IP address %q", value)}
+		}
+	case kindString:
+		if value == "" {
+			return &Error{Option: name, Message: "value cannot be empty"}
+		}
+	}
+	return nil
+}
+
+// unwrapURLError 返回 url.Error 中的原因，避免在错误信息中重复完整URL（可能包含密码）
+func unwrapURLError(err error) string {
+	if urlErr, ok := err.(*url.Error); ok {
+		return urlErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// sortedKeys 返回排序后的参数名
+func sortedKeys(query url.Values) []string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,329 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bensonfx/mcp-liner/internal/validation"
+)
+
+// GeoIP geoip 函数的返回值，字段与 liner 一致
+type GeoIP struct {
+	Country        string
+	City           string
+	ISP            string
+	ConnectionType string
+}
+
+// IPRange ipRange 函数的返回值
+type IPRange struct {
+	prefix netip.Prefix
+}
+
+// Contains 判断主机或IP是否在网段内
+func (r IPRange) Contains(host string) bool {
+	addr, err := netip.ParseAddr(stripPort(host))
+	return err == nil && r.prefix.Contains(addr.Unmap())
+}
+
+// funcMap 返回模拟器使用的模板函数表
+// liner 的函数使用离线实现，常用的 sprig 函数按 sprig 的参数顺序实现，
+// 其余函数在执行时返回错误，提示模拟器不支持
+func (s *Simulator) funcMap() template.FuncMap {
+	funcs := template.FuncMap{}
+	for name := range validation.PolicyFuncMap() {
+		name := name
+		funcs[name] = func(...any) (any, error) {
+			return nil, fmt.Errorf("function %q is not supported by the simulator", name)
+		}
+	}
+
+	// liner 网络函数
+	funcs["country"] = func(ip any) string { return s.Country(s.resolve(toString(ip))) }
+	funcs["geoip"] = func(ip any) GeoIP { return GeoIP{Country: s.Country(s.resolve(toString(ip)))} }
+	funcs["geosite"] = func(host any) string { return s.Geosite(stripPort(toString(host))) }
+	funcs["dnsResolve"] = func(host any) string { return s.resolve(toString(host)) }
+	funcs["host"] = func(hostport any) string { return stripPort(toString(hostport)) }
+	funcs["domain"] = func(host any) string { return rootDomain(stripPort(toString(host))) }
+	funcs["hasIPv6"] = func(host any) bool {
+		addr, err := netip.ParseAddr(s.resolve(toString(host)))
+		return err == nil && addr.Is6() && !addr.Is4In6()
+	}
+	funcs["ipInt"] = func(ip any) (uint32, error) {
+		addr, err := netip.ParseAddr(s.resolve(toString(ip)))
+		if err != nil || !addr.Unmap().Is4() {
+			return 0, fmt.Errorf("ipInt: %q is not an IPv4 address", toString(ip))
+		}
+		b := addr.Unmap().As4()
+		return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3]), nil
+	}
+	funcs["ipRange"] = func(cidr string) (IPRange, error) {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return IPRange{}, fmt.Errorf("ipRange: %w", err)
+		}
+		return IPRange{prefix: prefix.Masked()}, nil
+	}
+	funcs["isInNet"] = func(host any, cidr string) (bool, error) {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return false, fmt.Errorf("isInNet: %w", err)
+		}
+		addr, err := netip.ParseAddr(s.resolve(toString(host)))
+		return err == nil && prefix.Contains(addr.Unmap()), nil
+	}
+
+	// liner 字符串匹配函数，pattern 以 | 分隔
+	funcs["hasPrefixes"] = func(pattern string, str any) bool {
+		return matchAny(pattern, func(p string) bool { return strings.HasPrefix(toString(str), p) })
+	}
+	funcs["hasSuffixes"] = func(pattern string, str any) bool {
+		return matchAny(pattern, func(p string) bool { return strings.HasSuffix(toString(str), p) })
+	}
+	funcs["wildcardMatch"] = func(pattern string, str any) bool {
+		return matchAny(pattern, func(p string) bool {
+			ok, _ := filepath.Match(p, toString(str))
+			return ok
+		})
+	}
+	funcs["regexMatch"] = func(pattern string, str any) (bool, error) {
+		return regexp.MatchString(pattern, toString(str))
+	}
+
+	// liner 文件与HTTP函数，数据来自 fixtures
+	funcs["readFile"] = func(name string) (string, error) {
+		content, ok := s.Files[name]
+		if !ok {
+			return "", fmt.Errorf("readFile: no fixture for file %q", name)
+		}
+		return content, nil
+	}
+	funcs["inFileLine"] = func(name string, line any) (bool, error) {
+		content, ok := s.Files[name]
+		if !ok {
+			return false, fmt.Errorf("inFileLine: no fixture for file %q", name)
+		}
+		for _, l := range strings.Split(content, "\n") {
+			if strings.TrimSpace(l) == toString(line) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	funcs["inFileIPSet"] = func(name string, ip any) (bool, error) {
+		content, ok := s.Files[name]
+		if !ok {
+			return false, fmt.Errorf("inFileIPSet: no fixture for file %q", name)
+		}
+		addr, err := netip.ParseAddr(s.resolve(toString(ip)))
+		if err != nil {
+			return false, nil
+		}
+		return inIPSet(content, addr.Unmap()), nil
+	}
+	funcs["fetch"] = func(ua string, timeout, ttl int, url string) FetchResponse {
+		return s.Fetch(url)
+	}
+	funcs["greased"] = func(any) bool { return false }
+
+	// liner 字典函数
+	funcs["get"] = func(d map[string]any, key string) any { return d[key] }
+	funcs["set"] = func(d map[string]any, key string, value any) map[string]any { d[key] = value; return d }
+	funcs["unset"] = func(d map[string]any, key string) map[string]any { delete(d, key); return d }
+	funcs["hasKey"] = func(d map[string]any, key string) bool { _, ok := d[key]; return ok }
+
+	// 常用 sprig 函数
+	funcs["contains"] = func(substr string, str any) bool { return strings.Contains(toString(str), substr) }
+	funcs["hasPrefix"] = func(prefix string, str any) bool { return strings.HasPrefix(toString(str), prefix) }
+	funcs["hasSuffix"] = func(suffix string, str any) bool { return strings.HasSuffix(toString(str), suffix) }
+	funcs["lower"] = func(str any) string { return strings.ToLower(toString(str)) }
+	funcs["upper"] = func(str any) string { return strings.ToUpper(toString(str)) }
+	funcs["trim"] = func(str any) string { return strings.TrimSpace(toString(str)) }
+	funcs["trimPrefix"] = func(prefix string, str any) string { return strings.TrimPrefix(toString(str), prefix) }
+	funcs["trimSuffix"] = func(suffix string, str any) string { return strings.TrimSuffix(toString(str), suffix) }
+	funcs["replace"] = func(old, new string, str any) string { return strings.ReplaceAll(toString(str), old, new) }
+	funcs["splitList"] = func(sep string, str any) []string { return strings.Split(toString(str), sep) }
+	funcs["join"] = func(sep string, list any) string { return strings.Join(toStrings(list), sep) }
+	funcs["toString"] = toString
+	funcs["quote"] = func(str any) string { return strconv.Quote(toString(str)) }
+	funcs["atoi"] = func(str any) int { n, _ := strconv.Atoi(toString(str)); return n }
+	funcs["int"] = func(v any) int { n, _ := strconv.Atoi(toString(v)); return n }
+	funcs["default"] = func(d any, given ...any) any {
+		if len(given) == 0 || isEmpty(given[0]) {
+			return d
+		}
+		return given[0]
+	}
+	funcs["empty"] = isEmpty
+	funcs["coalesce"] = func(values ...any) any {
+		for _, v := range values {
+			if !isEmpty(v) {
+				return v
+			}
+		}
+		return nil
+	}
+	funcs["ternary"] = func(vt, vf any, cond bool) any {
+		if cond {
+			return vt
+		}
+		return vf
+	}
+	funcs["list"] = func(items ...any) []any { return items }
+	funcs["has"] = func(needle any, list any) bool {
+		v := reflect.ValueOf(list)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return false
+		}
+		for i := 0; i < v.Len(); i++ {
+			if reflect.DeepEqual(v.Index(i).Interface(), needle) {
+				return true
+			}
+		}
+		return false
+	}
+	funcs["dict"] = func(pairs ...any) map[string]any {
+		d := make(map[string]any, len(pairs)/2)
+		for i := 0; i+1 < len(pairs); i += 2 {
+			d[toString(pairs[i])] = pairs[i+1]
+		}
+		return d
+	}
+	funcs["keys"] = func(d map[string]any) []string {
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys
+	}
+	funcs["fromJson"] = func(str any) any {
+		var v any
+		_ = json.Unmarshal([]byte(toString(str)), &v)
+		return v
+	}
+	funcs["now"] = func() time.Time { return s.Now }
+	funcs["date"] = func(layout string, t time.Time) string { return t.Format(layout) }
+
+	return funcs
+}
+
+// resolve 将主机名解析为IP，已是IP时原样返回，会去掉端口
+func (s *Simulator) resolve(host string) string {
+	host = stripPort(host)
+	if _, err := netip.ParseAddr(host); err == nil {
+		return host
+	}
+	return s.DNSResolve(host)
+}
+
+// stripPort 去掉 host:port 中的端口
+func stripPort(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return strings.Trim(hostport, "[]")
+}
+
+// secondLevelLabels 常见的二级后缀，rootDomain 遇到时多保留一级
+var secondLevelLabels = map[string]bool{"com": true, "net": true, "org": true, "gov": true, "edu": true, "co": true, "ac": true}
+
+// rootDomain 返回主域名，如 www.google.com -> google.com、www.bbc.co.uk -> bbc.co.uk
+// 模拟器不包含完整的公共后缀表，只处理常见的二级后缀
+func rootDomain(host string) string {
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+	if len(labels) <= 2 {
+		return host
+	}
+	n := 2
+	if len(labels[len(labels)-1]) == 2 && secondLevelLabels[labels[len(labels)-2]] {
+		n = 3
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
+
+// matchAny 判断 | 分隔的模式中是否有任意一个匹配
+func matchAny(pattern string, match func(string) bool) bool {
+	for _, p := range strings.Split(pattern, "|") {
+		if p != "" && match(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// inIPSet 判断IP是否在IP集合文件中，每行为单个IP、CIDR或 a-b 形式的范围
+func inIPSet(content string, addr netip.Addr) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if from, to, ok := strings.Cut(line, "-"); ok {
+			start, err1 := netip.ParseAddr(strings.TrimSpace(from))
+			end, err2 := netip.ParseAddr(strings.TrimSpace(to))
+			if err1 == nil && err2 == nil && start.Compare(addr) <= 0 && addr.Compare(end) <= 0 {
+				return true
+			}
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(line); err == nil && prefix.Contains(addr) {
+			return true
+		}
+		if ip, err := netip.ParseAddr(line); err == nil && ip == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// toString 将模板参数转换为字符串
+func toString(v any) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case fmt.Stringer:
+		return s.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toStrings 将列表参数转换为字符串切片
+func toStrings(v any) []string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []string{toString(v)}
+	}
+	result := make([]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		result = append(result, toString(rv.Index(i).Interface()))
+	}
+	return result
+}
+
+// isEmpty 按 sprig 的规则判断值是否为空
+func isEmpty(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return rv.Len() == 0
+	default:
+		return rv.IsZero()
+	}
+}
@@ -0,0 +1,276 @@
+// Package policy 提供 liner policy 模板的离线模拟执行
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bensonfx/mcp-liner/internal/dialer"
+	"github.com/bensonfx/mcp-liner/internal/validation"
+)
+
+// Actions liner policy 的特殊返回值，不是拨号器名称
+var Actions = validation.PolicyActions
+
+// Request 模拟的请求
+type Request struct {
+	Host      string            `json:"host"`
+	Port      int               `json:"port,omitempty"`
+	ClientIP  string            `json:"client_ip,omitempty"`
+	User      string            `json:"user,omitempty"`
+	UserAttrs map[string]string `json:"user_attrs,omitempty"`
+	Method    string            `json:"method,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+}
+
+// FetchResponse fetch 函数的返回值
+type FetchResponse struct {
+	Status int      `json:"status,omitempty"`
+	Body   string   `json:"body"`
+	Lines  []string `json:"-"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Fixtures 离线替身函数使用的数据
+type Fixtures struct {
+	Country  map[string]string        `json:"country,omitempty"`  // IP或CIDR -> 国家代码
+	Geosite  map[string]string        `json:"geosite,omitempty"`  // 域名（匹配自身及子域名） -> 分类
+	DNS      map[string]string        `json:"dns,omitempty"`      // 主机名 -> IP
+	Fetch    map[string]FetchResponse `json:"fetch,omitempty"`    // URL -> 响应
+	Files    map[string]string        `json:"files,omitempty"`    // 文件名 -> 内容，供 inFileLine、inFileIPSet、readFile 使用
+	Now      string                   `json:"now,omitempty"`      // now 函数返回的时间，RFC 3339 格式
+	Timezone string                   `json:"timezone,omitempty"` // now 使用的时区
+}
+
+// Result 单个请求的模拟结果
+type Result struct {
+	Request Request `json:"request"`
+	Output  string  `json:"output"`
+	Action  string  `json:"action,omitempty"`
+	Dialer  string  `json:"dialer,omitempty"`
+	// Undefined 为 true 表示 Dialer 不是 local，也不在配置的 dialer 中
+	Undefined bool   `json:"undefined,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Simulator policy 模板模拟器
+// Country、Geosite、DNSResolve、Fetch 是 liner 对应函数的离线替身，可以替换为自定义实现
+type Simulator struct {
+	Country    func(ip string) string
+	Geosite    func(host string) string
+	DNSResolve func(host string) string
+	Fetch      func(url string) FetchResponse
+	Files      map[string]string
+	Now        time.Time
+}
+
+// NewSimulator 创建使用 fixtures 数据的模拟器
+func NewSimulator(f Fixtures) (*Simulator, error) {
+	s := &Simulator{
+		DNSResolve: func(host string) string { return f.DNS[host] },
+		Files:      f.Files,
+		Now:        time.Now(),
+	}
+
+	countries, err := newPrefixTable(f.Country)
+	if err != nil {
+		return nil, fmt.Errorf("fixtures.country: %w", err)
+	}
+	s.Country = countries.lookup
+
+	s.Geosite = func(host string) string {
+		// 匹配最长的域名后缀
+		best, category := "", ""
+		for domain, c := range f.Geosite {
+			if (host == domain || strings.HasSuffix(host, "."+domain)) && len(domain) > len(best) {
+				best, category = domain, c
+			}
+		}
+		return category
+	}
+
+	s.Fetch = func(u string) FetchResponse {
+		resp, ok := f.Fetch[u]
+		if !ok {
+			return FetchResponse{Error: fmt.Sprintf("no fixture for %s", u)}
+		}
+		if resp.Status == 0 {
+			resp.Status = http.StatusOK
+		}
+		resp.Lines = strings.Split(strings.TrimRight(resp.Body, "\n"), "\n")
+		return resp
+	}
+
+	if f.Now != "" {
+		now, err := time.Parse(time.RFC3339, f.Now)
+		if err != nil {
+			return nil, fmt.Errorf("fixtures.now: %w", err)
+		}
+		s.Now = now
+	}
+	if f.Timezone != "" {
+		loc, err := time.LoadLocation(f.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("fixtures.timezone: %w", err)
+		}
+		s.Now = s.Now.In(loc)
+	}
+
+	return s, nil
+}
+
+// Run 对每个请求执行 policy 模板，dialers 为配置中定义的拨号器，nil 时不检查拨号器是否存在
+// 模板解析失败时返回错误；单个请求执行失败时记录在对应结果的 Error 中
+func (s *Simulator) Run(policy string, requests []Request, dialers map[string]string) ([]Result, error) {
+	tmpl, err := template.New("policy").Funcs(s.funcMap()).Parse(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(requests))
+	for _, req := range requests {
+		result := Result{Request: req}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, templateData(req)); err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		result.Output = strings.TrimSpace(buf.String())
+		name := dialer.FromPolicyOutput(result.Output)
+		switch {
+		case name == "":
+			result.Error = "policy produced no dialer or action"
+		case isAction(name):
+			result.Action = name
+		default:
+			result.Dialer = name
+			if dialers != nil && name != "local" {
+				_, ok := dialers[name]
+				result.Undefined = !ok
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// templateData 构造与 liner 一致的模板上下文
+// 同时包含 http/socks、sni、dns 各类 policy 使用的字段
+func templateData(req Request) map[string]any {
+	hostport := req.Host
+	if req.Port > 0 {
+		hostport = net.JoinHostPort(req.Host, strconv.Itoa(req.Port))
+	}
+	method := req.Method
+	if method == "" {
+		method = http.MethodConnect
+	}
+	header := http.Header{}
+	for k, v := range req.Headers {
+		header.Set(k, v)
+	}
+
+	clientIP := req.ClientIP
+	if clientIP == "" {
+		clientIP = "127.0.0.1"
+	}
+	realIP, _ := netip.ParseAddr(clientIP)
+	remoteAddr := net.JoinHostPort(clientIP, "50000")
+
+	attrs := map[string]string{}
+	for k, v := range req.UserAttrs {
+		attrs[k] = v
+	}
+
+	return map[string]any{
+		"Request": &http.Request{
+			Method:     method,
+			Host:       req.Host,
+			URL:        &url.URL{Host: hostport},
+			Header:     header,
+			RemoteAddr: remoteAddr,
+		},
+		"RealIP":     realIP,
+		"RemoteAddr": remoteAddr,
+		"ServerName": req.Host,
+		"User": map[string]any{
+			"Username": req.User,
+			"Attrs":    attrs,
+		},
+		"UserAgent": map[string]string{
+			"OS":      "",
+			"Name":    "",
+			"Version": "",
+		},
+		"Question": map[string]string{
+			"Name": req.Host + ".",
+			"Type": "A",
+		},
+	}
+}
+
+// isAction 判断是否为特殊动作
+func isAction(name string) bool {
+	for _, a := range Actions {
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixEntry 查找表中的一项
+type prefixEntry struct {
+	prefix netip.Prefix
+	value  string
+}
+
+// prefixTable 按最长前缀匹配IP的查找表
+type prefixTable []prefixEntry
+
+// newPrefixTable 由 IP或CIDR -> 值 的映射创建查找表
+func newPrefixTable(m map[string]string) (prefixTable, error) {
+	t := make(prefixTable, 0, len(m))
+	for key, value := range m {
+		prefix, err := netip.ParsePrefix(key)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(key)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid IP or CIDR %q", key)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		t = append(t, prefixEntry{prefix: prefix.Masked(), value: value})
+	}
+	// 前缀长的排在前面，实现最长前缀匹配
+	sort.Slice(t, func(i, j int) bool { return t[i].prefix.Bits() > t[j].prefix.Bits() })
+	return t, nil
+}
+
+// lookup 返回IP所在的最长前缀对应的值，找不到时返回空字符串
+func (t prefixTable) lookup(ip string) string {
+	addr, err := netip.ParseAddr(stripPort(ip))
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+	for _, e := range t {
+		if e.prefix.Contains(addr) {
+			return e.value
+		}
+	}
+	return ""
+}
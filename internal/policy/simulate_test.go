@@ -0,0 +1,165 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimulatorRun(t *testing.T) {
+	fixtures := Fixtures{
+		Country: map[string]string{
+			"10.0.0.0/8":     "CN",
+			"142.250.0.0/15": "US",
+			"142.250.1.1":    "JP",
+		},
+		Geosite: map[string]string{"youtube.com": "google", "cn": "cn"},
+		DNS: map[string]string{
+			"www.youtube.com": "142.250.1.1",
+			"api.example.com": "142.250.2.2",
+			"www.baidu.cn":    "10.1.2.3",
+			"v6.example.com":  "2001:db8::1",
+		},
+		Fetch: map[string]FetchResponse{
+			"https://example.com/list.txt": {Body: "blocked.example\nbad.example\n"},
+		},
+		Files: map[string]string{"direct.txt": "intranet.example\n", "cn_ips.txt": "10.0.0.0/8\n"},
+		Now:   "2024-05-01T10:30:00Z",
+	}
+	dialers := map[string]string{"cloud": "socks5://1.2.3.4:1080", "us": "socks5://5.6.7.8:1080"}
+
+	tests := []struct {
+		name    string
+		policy  string
+		request Request
+		want    Result
+	}{
+		{
+			name:    "geosite picks dialer",
+			policy:  `{{ if eq (geosite .Request.Host) "google" }}cloud{{ else }}direct{{ end }}`,
+			request: Request{Host: "www.youtube.com", Port: 443, ClientIP: "10.0.0.5"},
+			want:    Result{Output: "cloud", Dialer: "cloud"},
+		},
+		{
+			name:    "country uses longest prefix",
+			policy:  `{{ if eq (country (dnsResolve .Request.Host)) "JP" }}us{{ else }}cloud{{ end }}`,
+			request: Request{Host: "www.youtube.com"},
+			want:    Result{Output: "us", Dialer: "us"},
+		},
+		{
+			name:    "client country via RealIP",
+			policy:  `{{ if eq (country .RealIP) "CN" }}direct{{ else }}cloud{{ end }}`,
+			request: Request{Host: "www.youtube.com", ClientIP: "10.0.0.5"},
+			want:    Result{Output: "direct", Action: "direct"},
+		},
+		{
+			name:    "undefined dialer flagged",
+			policy:  `{{ if hasSuffixes "youtube.com|google.com" .Request.Host }}google_dialer{{ else }}direct{{ end }}`,
+			request: Request{Host: "www.youtube.com"},
+			want:    Result{Output: "google_dialer", Dialer: "google_dialer", Undefined: true},
+		},
+		{
+			name:    "isInNet and JSON output",
+			policy:  `{{ if isInNet (dnsResolve .Request.Host) "10.0.0.0/8" }}{"dialer":"local","disable_ipv6":true}{{ else }}{"dialer":"cloud"}{{ end }}`,
+			request: Request{Host: "www.baidu.cn"},
+			want:    Result{Output: `{"dialer":"local","disable_ipv6":true}`, Dialer: "local"},
+		},
+		{
+			name:    "user attrs and query string output",
+			policy:  `{{ if eq .User.Attrs.type "premium" }}dialer=us&disable_ipv6=false{{ else }}dialer=cloud{{ end }}`,
+			request: Request{Host: "api.example.com", User: "alice", UserAttrs: map[string]string{"type": "premium"}},
+			want:    Result{Output: "dialer=us&disable_ipv6=false", Dialer: "us"},
+		},
+		{
+			name:    "fetch fixture",
+			policy:  `{{ $resp := fetch "" 30 300 "https://example.com/list.txt" }}{{ if contains .Request.Host $resp.Body }}deny{{ else }}cloud{{ end }}`,
+			request: Request{Host: "bad.example"},
+			want:    Result{Output: "deny", Action: "deny"},
+		},
+		{
+			name:    "file fixtures",
+			policy:  `{{ if inFileLine "direct.txt" .Request.Host }}direct{{ else if inFileIPSet "cn_ips.txt" (dnsResolve .Request.Host) }}local{{ else }}cloud{{ end }}`,
+			request: Request{Host: "www.baidu.cn"},
+			want:    Result{Output: "local", Dialer: "local"},
+		},
+		{
+			name:    "time from fixtures",
+			policy:  `{{ $hour := now | date "15" | atoi }}{{ if and (ge $hour 9) (lt $hour 18) }}cloud{{ else }}us{{ end }}`,
+			request: Request{Host: "api.example.com"},
+			want:    Result{Output: "cloud", Dialer: "cloud"},
+		},
+		{
+			name:    "headers and ipv6",
+			policy:  `{{ if and (hasIPv6 .Request.Host) (eq (.Request.Header.Get "X-Team") "ops") }}us{{ else }}cloud{{ end }}`,
+			request: Request{Host: "v6.example.com", Headers: map[string]string{"X-Team": "ops"}},
+			want:    Result{Output: "us", Dialer: "us"},
+		},
+		{
+			name:    "empty output",
+			policy:  `{{ if eq .Request.Host "x" }}cloud{{ end }}`,
+			request: Request{Host: "y"},
+			want:    Result{Error: "policy produced no dialer or action"},
+		},
+		{
+			name:    "unsupported function",
+			policy:  `{{ if eq (sha256sum .Request.Host) "x" }}cloud{{ end }}`,
+			request: Request{Host: "y"},
+			want:    Result{Error: `function "sha256sum" is not supported by the simulator`},
+		},
+	}
+
+	sim, err := NewSimulator(fixtures)
+	if err != nil {
+		t.Fatalf("NewSimulator() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := sim.Run(tt.policy, []Request{tt.request}, dialers)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			got := results[0]
+			if got.Output != tt.want.Output || got.Dialer != tt.want.Dialer || got.Action != tt.want.Action || got.Undefined != tt.want.Undefined {
+				t.Errorf("Run() = %+v, want %+v", got, tt.want)
+			}
+			if !strings.Contains(got.Error, tt.want.Error) || (tt.want.Error == "") != (got.Error == "") {
+				t.Errorf("Run() error = %q, want %q", got.Error, tt.want.Error)
+			}
+		})
+	}
+}
+
+func TestSimulatorRunErrors(t *testing.T) {
+	if _, err := NewSimulator(Fixtures{Country: map[string]string{"not-an-ip": "CN"}}); err == nil {
+		t.Error("NewSimulator() expected error for invalid country fixture")
+	}
+
+	sim, err := NewSimulator(Fixtures{})
+	if err != nil {
+		t.Fatalf("NewSimulator() error = %v", err)
+	}
+	if _, err := sim.Run("{{ if }}", []Request{{Host: "a"}}, nil); err == nil {
+		t.Error("Run() expected parse error")
+	}
+
+	// 替换替身函数
+	sim.Geosite = func(string) string { return "custom" }
+	results, err := sim.Run(`{{ geosite .Request.Host }}`, []Request{{Host: "a"}}, nil)
+	if err != nil || results[0].Dialer != "custom" || results[0].Undefined {
+		t.Errorf("Run() with custom geosite = %+v, %v", results, err)
+	}
+}
+
+func TestRootDomain(t *testing.T) {
+	tests := map[string]string{
+		"www.google.com":  "google.com",
+		"google.com":      "google.com",
+		"www.bbc.co.uk":   "bbc.co.uk",
+		"a.b.example.org": "example.org",
+	}
+	for host, want := range tests {
+		if got := rootDomain(host); got != want {
+			t.Errorf("rootDomain(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
@@ -2,17 +2,23 @@
 package responses
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/bensonfx/mcp-liner/internal/audit"
+	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/bensonfx/mcp-liner/internal/policy"
 	"github.com/bensonfx/mcp-liner/internal/validation"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"gopkg.in/yaml.v3"
 )
 
-// MCPResponse MCP标准响应格式
-type MCPResponse struct {
-	Content []ContentBlock `json:"content"`
-	IsError bool           `json:"isError,omitempty"`
+// Result 工具执行结果，与 mcp.CallToolResult 一一对应
+// Content 面向阅读，Structured 面向程序处理
+type Result struct {
+	Content    []ContentBlock
+	Structured any
+	IsError    bool
 }
 
 // ContentBlock 内容块
@@ -21,129 +27,435 @@ type ContentBlock struct {
 	Text string `json:"text"`
 }
 
+// ConfigOutput 配置类响应的结构化输出
+type ConfigOutput struct {
+	YAML   string         `json:"yaml"`
+	Config map[string]any `json:"config"`
+}
+
+// ContentOutput 非YAML内容（CSV、防火墙规则、模板示例等）的结构化输出
+type ContentOutput struct {
+	Format  string `json:"format"`
+	Content string `json:"content"`
+}
+
+// ErrorOutput 错误响应的结构化输出
+type ErrorOutput struct {
+	Error      string `json:"error"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// ValidationOutput 验证响应的结构化输出
+// Errors 包含所有级别的发现，按 severity 区分
+type ValidationOutput struct {
+	Valid      bool                    `json:"valid"`
+	Errors     []ValidationErrorOutput `json:"errors"`
+	Suppressed int                     `json:"suppressed,omitempty"`
+}
+
+// ValidationErrorOutput 单条验证发现
+type ValidationErrorOutput struct {
+	Field      string `json:"field"`
+	Message    string `json:"message"`
+	Code       string `json:"code,omitempty"`
+	Severity   string `json:"severity"`
+	Suggestion string `json:"suggestion,omitempty"`
+	Line       int    `json:"line,omitempty"`
+	Column     int    `json:"column,omitempty"`
+}
+
+// DocumentationOutput 文档响应的结构化输出
+type DocumentationOutput struct {
+	Topic   string `json:"topic"`
+	Content string `json:"content"`
+}
+
+// PatchOutput 修改配置响应的结构化输出
+type PatchOutput struct {
+	YAML       string           `json:"yaml"`
+	Config     map[string]any   `json:"config"`
+	Diff       string           `json:"diff"`
+	Validation ValidationOutput `json:"validation"`
+}
+
+// DiffOutput 配置差异响应的结构化输出
+type DiffOutput struct {
+	Identical bool            `json:"identical"`
+	Added     int             `json:"added"`
+	Removed   int             `json:"removed"`
+	Changed   int             `json:"changed"`
+	Changes   []config.Change `json:"changes"`
+	Summary   string          `json:"summary"`
+}
+
+// SimulationOutput policy 模拟响应的结构化输出
+type SimulationOutput struct {
+	Results []policy.Result `json:"results"`
+	// DialersChecked 为 false 表示未提供配置，没有检查拨号器是否已定义
+	DialersChecked bool `json:"dialers_checked"`
+	Undefined      int  `json:"undefined"`
+	Errors         int  `json:"errors"`
+}
+
+// AuditOutput 安全审计响应的结构化输出
+type AuditOutput struct {
+	Score             int             `json:"score"`
+	Grade             string          `json:"grade"`
+	Findings          []audit.Finding `json:"findings"`
+	Valid             bool            `json:"valid"`
+	ValidationErrors  int             `json:"validation_errors"`
+	AuthTablesChecked []string        `json:"auth_tables_checked"`
+}
+
+// Text 返回所有文本内容块，以空行分隔
+func (r *Result) Text() string {
+	texts := make([]string, 0, len(r.Content))
+	for _, block := range r.Content {
+		texts = append(texts, block.Text)
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// CallToolResult 转换为 MCP 协议的工具调用结果
+func (r *Result) CallToolResult() *mcp.CallToolResult {
+	content := make([]mcp.Content, 0, len(r.Content))
+	for _, block := range r.Content {
+		content = append(content, &mcp.TextContent{Text: block.Text})
+	}
+	return &mcp.CallToolResult{
+		Content:           content,
+		StructuredContent: r.Structured,
+		IsError:           r.IsError,
+	}
+}
+
 // SuccessResponse 创建成功响应
-// yamlContent: 生成的YAML配置内容
+// yamlContent: 生成的YAML配置内容，作为独立的内容块返回
 // description: 配置说明（可选）
-func SuccessResponse(yamlContent string, description string) (string, error) {
-	var textBuilder strings.Builder
-
-	if description != "" {
-		textBuilder.WriteString(description)
-		textBuilder.WriteString("\n\n")
+func SuccessResponse(yamlContent string, description string) (*Result, error) {
+	output, err := newConfigOutput(yamlContent)
+	if err != nil {
+		return invalidConfigResponse(err)
 	}
 
-	textBuilder.WriteString("Generated Liner Configuration:\n\n")
-	textBuilder.WriteString("```yaml\n")
-	textBuilder.WriteString(yamlContent)
-	textBuilder.WriteString("\n```")
+	if description == "" {
+		description = "Generated Liner Configuration"
+	}
 
-	response := MCPResponse{
+	return &Result{
 		Content: []ContentBlock{
-			{
-				Type: "text",
-				Text: textBuilder.String(),
-			},
+			textBlock(description),
+			textBlock(yamlContent),
 		},
-		IsError: false,
+		Structured: output,
+	}, nil
+}
+
+// ConfigWithExampleResponse 创建带示例的配置响应
+// yamlContent: 生成的YAML配置
+// example: 使用示例
+func ConfigWithExampleResponse(yamlContent string, example string) (*Result, error) {
+	output, err := newConfigOutput(yamlContent)
+	if err != nil {
+		return invalidConfigResponse(err)
+	}
+
+	content := []ContentBlock{
+		textBlock("Generated Liner Configuration"),
+		textBlock(yamlContent),
+	}
+	if example != "" {
+		content = append(content, textBlock("## Usage Example\n\n"+example))
+	}
+
+	return &Result{
+		Content:    content,
+		Structured: output,
+	}, nil
+}
+
+// ContentResponse 创建非YAML内容的响应
+// content: 生成的内容，作为独立的内容块返回
+// format: 内容格式，如 "csv"、"iptables-save"
+// description: 内容说明（可选）
+func ContentResponse(content string, format string, description string) (*Result, error) {
+	blocks := []ContentBlock{}
+	if description != "" {
+		blocks = append(blocks, textBlock(description))
 	}
+	blocks = append(blocks, textBlock(content))
 
-	return marshalResponse(response)
+	return &Result{
+		Content: blocks,
+		Structured: ContentOutput{
+			Format:  format,
+			Content: content,
+		},
+	}, nil
 }
 
 // ErrorResponse 创建错误响应
 // errorMsg: 错误信息
 // suggestion: 建议（可选）
-func ErrorResponse(errorMsg string, suggestion string) (string, error) {
+func ErrorResponse(errorMsg string, suggestion string) (*Result, error) {
 	var textBuilder strings.Builder
 
 	textBuilder.WriteString("Error: ")
 	textBuilder.WriteString(errorMsg)
-	textBuilder.WriteString("\n")
 
 	if suggestion != "" {
-		textBuilder.WriteString("\nSuggestion: ")
+		textBuilder.WriteString("\n\nSuggestion: ")
 		textBuilder.WriteString(suggestion)
 	}
 
-	response := MCPResponse{
-		Content: []ContentBlock{
-			{
-				Type: "text",
-				Text: textBuilder.String(),
-			},
+	return &Result{
+		Content: []ContentBlock{textBlock(textBuilder.String())},
+		Structured: ErrorOutput{
+			Error:      errorMsg,
+			Suggestion: suggestion,
 		},
 		IsError: true,
-	}
-
-	return marshalResponse(response)
+	}, nil
 }
 
 // ValidationResponse 创建验证响应
 // result: 验证结果
-func ValidationResponse(result *validation.ValidationResult) (string, error) {
-	if result.Valid {
-		response := MCPResponse{
+func ValidationResponse(result *validation.ValidationResult) (*Result, error) {
+	output := newValidationOutput(result)
+
+	if result.Valid && len(result.Errors) == 0 {
+		return &Result{
 			Content: []ContentBlock{
-				{
-					Type: "text",
-					Text: "✅ Configuration validation passed!\n\nThe liner configuration is valid and ready to use.",
-				},
+				textBlock("✅ Configuration validation passed!\n\nThe liner configuration is valid and ready to use."),
 			},
-			IsError: false,
-		}
-		return marshalResponse(response)
+			Structured: output,
+		}, nil
 	}
 
-	// 构建错误信息
 	var textBuilder strings.Builder
-	textBuilder.WriteString("❌ Configuration validation failed!\n\n")
-	textBuilder.WriteString(fmt.Sprintf("Found %d error(s):\n\n", len(result.Errors)))
-
-	for i, err := range result.Errors {
-		textBuilder.WriteString(fmt.Sprintf("%d. **%s**: %s\n", i+1, err.Field, err.Message))
+	if result.Valid {
+		textBuilder.WriteString("✅ Configuration validation passed with warnings.\n\n")
+	} else {
+		textBuilder.WriteString("❌ Configuration validation failed!\n\n")
 	}
+	textBuilder.WriteString(findingsSummary(result) + ":\n\n")
+	writeFindings(&textBuilder, result)
 
+	if result.Valid {
+		return &Result{
+			Content:    []ContentBlock{textBlock(textBuilder.String())},
+			Structured: output,
+		}, nil
+	}
 	textBuilder.WriteString("\nPlease fix these errors and try again.")
 
-	response := MCPResponse{
+	return &Result{
+		Content:    []ContentBlock{textBlock(textBuilder.String())},
+		Structured: output,
+		IsError:    true,
+	}, nil
+}
+
+// PatchResponse 创建修改配置的响应
+// yamlContent: 修改后的YAML配置
+// diff: 修改前后的 unified diff
+// result: 修改后配置的验证结果，验证失败时响应标记为错误
+func PatchResponse(yamlContent string, diff string, result *validation.ValidationResult) (*Result, error) {
+	output, err := newConfigOutput(yamlContent)
+	if err != nil {
+		return invalidConfigResponse(err)
+	}
+	validationOutput := newValidationOutput(result)
+
+	diffText := "## Diff\n\nNo changes."
+	if diff != "" {
+		diffText = "## Diff\n\n```diff\n" + diff + "```"
+	}
+	content := []ContentBlock{
+		textBlock("Patched Liner Configuration"),
+		textBlock(yamlContent),
+		textBlock(diffText),
+	}
+
+	switch {
+	case result.Valid && len(result.Errors) == 0:
+		content = append(content, textBlock("✅ The patched configuration is valid."))
+	case result.Valid:
+		var textBuilder strings.Builder
+		textBuilder.WriteString(fmt.Sprintf("✅ The patched configuration is valid, %s:\n\n", findingsSummary(result)))
+		writeFindings(&textBuilder, result)
+		content = append(content, textBlock(textBuilder.String()))
+	default:
+		var textBuilder strings.Builder
+		textBuilder.WriteString(fmt.Sprintf("❌ The patched configuration has validation errors (%s):\n\n", findingsSummary(result)))
+		writeFindings(&textBuilder, result)
+		content = append(content, textBlock(textBuilder.String()))
+	}
+
+	return &Result{
+		Content: content,
+		Structured: PatchOutput{
+			YAML:       output.YAML,
+			Config:     output.Config,
+			Diff:       diff,
+			Validation: validationOutput,
+		},
+		IsError: !result.Valid,
+	}, nil
+}
+
+// DiffResponse 创建配置差异响应
+// changes: config.Diff 返回的差异列表
+func DiffResponse(changes []config.Change) (*Result, error) {
+	output := DiffOutput{
+		Identical: len(changes) == 0,
+		Changes:   make([]config.Change, 0, len(changes)),
+	}
+	lines := make([]string, 0, len(changes))
+	for _, c := range changes {
+		switch c.Kind {
+		case config.ChangeAdded:
+			output.Added++
+		case config.ChangeRemoved:
+			output.Removed++
+		default:
+			output.Changed++
+		}
+		output.Changes = append(output.Changes, c)
+		lines = append(lines, c.String())
+	}
+	output.Summary = strings.Join(lines, "\n")
+
+	if output.Identical {
+		return &Result{
+			Content:    []ContentBlock{textBlock("✅ The configurations are equivalent.")},
+			Structured: output,
+		}, nil
+	}
+
+	return &Result{
 		Content: []ContentBlock{
-			{
-				Type: "text",
-				Text: textBuilder.String(),
-			},
+			textBlock(fmt.Sprintf("## Configuration Diff\n\n%d change(s): %d added, %d removed, %d changed",
+				len(changes), output.Added, output.Removed, output.Changed)),
+			textBlock("```\n" + output.Summary + "\n```"),
 		},
-		IsError: true,
+		Structured: output,
+	}, nil
+}
+
+// SimulationResponse 创建 policy 模拟响应
+// results: 每个请求的模拟结果
+// dialersChecked: 是否已按配置检查拨号器
+func SimulationResponse(results []policy.Result, dialersChecked bool) (*Result, error) {
+	output := SimulationOutput{
+		Results:        results,
+		DialersChecked: dialersChecked,
+	}
+
+	var textBuilder strings.Builder
+	textBuilder.WriteString("## Policy Simulation\n\n")
+	for i, r := range results {
+		target := r.Request.Host
+		if r.Request.Port > 0 {
+			target = fmt.Sprintf("%s:%d", r.Request.Host, r.Request.Port)
+		}
+		if r.Request.ClientIP != "" {
+			target += " from " + r.Request.ClientIP
+		}
+		if r.Request.User != "" {
+			target += " as " + r.Request.User
+		}
+
+		var outcome string
+		switch {
+		case r.Error != "":
+			output.Errors++
+			outcome = "❌ error: " + r.Error
+		case r.Action != "":
+			outcome = fmt.Sprintf("action %q", r.Action)
+		case r.Undefined:
+			output.Undefined++
+			outcome = fmt.Sprintf("dialer %q ⚠️ not defined in the dialer section", r.Dialer)
+		default:
+			outcome = fmt.Sprintf("dialer %q", r.Dialer)
+		}
+		textBuilder.WriteString(fmt.Sprintf("%d. %s → %s\n", i+1, target, outcome))
 	}
 
-	return marshalResponse(response)
+	summary := fmt.Sprintf("\n%d request(s) simulated", len(results))
+	if output.Undefined > 0 {
+		summary += fmt.Sprintf(", %d picked an undefined dialer", output.Undefined)
+	}
+	if output.Errors > 0 {
+		summary += fmt.Sprintf(", %d failed", output.Errors)
+	}
+	if !dialersChecked {
+		summary += "\n\nNo configuration was provided, so dialer names were not checked."
+	}
+	textBuilder.WriteString(summary)
+
+	return &Result{
+		Content:    []ContentBlock{textBlock(textBuilder.String())},
+		Structured: output,
+	}, nil
 }
 
-// DocumentationResponse 创建文档响应
-// topic: 主题
-// content: 文档内容
-func DocumentationResponse(topic string, content string) (string, error) {
+// AuditResponse 创建安全审计响应
+// report: audit.Audit 返回的审计报告，发现已按风险排序
+func AuditResponse(report *audit.Report) (*Result, error) {
+	output := AuditOutput{
+		Score:             report.Score,
+		Grade:             report.Grade,
+		Findings:          make([]audit.Finding, 0, len(report.Findings)),
+		Valid:             report.Valid,
+		ValidationErrors:  report.ValidationErrors,
+		AuthTablesChecked: report.AuthTablesChecked,
+	}
+	output.Findings = append(output.Findings, report.Findings...)
+
 	var textBuilder strings.Builder
+	textBuilder.WriteString(fmt.Sprintf("## Security Audit\n\nScore: %d/100 (grade %s)\n", report.Score, report.Grade))
+	if !report.Valid {
+		textBuilder.WriteString(fmt.Sprintf("\n⚠️ The configuration also has %d validation error(s), run validate_liner_config for details.\n", report.ValidationErrors))
+	}
 
-	textBuilder.WriteString(fmt.Sprintf("# Liner Documentation: %s\n\n", topic))
-	textBuilder.WriteString(content)
+	if len(report.Findings) == 0 {
+		textBuilder.WriteString("\n✅ No exposure issues found.")
+	} else {
+		textBuilder.WriteString(fmt.Sprintf("\n%d finding(s), most severe first:\n\n", len(report.Findings)))
+		for i, f := range report.Findings {
+			textBuilder.WriteString(fmt.Sprintf("%d. [%s] `%s` **%s**: %s\n   Remediation: %s\n", i+1, f.Level, f.Code, f.Field, f.Message, f.Remediation))
+		}
+	}
+	if len(report.AuthTablesChecked) == 0 {
+		textBuilder.WriteString("\n\nNo auth table contents were provided, so passwords were not checked.")
+	}
 
-	response := MCPResponse{
+	return &Result{
+		Content:    []ContentBlock{textBlock(textBuilder.String())},
+		Structured: output,
+	}, nil
+}
+
+// DocumentationResponse 创建文档响应
+// topic: 主题
+// content: 文档内容
+func DocumentationResponse(topic string, content string) (*Result, error) {
+	return &Result{
 		Content: []ContentBlock{
-			{
-				Type: "text",
-				Text: textBuilder.String(),
-			},
+			textBlock(fmt.Sprintf("# Liner Documentation: %s\n\n%s", topic, content)),
 		},
-		IsError: false,
-	}
-
-	return marshalResponse(response)
+		Structured: DocumentationOutput{
+			Topic:   topic,
+			Content: content,
+		},
+	}, nil
 }
 
 // InfoResponse 创建信息响应
 // title: 标题
 // info: 信息内容
-func InfoResponse(title string, info string) (string, error) {
+func InfoResponse(title string, info string) (*Result, error) {
 	var textBuilder strings.Builder
 
 	if title != "" {
@@ -152,67 +464,100 @@ func InfoResponse(title string, info string) (string, error) {
 
 	textBuilder.WriteString(info)
 
-	response := MCPResponse{
-		Content: []ContentBlock{
-			{
-				Type: "text",
-				Text: textBuilder.String(),
-			},
-		},
-		IsError: false,
-	}
-
-	return marshalResponse(response)
+	return &Result{
+		Content: []ContentBlock{textBlock(textBuilder.String())},
+	}, nil
 }
 
-// ConfigWithExampleResponse 创建带示例的配置响应
-// yamlContent: 生成的YAML配置
-// example: 使用示例
-func ConfigWithExampleResponse(yamlContent string, example string) (string, error) {
-	var textBuilder strings.Builder
+// SimpleTextResponse 创建简单文本响应（用于调试）
+func SimpleTextResponse(text string) (*Result, error) {
+	return &Result{
+		Content: []ContentBlock{textBlock(text)},
+	}, nil
+}
 
-	textBuilder.WriteString("Generated Liner Configuration:\n\n")
-	textBuilder.WriteString("```yaml\n")
-	textBuilder.WriteString(yamlContent)
-	textBuilder.WriteString("\n```\n\n")
+// invalidConfigResponse 生成的YAML无法解析时返回错误结果，而不是让工具调用以协议错误失败
+func invalidConfigResponse(err error) (*Result, error) {
+	return ErrorResponse(
+		err.Error(),
+		"Check the input values for YAML special characters such as ':', '[' or '#'",
+	)
+}
 
-	if example != "" {
-		textBuilder.WriteString("## Usage Example\n\n")
-		textBuilder.WriteString(example)
+// newConfigOutput 解析YAML，生成配置类响应的结构化输出
+func newConfigOutput(yamlContent string) (ConfigOutput, error) {
+	parsed := map[string]any{}
+	if err := yaml.Unmarshal([]byte(yamlContent), &parsed); err != nil {
+		return ConfigOutput{}, fmt.Errorf("failed to parse generated YAML: %w", err)
 	}
+	return ConfigOutput{
+		YAML:   yamlContent,
+		Config: parsed,
+	}, nil
+}
 
-	response := MCPResponse{
-		Content: []ContentBlock{
-			{
-				Type: "text",
-				Text: textBuilder.String(),
-			},
-		},
-		IsError: false,
+// newValidationOutput 将验证结果转换为结构化输出
+func newValidationOutput(result *validation.ValidationResult) ValidationOutput {
+	output := ValidationOutput{
+		Valid:      result.Valid,
+		Errors:     make([]ValidationErrorOutput, 0, len(result.Errors)),
+		Suppressed: result.Suppressed,
+	}
+	for _, err := range result.Errors {
+		severity := err.Severity
+		if severity == "" {
+			severity = validation.SeverityError
+		}
+		output.Errors = append(output.Errors, ValidationErrorOutput{
+			Field:      err.Field,
+			Message:    err.Message,
+			Code:       err.Code,
+			Severity:   string(severity),
+			Suggestion: err.Suggestion,
+			Line:       err.Line,
+			Column:     err.Column,
+		})
 	}
+	return output
+}
 
-	return marshalResponse(response)
+// findingsSummary 按严重程度统计发现，如 "1 error(s), 2 warning(s)"
+func findingsSummary(result *validation.ValidationResult) string {
+	var parts []string
+	for _, s := range []validation.Severity{validation.SeverityError, validation.SeverityWarning, validation.SeverityInfo} {
+		if n := result.Count(s); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s(s)", n, s))
+		}
+	}
+	return strings.Join(parts, ", ")
 }
 
-// marshalResponse 序列化响应为JSON
-func marshalResponse(response MCPResponse) (string, error) {
-	data, err := json.MarshalIndent(response, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal response: %w", err)
+// writeFindings 逐条写出验证发现及修复建议
+func writeFindings(builder *strings.Builder, result *validation.ValidationResult) {
+	for i, err := range result.Errors {
+		severity := err.Severity
+		if severity == "" {
+			severity = validation.SeverityError
+		}
+		location := ""
+		if err.Line > 0 {
+			location = fmt.Sprintf(" (line %d, column %d)", err.Line, err.Column)
+		}
+		code := ""
+		if err.Code != "" {
+			code = " `" + err.Code + "`"
+		}
+		builder.WriteString(fmt.Sprintf("%d. [%s]%s **%s**%s: %s\n", i+1, severity, code, err.Field, location, err.Message))
+		if err.Suggestion != "" {
+			builder.WriteString(fmt.Sprintf("   Fix: %s\n", err.Suggestion))
+		}
 	}
-	return string(data), nil
 }
 
-// SimpleTextResponse 创建简单文本响应（用于调试）
-func SimpleTextResponse(text string) (string, error) {
-	response := MCPResponse{
-		Content: []ContentBlock{
-			{
-				Type: "text",
-				Text: text,
-			},
-		},
-		IsError: false,
+// textBlock 创建文本内容块
+func textBlock(text string) ContentBlock {
+	return ContentBlock{
+		Type: "text",
+		Text: text,
 	}
-	return marshalResponse(response)
 }
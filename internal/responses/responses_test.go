@@ -0,0 +1,215 @@
+package responses
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/bensonfx/mcp-liner/internal/policy"
+	"github.com/bensonfx/mcp-liner/internal/validation"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSuccessResponse(t *testing.T) {
+	yamlContent := "dialer:\n  cloud: socks5://127.0.0.1:1080\n"
+	result, err := SuccessResponse(yamlContent, "Generated dialer")
+	if err != nil {
+		t.Fatalf("SuccessResponse failed: %v", err)
+	}
+
+	if result.IsError {
+		t.Error("Success response should not be an error")
+	}
+	if len(result.Content) != 2 || result.Content[1].Text != yamlContent {
+		t.Errorf("YAML should be returned as its own content block, got %#v", result.Content)
+	}
+
+	output, ok := result.Structured.(ConfigOutput)
+	if !ok {
+		t.Fatalf("Structured should be ConfigOutput, got %T", result.Structured)
+	}
+	if output.YAML != yamlContent {
+		t.Error("Structured YAML does not match")
+	}
+	dialer, ok := output.Config["dialer"].(map[string]any)
+	if !ok || dialer["cloud"] != "socks5://127.0.0.1:1080" {
+		t.Errorf("Structured config not parsed correctly: %#v", output.Config)
+	}
+}
+
+func TestSuccessResponseInvalidYAML(t *testing.T) {
+	for name, respond := range map[string]func(string) (*Result, error){
+		"SuccessResponse":           func(y string) (*Result, error) { return SuccessResponse(y, "") },
+		"ConfigWithExampleResponse": func(y string) (*Result, error) { return ConfigWithExampleResponse(y, "") },
+		"PatchResponse": func(y string) (*Result, error) {
+			return PatchResponse(y, "", &validation.ValidationResult{Valid: true})
+		},
+	} {
+		result, err := respond("key: [unclosed")
+		if err != nil {
+			t.Fatalf("%s returned a protocol error for invalid YAML: %v", name, err)
+		}
+		if !result.IsError || !strings.Contains(result.Text(), "failed to parse generated YAML") {
+			t.Errorf("%s should return an error result for invalid YAML, got %q", name, result.Text())
+		}
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	result, err := ErrorResponse("something broke", "try again")
+	if err != nil {
+		t.Fatalf("ErrorResponse failed: %v", err)
+	}
+
+	if !result.IsError {
+		t.Error("Error response should set IsError")
+	}
+	if !strings.Contains(result.Text(), "something broke") || !strings.Contains(result.Text(), "try again") {
+		t.Errorf("Unexpected error text: %s", result.Text())
+	}
+
+	ctr := result.CallToolResult()
+	if !ctr.IsError {
+		t.Error("CallToolResult should carry IsError")
+	}
+	if output, ok := ctr.StructuredContent.(ErrorOutput); !ok || output.Error != "something broke" {
+		t.Errorf("Unexpected structured content: %#v", ctr.StructuredContent)
+	}
+}
+
+func TestValidationResponse(t *testing.T) {
+	result, err := ValidationResponse(&validation.ValidationResult{
+		Valid: false,
+		Errors: []validation.ValidationError{
+			{Field: "https[0].listen", Message: "listen field is required and cannot be empty"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ValidationResponse failed: %v", err)
+	}
+
+	if !result.IsError {
+		t.Error("Failed validation should set IsError")
+	}
+	output := result.Structured.(ValidationOutput)
+	if output.Valid || len(output.Errors) != 1 || output.Errors[0].Field != "https[0].listen" {
+		t.Errorf("Unexpected structured output: %#v", output)
+	}
+
+	result, err = ValidationResponse(&validation.ValidationResult{Valid: true})
+	if err != nil {
+		t.Fatalf("ValidationResponse failed: %v", err)
+	}
+	if result.IsError {
+		t.Error("Passed validation should not set IsError")
+	}
+}
+
+func TestPatchResponse(t *testing.T) {
+	diff := "--- a/config.yaml\n+++ b/config.yaml\n@@ -1 +1 @@\n-dialer: {}\n+dialer: {cloud: local}\n"
+	result, err := PatchResponse("dialer:\n  cloud: local\n", diff, &validation.ValidationResult{Valid: true})
+	if err != nil {
+		t.Fatalf("PatchResponse failed: %v", err)
+	}
+	if result.IsError {
+		t.Error("Valid patched config should not set IsError")
+	}
+	output := result.Structured.(PatchOutput)
+	if output.Diff != diff || output.Config["dialer"] == nil || !output.Validation.Valid {
+		t.Errorf("Unexpected structured output: %#v", output)
+	}
+	if !strings.Contains(result.Text(), "```diff\n"+diff+"```") {
+		t.Errorf("Diff block missing from text:\n%s", result.Text())
+	}
+
+	result, err = PatchResponse("dialer: {}\n", "", &validation.ValidationResult{
+		Valid:  false,
+		Errors: []validation.ValidationError{{Field: "tunnel[0].dialer", Message: "dialer not found"}},
+	})
+	if err != nil {
+		t.Fatalf("PatchResponse failed: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Invalid patched config should set IsError")
+	}
+	if !strings.Contains(result.Text(), "No changes.") || !strings.Contains(result.Text(), "tunnel[0].dialer") {
+		t.Errorf("Unexpected text:\n%s", result.Text())
+	}
+}
+
+func TestDiffResponse(t *testing.T) {
+	result, err := DiffResponse(nil)
+	if err != nil {
+		t.Fatalf("DiffResponse failed: %v", err)
+	}
+	if output := result.Structured.(DiffOutput); !output.Identical || !strings.Contains(result.Text(), "equivalent") {
+		t.Errorf("Unexpected response for identical configs: %#v", output)
+	}
+
+	result, err = DiffResponse([]config.Change{
+		{Kind: config.ChangeChanged, Path: "dialer[cloud]", Old: "socks5://1.2.3.4:1080", New: "socks5://5.6.7.8:1080"},
+		{Kind: config.ChangeAdded, Path: "global.dial_timeout", New: 30},
+	})
+	if err != nil {
+		t.Fatalf("DiffResponse failed: %v", err)
+	}
+	output := result.Structured.(DiffOutput)
+	if output.Identical || output.Added != 1 || output.Changed != 1 || len(output.Changes) != 2 {
+		t.Errorf("Unexpected structured output: %#v", output)
+	}
+	if result.IsError {
+		t.Error("Differences should not set IsError")
+	}
+	if !strings.Contains(result.Text(), "2 change(s): 1 added, 0 removed, 1 changed") ||
+		!strings.Contains(result.Text(), `~ dialer[cloud]: "socks5://1.2.3.4:1080" → "socks5://5.6.7.8:1080"`) {
+		t.Errorf("Unexpected text:\n%s", result.Text())
+	}
+}
+
+func TestSimulationResponse(t *testing.T) {
+	result, err := SimulationResponse([]policy.Result{
+		{Request: policy.Request{Host: "www.youtube.com", Port: 443, ClientIP: "10.0.0.5"}, Output: "cloud", Dialer: "cloud"},
+		{Request: policy.Request{Host: "www.baidu.cn"}, Output: "direct", Action: "direct"},
+		{Request: policy.Request{Host: "api.example.com"}, Output: "us", Dialer: "us", Undefined: true},
+		{Request: policy.Request{Host: "x"}, Error: "policy produced no dialer or action"},
+	}, true)
+	if err != nil {
+		t.Fatalf("SimulationResponse failed: %v", err)
+	}
+	output := result.Structured.(SimulationOutput)
+	if !output.DialersChecked || output.Undefined != 1 || output.Errors != 1 || len(output.Results) != 4 {
+		t.Errorf("Unexpected structured output: %#v", output)
+	}
+	for _, want := range []string{
+		`1. www.youtube.com:443 from 10.0.0.5 → dialer "cloud"`,
+		`2. www.baidu.cn → action "direct"`,
+		`3. api.example.com → dialer "us" ⚠️ not defined in the dialer section`,
+		"4. x → ❌ error: policy produced no dialer or action",
+		"4 request(s) simulated, 1 picked an undefined dialer, 1 failed",
+	} {
+		if !strings.Contains(result.Text(), want) {
+			t.Errorf("Text missing %q:\n%s", want, result.Text())
+		}
+	}
+	if strings.Contains(result.Text(), "not checked") {
+		t.Error("Text should not mention unchecked dialers when a config was provided")
+	}
+}
+
+func TestCallToolResult(t *testing.T) {
+	result, err := ContentResponse("username,password\n", "csv", "Generated auth_user.csv")
+	if err != nil {
+		t.Fatalf("ContentResponse failed: %v", err)
+	}
+
+	ctr := result.CallToolResult()
+	if len(ctr.Content) != 2 {
+		t.Fatalf("Expected 2 content blocks, got %d", len(ctr.Content))
+	}
+	if text, ok := ctr.Content[1].(*mcp.TextContent); !ok || text.Text != "username,password\n" {
+		t.Errorf("Unexpected content block: %#v", ctr.Content[1])
+	}
+	if output, ok := ctr.StructuredContent.(ContentOutput); !ok || output.Format != "csv" {
+		t.Errorf("Unexpected structured content: %#v", ctr.StructuredContent)
+	}
+}
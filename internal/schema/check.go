@@ -0,0 +1,175 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// Kind 检查发现的类型
+type Kind string
+
+const (
+	KindUnknownKey  Kind = "unknown-key"  // schema 中没有的键
+	KindInvalidType Kind = "invalid-type" // 值的类型与schema不符
+)
+
+// Finding 一条按schema检查的发现，Path 使用与验证结果相同的格式，如 https[0].forward.policy
+type Finding struct {
+	Kind    Kind
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Check 使用内置schema检查YAML配置
+func Check(content string) ([]Finding, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, err
+	}
+	return CheckNode(Default(), &doc), nil
+}
+
+// CheckNode 使用指定的schema检查已解析的YAML文档
+func CheckNode(root *jsonschema.Schema, doc *yaml.Node) []Finding {
+	c := &checker{root: root}
+	if doc.Kind == yaml.DocumentNode {
+		if len(doc.Content) == 0 {
+			return nil
+		}
+		doc = doc.Content[0]
+	}
+	c.check(root, doc, "")
+	return c.findings
+}
+
+type checker struct {
+	root     *jsonschema.Schema
+	findings []Finding
+}
+
+func (c *checker) add(kind Kind, path string, node *yaml.Node, format string, args ...any) {
+	c.findings = append(c.findings, Finding{
+		Kind:    kind,
+		Path:    path,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// check 检查节点是否符合schema，path 为节点的字段路径
+func (c *checker) check(s *jsonschema.Schema, node *yaml.Node, path string) {
+	s = Resolve(c.root, s)
+	if s == nil {
+		return
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	// 与 yaml.Unmarshal 一致，null 值等同于未设置
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			c.add(KindInvalidType, path, node, "expected a mapping, got %s", describeNode(node))
+			return
+		}
+		c.checkMapping(s, node, path)
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			c.add(KindInvalidType, path, node, "expected a list, got %s", describeNode(node))
+			return
+		}
+		for i, item := range node.Content {
+			c.check(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case "string", "integer", "boolean":
+		if node.Kind != yaml.ScalarNode || !scalarMatches(s.Type, node) {
+			c.add(KindInvalidType, path, node, "expected %s, got %s", typeName(s.Type), describeNode(node))
+		}
+	}
+}
+
+// checkMapping 检查映射中的每个键，区分结构体属性和 map 的任意键
+func (c *checker) checkMapping(s *jsonschema.Schema, node *yaml.Node, path string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		// 合并键 <<: *anchor 由 yaml 展开，这里检查被引用的映射
+		if key.Tag == "!!merge" {
+			c.check(s, value, path)
+			continue
+		}
+		childPath := JoinPath(path, key.Value)
+		prop, ok := s.Properties[key.Value]
+		if !ok {
+			if s.AdditionalProperties == nil || s.AdditionalProperties.Not == nil {
+				c.check(s.AdditionalProperties, value, childPath)
+				continue
+			}
+			c.add(KindUnknownKey, childPath, key, "unknown key %q", key.Value)
+			continue
+		}
+		c.check(prop, value, childPath)
+	}
+}
+
+// scalarMatches 判断标量能否被 yaml.v3 解码为指定类型
+func scalarMatches(typ string, node *yaml.Node) bool {
+	switch typ {
+	case "integer":
+		return node.ShortTag() == "!!int"
+	case "boolean":
+		if node.ShortTag() == "!!bool" {
+			return true
+		}
+		// yaml.v3 解码到 bool 字段时仍接受 YAML 1.1 的写法
+		switch node.Value {
+		case "y", "Y", "yes", "Yes", "YES", "on", "On", "ON", "n", "N", "no", "No", "NO", "off", "Off", "OFF":
+			return node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) == 0
+		}
+		return false
+	}
+	// 任意标量都可以解码为字符串
+	return true
+}
+
+// JoinPath 拼接字段路径，包含 . 或 [ 的键（如域名）使用方括号
+func JoinPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		return fmt.Sprintf("%s[%s]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// typeName 返回schema类型的可读名称
+func typeName(typ string) string {
+	switch typ {
+	case "integer":
+		return "an integer"
+	case "boolean":
+		return "a boolean"
+	}
+	return "a " + typ
+}
+
+// describeNode 返回YAML节点的可读描述
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	return fmt.Sprintf("%q", node.Value)
+}
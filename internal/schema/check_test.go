@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Finding // 只比较 Kind、Path、Line 和 Column
+	}{
+		{
+			name:    "valid",
+			content: "global:\n  log_level: info\n  log_backups: 2\n  tls_insecure: false\nhttps:\n  - listen: [':443']\n    server_config:\n      a.example:\n        certfile: a.pem\n",
+		},
+		{
+			name:    "null values are ignored",
+			content: "global:\nhttps:\n  - forward: ~\n",
+		},
+		{
+			name:    "unknown keys",
+			content: "global:\n  log_lvl: info\nhttps:\n  - forword:\n      policy: x\nbogus: 1\n",
+			want: []Finding{
+				{Kind: KindUnknownKey, Path: "global.log_lvl", Line: 2, Column: 3},
+				{Kind: KindUnknownKey, Path: "https[0].forword", Line: 4, Column: 5},
+				{Kind: KindUnknownKey, Path: "bogus", Line: 6, Column: 1},
+			},
+		},
+		{
+			name:    "map keys are free but values are checked",
+			content: "dialer:\n  cloud: socks5://1.2.3.4:1080\nhttp:\n  - server_config:\n      a.example:\n        certfle: a.pem\n",
+			want:    []Finding{{Kind: KindUnknownKey, Path: "http[0].server_config[a.example].certfle", Line: 6, Column: 9}},
+		},
+		{
+			name:    "invalid types",
+			content: "global:\n  log_backups: two\n  tls_insecure: 'yes'\n  forbid_local_addr: yes\nhttp:\n  listen: ':80'\ncron:\n  - spec: 5\n",
+			want: []Finding{
+				{Kind: KindInvalidType, Path: "global.log_backups", Line: 2, Column: 16},
+				{Kind: KindInvalidType, Path: "global.tls_insecure", Line: 3, Column: 17},
+				{Kind: KindInvalidType, Path: "http", Line: 6, Column: 3},
+			},
+		},
+		{
+			name:    "anchors and merge keys",
+			content: "socks:\n  - forward: &fwd\n      policy: proxy_pass\n      dialr: local\n  - forward:\n      <<: *fwd\n      log: true\n",
+			want: []Finding{
+				{Kind: KindUnknownKey, Path: "socks[0].forward.dialr", Line: 4, Column: 7},
+				{Kind: KindUnknownKey, Path: "socks[1].forward.dialr", Line: 4, Column: 7},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := Check(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertFindings(t, findings, tt.want)
+		})
+	}
+
+	if _, err := Check("global: ["); err == nil {
+		t.Error("expected YAML syntax error")
+	}
+}
+
+func assertFindings(t *testing.T, got, want []Finding) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d findings, want %d: %+v", len(got), len(want), got)
+	}
+	for i, f := range got {
+		if f.Kind != want[i].Kind || f.Path != want[i].Path || f.Line != want[i].Line || f.Column != want[i].Column {
+			t.Errorf("finding %d = %+v, want %+v", i, f, want[i])
+		}
+		if f.Message == "" {
+			t.Errorf("finding %d has no message", i)
+		}
+	}
+}
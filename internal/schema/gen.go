@@ -0,0 +1,22 @@
+//go:build ignore
+
+// gen.go 重新生成 liner.schema.json，由 go generate 调用
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/bensonfx/mcp-liner/internal/schema"
+)
+
+func main() {
+	data, err := json.MarshalIndent(schema.Generate(), "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("liner.schema.json", append(data, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
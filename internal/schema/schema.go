@@ -0,0 +1,126 @@
+// Package schema 提供liner配置的JSON Schema，以及按schema检查YAML配置的功能
+//
+// schema 由 internal/config 中的结构体生成，并保存在 liner.schema.json 中；
+// 修改 config 结构体后需要运行 go generate 重新生成，测试会检查两者是否一致
+package schema
+
+//go:generate go run gen.go
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+)
+
+// ID schema 的 $id，与MCP资源URI一致
+const ID = "liner://schema"
+
+//go:embed liner.schema.json
+var embedded []byte
+
+// loaded 解析后的内置schema
+var loaded = sync.OnceValue(func() *jsonschema.Schema {
+	var s jsonschema.Schema
+	if err := json.Unmarshal(embedded, &s); err != nil {
+		panic(fmt.Sprintf("invalid embedded liner schema: %v", err))
+	}
+	return &s
+})
+
+// JSON 返回内置的liner配置schema原文
+func JSON() []byte {
+	return embedded
+}
+
+// Default 返回内置的liner配置schema，调用方不应修改返回值
+func Default() *jsonschema.Schema {
+	return loaded()
+}
+
+// Generate 根据 config.Config 的 yaml tag 生成schema，每个结构体类型放在 $defs 中并通过 $ref 引用
+func Generate() *jsonschema.Schema {
+	root := &jsonschema.Schema{
+		Schema:      "https://json-schema.org/draft/2020-12/schema",
+		ID:          ID,
+		Title:       "liner configuration",
+		Description: "Generated from the config structs of mcp-liner, which mirror liner/config.go",
+		Defs:        map[string]*jsonschema.Schema{},
+	}
+	rootType := reflect.TypeOf(config.Config{})
+	typeSchema(rootType, root.Defs)
+	root.Ref = "#/$defs/" + rootType.Name()
+	return root
+}
+
+// typeSchema 返回类型 t 对应的schema，结构体登记到 defs 中并返回 $ref
+func typeSchema(t reflect.Type, defs map[string]*jsonschema.Schema) *jsonschema.Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), defs)
+	case reflect.String:
+		return &jsonschema.Schema{Type: "string"}
+	case reflect.Bool:
+		return &jsonschema.Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &jsonschema.Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonschema.Schema{Type: "integer", Minimum: jsonschema.Ptr(0.0)}
+	case reflect.Slice:
+		return &jsonschema.Schema{Type: "array", Items: typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return &jsonschema.Schema{Type: "object", AdditionalProperties: typeSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		ref := &jsonschema.Schema{Ref: "#/$defs/" + t.Name()}
+		if _, ok := defs[t.Name()]; ok {
+			return ref
+		}
+		s := &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{},
+			// liner 使用 yaml.Unmarshal，未知的键会被静默忽略，这里显式禁止
+			AdditionalProperties: &jsonschema.Schema{Not: &jsonschema.Schema{}},
+		}
+		defs[t.Name()] = s
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := YAMLName(f)
+			if name == "" {
+				continue
+			}
+			s.Properties[name] = typeSchema(f.Type, defs)
+		}
+		return ref
+	default:
+		panic(fmt.Sprintf("unsupported config field type %s", t))
+	}
+}
+
+// YAMLName 返回结构体字段在YAML中的键名，未导出或标记为 "-" 的字段返回空串
+func YAMLName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		// 与 yaml.v3 一致，没有tag时使用小写的字段名
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
+
+// Resolve 返回 $ref 指向的定义，非引用时原样返回
+func Resolve(root, s *jsonschema.Schema) *jsonschema.Schema {
+	for s != nil && s.Ref != "" {
+		s = root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+	}
+	return s
+}
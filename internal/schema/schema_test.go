@@ -0,0 +1,41 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestEmbeddedSchemaUpToDate(t *testing.T) {
+	data, err := json.MarshalIndent(Generate(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(append(data, '\n'), JSON()) {
+		t.Error("liner.schema.json is out of date with internal/config, run go generate ./internal/schema")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate()
+
+	global := Resolve(s, s.Defs["Config"].Properties["global"])
+	if global == nil || global.Type != "object" {
+		t.Fatalf("global does not resolve to an object: %+v", global)
+	}
+	if got := global.Properties["log_level"].Type; got != "string" {
+		t.Errorf("log_level type = %q, want string", got)
+	}
+	if got := global.Properties["log_backups"].Type; got != "integer" {
+		t.Errorf("log_backups type = %q, want integer", got)
+	}
+	if global.AdditionalProperties == nil || global.AdditionalProperties.Not == nil {
+		t.Error("structs should not allow additional properties")
+	}
+
+	// server_config 是 map[string]ServerConfig，键任意，值引用结构体定义
+	serverConfig := Resolve(s, s.Defs["HTTPConfig"].Properties["server_config"])
+	if serverConfig.AdditionalProperties == nil || serverConfig.AdditionalProperties.Ref != "#/$defs/ServerConfig" {
+		t.Errorf("server_config additionalProperties = %+v", serverConfig.AdditionalProperties)
+	}
+}
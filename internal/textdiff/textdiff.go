@@ -0,0 +1,170 @@
+// Package textdiff 提供按行比较文本并输出 unified diff 的功能
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines unified diff 中每个变更块前后保留的上下文行数
+const contextLines = 3
+
+// 行操作类型
+const (
+	opEqual  = ' '
+	opDelete = '-'
+	opInsert = '+'
+)
+
+// line 比较结果中的一行
+type line struct {
+	op   byte
+	text string
+	a, b int // 该行在 a、b 中的行号（从0开始），不存在时为 -1
+}
+
+// Unified 比较 a 和 b，返回 unified diff 格式的差异，两者相同时返回空字符串
+func Unified(fromName, toName, a, b string) string {
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	for _, h := range hunks(lines) {
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		sb.WriteString(h)
+	}
+	return sb.String()
+}
+
+// splitLines 按行拆分文本，忽略末尾换行
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// diffLines 基于最长公共子序列计算逐行差异
+func diffLines(a, b []string) []line {
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, line{opEqual, a[i], i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, line{opDelete, a[i], i, -1})
+			i++
+		default:
+			lines = append(lines, line{opInsert, b[j], -1, j})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, line{opDelete, a[i], i, -1})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, line{opInsert, b[j], -1, j})
+	}
+	return lines
+}
+
+// hunks 将逐行差异分组为带上下文的变更块
+func hunks(lines []line) []string {
+	var result []string
+
+	for start := 0; start < len(lines); {
+		// 找到下一处变更
+		first := start
+		for first < len(lines) && lines[first].op == opEqual {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+
+		// 向后扩展，直到连续的相同行超过两倍上下文
+		end := first
+		for end < len(lines) {
+			if lines[end].op != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].op == opEqual {
+				run++
+			}
+			if run == len(lines) || run-end > 2*contextLines {
+				break
+			}
+			end = run
+		}
+
+		from := max(first-contextLines, start)
+		to := min(end+contextLines, len(lines))
+		result = append(result, formatHunk(lines[from:to]))
+		start = to
+	}
+
+	return result
+}
+
+// formatHunk 格式化一个变更块
+func formatHunk(lines []line) string {
+	aStart, aCount, bStart, bCount := -1, 0, -1, 0
+	for _, l := range lines {
+		if l.a >= 0 {
+			if aStart < 0 {
+				aStart = l.a
+			}
+			aCount++
+		}
+		if l.b >= 0 {
+			if bStart < 0 {
+				bStart = l.b
+			}
+			bCount++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+	for _, l := range lines {
+		sb.WriteByte(l.op)
+		sb.WriteString(l.text)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// hunkRange 返回变更块的行范围，行号从1开始
+// 变更块包含上下文，某一侧没有行只会出现在该侧文本为空时，此时行号为0
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return "0,0"
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
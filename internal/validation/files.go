@@ -0,0 +1,324 @@
+package validation
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/csv"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+)
+
+// fileChecker 检查配置引用的文件，相对路径相对于 baseDir
+// 工具可能经 http/sse 暴露给其他客户端，因此只读取 baseDir 内的普通文件，且不在结果中回显文件内容
+type fileChecker struct {
+	baseDir string
+	now     time.Time
+	result  *ValidationResult
+}
+
+// ValidateFiles 检查配置引用的文件和目录：是否存在、可读，证书与私钥是否匹配、是否过期、
+// 是否覆盖 server_name，认证表是否为合法的CSV，以及目录权限是否过于宽松
+// baseDir 为相对路径的基准目录，为空时使用当前目录；相对和绝对路径都必须位于 baseDir 内
+// 存在错误级别的发现时 result.Valid 置为 false
+func ValidateFiles(cfg *config.Config, baseDir string, result *ValidationResult) {
+	c := &fileChecker{baseDir: baseDir, now: time.Now(), result: result}
+
+	c.dir("global.geoip_dir", cfg.Global.GeoipDir, 0o002)
+	// autocert_dir 保存证书私钥，不应被其他用户读取
+	c.dir("global.autocert_dir", cfg.Global.AutocertDir, 0o077)
+
+	checkHTTP := func(section string, httpCfgs []config.HTTPConfig) {
+		for i, httpCfg := range httpCfgs {
+			prefix := fmt.Sprintf("%s[%d]", section, i)
+			c.certificate(prefix, httpCfg.Certfile, httpCfg.Keyfile, httpCfg.ServerName)
+			names := make([]string, 0, len(httpCfg.ServerConfig))
+			for name := range httpCfg.ServerConfig {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				serverCfg := httpCfg.ServerConfig[name]
+				c.certificate(fmt.Sprintf("%s.server_config[%s]", prefix, name), serverCfg.Certfile, serverCfg.Keyfile, []string{name})
+			}
+			c.authTable(prefix+".forward.auth_table", httpCfg.Forward.AuthTable)
+			c.authTable(prefix+".tunnel.auth_table", httpCfg.Tunnel.AuthTable)
+			for j, webCfg := range httpCfg.Web {
+				webPrefix := fmt.Sprintf("%s.web[%d]", prefix, j)
+				if webCfg.Dav.Enabled {
+					c.dir(webPrefix+".dav.root", webCfg.Dav.Root, 0o002)
+				}
+				if webCfg.Fastcgi.Enabled {
+					c.dir(webPrefix+".fastcgi.root", webCfg.Fastcgi.Root, 0o002)
+				}
+				c.authTable(webPrefix+".dav.auth_table", webCfg.Dav.AuthTable)
+				c.authTable(webPrefix+".proxy.auth_table", webCfg.Proxy.AuthTable)
+				c.authTable(webPrefix+".shell.auth_table", webCfg.Shell.AuthTable)
+			}
+		}
+	}
+	checkHTTP("https", cfg.Https)
+	checkHTTP("http", cfg.Http)
+
+	for i, socksCfg := range cfg.Socks {
+		c.authTable(fmt.Sprintf("socks[%d].forward.auth_table", i), socksCfg.Forward.AuthTable)
+	}
+	for i, streamCfg := range cfg.Stream {
+		c.certificate(fmt.Sprintf("stream[%d]", i), streamCfg.Certfile, streamCfg.Keyfile, nil)
+	}
+	for i, dnsCfg := range cfg.Dns {
+		c.file(fmt.Sprintf("dns[%d].keyfile", i), dnsCfg.Keyfile)
+	}
+	for i, sshCfg := range cfg.Ssh {
+		prefix := fmt.Sprintf("ssh[%d]", i)
+		if data := c.file(prefix+".host_key", sshCfg.HostKey); data != nil {
+			if block, _ := pem.Decode(data); block == nil || !strings.Contains(block.Type, "PRIVATE KEY") {
+				c.fail(prefix+".host_key", CodeInvalidHostKey, fmt.Sprintf("%s is not a PEM encoded private key", c.path(sshCfg.HostKey)))
+			}
+		}
+		c.authTable(prefix+".auth_table", sshCfg.AuthTable)
+		c.file(prefix+".authorized_keys", sshCfg.AuthorizedKeys)
+		c.file(prefix+".banner_file", sshCfg.BannerFile)
+		c.file(prefix+".env_file", sshCfg.EnvFile)
+	}
+
+	result.finalize()
+}
+
+// fail 记录一个发现，严重程度由规则决定
+func (c *fileChecker) fail(field, code, message string) {
+	c.result.Errors = append(c.result.Errors, ValidationError{Field: field, Code: code, Message: message})
+}
+
+// path 返回文件的实际路径
+func (c *fileChecker) path(name string) string {
+	if filepath.IsAbs(name) || c.baseDir == "" {
+		return name
+	}
+	return filepath.Join(c.baseDir, name)
+}
+
+// resolve 返回文件的路径，路径（及符号链接指向的目标）不在 baseDir 内时记录发现并返回 false
+// 在访问文件之前检查，不会泄露 baseDir 之外的文件是否存在
+func (c *fileChecker) resolve(field, name string) (string, bool) {
+	base := c.baseDir
+	if base == "" {
+		base = "."
+	}
+	base, err := filepath.Abs(base)
+	if err != nil {
+		c.fail(field, CodeFileOutsideBaseDir, fmt.Sprintf("cannot resolve base_dir: %v", err))
+		return "", false
+	}
+	path := c.path(name)
+	abs := filepath.Clean(name)
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(base, name)
+	}
+	if !withinDir(base, abs) {
+		c.fail(field, CodeFileOutsideBaseDir, fmt.Sprintf("%s is outside base_dir %s", path, base))
+		return "", false
+	}
+	// 符号链接可能指向 baseDir 之外；路径不存在时由调用方报告
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return path, true
+	}
+	if realBase, err := filepath.EvalSymlinks(base); err == nil && !withinDir(realBase, real) {
+		c.fail(field, CodeFileOutsideBaseDir, fmt.Sprintf("%s resolves to a path outside base_dir %s", path, base))
+		return "", false
+	}
+	return path, true
+}
+
+// withinDir 判断 path 是否为 dir 或其子路径，两者都是绝对路径
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// file 检查文件为 baseDir 内可读的普通文件，返回文件内容；路径为空或检查失败时返回 nil
+func (c *fileChecker) file(field, name string) []byte {
+	if name == "" {
+		return nil
+	}
+	path, ok := c.resolve(field, name)
+	if !ok {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		c.fail(field, CodeFileUnreadable, describeFileError(path, err))
+		return nil
+	}
+	if info.IsDir() {
+		c.fail(field, CodeFileUnreadable, fmt.Sprintf("%s is a directory, expected a file", path))
+		return nil
+	}
+	// 设备、管道等特殊文件可能阻塞读取或产生无限的数据
+	if !info.Mode().IsRegular() {
+		c.fail(field, CodeFileUnreadable, fmt.Sprintf("%s is not a regular file", path))
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.fail(field, CodeFileUnreadable, describeFileError(path, err))
+		return nil
+	}
+	return data
+}
+
+// dir 检查目录存在，且权限不包含 looseBits 中的任何位
+func (c *fileChecker) dir(field, name string, looseBits fs.FileMode) {
+	if name == "" {
+		return
+	}
+	path, ok := c.resolve(field, name)
+	if !ok {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		c.fail(field, CodeFileUnreadable, describeFileError(path, err))
+		return
+	}
+	if !info.IsDir() {
+		c.fail(field, CodeFileUnreadable, fmt.Sprintf("%s is not a directory", path))
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		c.fail(field, CodeFileUnreadable, describeFileError(path, err))
+		return
+	}
+	f.Close()
+	if perm := info.Mode().Perm(); perm&looseBits != 0 {
+		c.fail(field, CodeLoosePermissions, fmt.Sprintf("%s has permissions %04o which are too loose, remove %04o (e.g. chmod %04o %s)",
+			path, perm, perm&looseBits, perm&^looseBits, path))
+	}
+}
+
+// certificate 检查证书和私钥：PEM格式、是否匹配、有效期，以及是否覆盖 serverNames
+func (c *fileChecker) certificate(prefix, certfile, keyfile string, serverNames []string) {
+	certPEM := c.file(prefix+".certfile", certfile)
+	keyPEM := c.file(prefix+".keyfile", keyfile)
+	if certPEM == nil {
+		return
+	}
+
+	leaf, err := parseLeafCertificate(certPEM)
+	if err != nil {
+		c.fail(prefix+".certfile", CodeInvalidCertificate, fmt.Sprintf("%s: %v", c.path(certfile), err))
+		return
+	}
+
+	if keyPEM != nil {
+		if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+			c.fail(prefix+".keyfile", CodeKeyMismatch, fmt.Sprintf("%s does not match certificate %s: %v", c.path(keyfile), c.path(certfile), err))
+		}
+	}
+
+	switch {
+	case c.now.After(leaf.NotAfter):
+		c.fail(prefix+".certfile", CodeCertificateValidity, fmt.Sprintf("certificate %s expired on %s", c.path(certfile), leaf.NotAfter.Format(time.RFC3339)))
+	case c.now.Before(leaf.NotBefore):
+		c.fail(prefix+".certfile", CodeCertificateValidity, fmt.Sprintf("certificate %s is not valid until %s", c.path(certfile), leaf.NotBefore.Format(time.RFC3339)))
+	}
+
+	for _, name := range serverNames {
+		host := name
+		// 通配符 server_name 用任意子域名检查
+		if strings.HasPrefix(host, "*.") {
+			host = "wildcard-check" + host[1:]
+		}
+		if err := leaf.VerifyHostname(host); err != nil {
+			c.fail(prefix+".certfile", CodeCertificateName, fmt.Sprintf("certificate %s does not cover server_name %q (SANs: %s)", c.path(certfile), name, strings.Join(leaf.DNSNames, ", ")))
+		}
+	}
+}
+
+// authTable 检查认证表为合法的CSV，首行包含 username 和 password 列，且每行都有用户名
+// 以 URL 形式给出的认证表不检查；发现中只报告缺少的列和行号，不包含表的内容
+func (c *fileChecker) authTable(field, name string) {
+	if name == "" || strings.Contains(name, "://") {
+		return
+	}
+	data := c.file(field, name)
+	if data == nil {
+		return
+	}
+
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.Comment = '#'
+	header, err := reader.Read()
+	if err == io.EOF {
+		c.fail(field, CodeInvalidAuthTable, fmt.Sprintf("%s is empty, expected a header line such as username,password", c.path(name)))
+		return
+	}
+	if err != nil {
+		c.fail(field, CodeInvalidAuthTable, fmt.Sprintf("%s is not valid CSV: %v", c.path(name), err))
+		return
+	}
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		columns[strings.TrimSpace(h)] = i
+	}
+	for _, col := range []string{"username", "password"} {
+		if _, ok := columns[col]; !ok {
+			c.fail(field, CodeInvalidAuthTable, fmt.Sprintf("%s: header has no %s column", c.path(name), col))
+			return
+		}
+	}
+	usernameCol := columns["username"]
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			c.fail(field, CodeInvalidAuthTable, fmt.Sprintf("%s is not valid CSV: %v", c.path(name), err))
+			return
+		}
+		if strings.TrimSpace(record[usernameCol]) == "" {
+			line, _ := reader.FieldPos(0)
+			c.fail(field, CodeInvalidAuthTable, fmt.Sprintf("%s: line %d has an empty username", c.path(name), line))
+			return
+		}
+	}
+}
+
+// parseLeafCertificate 解析PEM文件中的第一张证书
+func parseLeafCertificate(data []byte) (*x509.Certificate, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no PEM encoded certificate found")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// describeFileError 将文件访问错误转换为可读的说明
+func describeFileError(path string, err error) string {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Sprintf("%s does not exist", path)
+	case errors.Is(err, fs.ErrPermission):
+		return fmt.Sprintf("%s is not readable: permission denied", path)
+	default:
+		return fmt.Sprintf("%s: %v", path, err)
+	}
+}
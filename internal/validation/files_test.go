@@ -0,0 +1,251 @@
+package validation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+)
+
+// writeCert 生成自签名证书和私钥，返回证书和私钥的PEM内容
+func writeCert(t *testing.T, dnsNames []string, notBefore, notAfter time.Time) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestValidateFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	write := func(name string, data []byte) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mkdir := func(name string, perm os.FileMode) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.Mkdir(path, perm); err != nil {
+			t.Fatal(err)
+		}
+		// 绕过 umask
+		if err := os.Chmod(path, perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cert, key := writeCert(t, []string{"example.org", "*.example.org"}, now.Add(-time.Hour), now.Add(24*time.Hour))
+	write("cert.pem", cert)
+	write("key.pem", key)
+	_, otherKey := writeCert(t, []string{"other.org"}, now.Add(-time.Hour), now.Add(24*time.Hour))
+	write("other_key.pem", otherKey)
+	expired, expiredKey := writeCert(t, []string{"example.org"}, now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+	write("expired.pem", expired)
+	write("expired_key.pem", expiredKey)
+	write("not_pem.txt", []byte("hello\n"))
+	write("auth.csv", []byte("username,password,speed_limit\nalice,secret,0\n# comment\nbob,pass,-1\n"))
+	write("auth_bad_header.csv", []byte("user,pass\nalice,secret\n"))
+	write("auth_bad_fields.csv", []byte("username,password\nalice,secret,extra\n"))
+	write("auth_empty_user.csv", []byte("username,password\nalice,secret\n,nopass\n"))
+	write("banner.txt", []byte("welcome\n"))
+	write("shadow", []byte("root:$6$SECRETHASH:19000:0:99999:7:::\n"))
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.csv"), []byte("username,password\nroot,secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.csv"), filepath.Join(dir, "link.csv")); err != nil {
+		t.Fatal(err)
+	}
+	// 非普通文件: unix socket
+	ln, err := net.Listen("unix", filepath.Join(dir, "sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	mkdir("geoip", 0o755)
+	mkdir("autocert", 0o700)
+	mkdir("autocert_loose", 0o755)
+	mkdir("webdav_loose", 0o777)
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		// want 为 字段 -> 错误信息片段
+		want map[string]string
+		// warningsOnly 表示 want 中都是警告，配置仍然有效
+		warningsOnly bool
+	}{
+		{
+			name: "all files valid",
+			cfg: &config.Config{
+				Global: config.GlobalConfig{GeoipDir: "geoip", AutocertDir: filepath.Join(dir, "autocert")},
+				Https: []config.HTTPConfig{{
+					ServerName: []string{"example.org", "www.example.org", "*.example.org"},
+					Certfile:   "cert.pem",
+					Keyfile:    "key.pem",
+					Forward:    config.HTTPForwardConfig{AuthTable: "auth.csv"},
+					Tunnel:     config.HTTPTunnelConfig{AuthTable: "https://auth.example.org/users.csv"},
+				}},
+				Ssh: []config.SshConfig{{HostKey: "key.pem", AuthTable: "auth.csv", BannerFile: "banner.txt"}},
+			},
+		},
+		{
+			name: "missing files and directories",
+			cfg: &config.Config{
+				Global: config.GlobalConfig{GeoipDir: "missing_geoip"},
+				Stream: []config.StreamConfig{{Certfile: "missing.pem", Keyfile: "geoip"}},
+				Ssh:    []config.SshConfig{{AuthorizedKeys: "missing_keys", EnvFile: "cert.pem"}},
+			},
+			want: map[string]string{
+				"global.geoip_dir":       "missing_geoip does not exist",
+				"stream[0].certfile":     "missing.pem does not exist",
+				"stream[0].keyfile":      "is a directory, expected a file",
+				"ssh[0].authorized_keys": "missing_keys does not exist",
+			},
+		},
+		{
+			name: "certificate problems",
+			cfg: &config.Config{
+				Https: []config.HTTPConfig{
+					{ServerName: []string{"example.org", "example.com"}, Certfile: "cert.pem", Keyfile: "other_key.pem"},
+					{ServerName: []string{"example.org"}, Certfile: "expired.pem", Keyfile: "expired_key.pem"},
+					{ServerName: []string{"example.org"}, Certfile: "not_pem.txt"},
+				},
+			},
+			want: map[string]string{
+				"https[0].keyfile":  "does not match certificate",
+				"https[0].certfile": `does not cover server_name "example.com" (SANs: example.org, *.example.org)`,
+				"https[1].certfile": "expired on",
+				"https[2].certfile": "no PEM encoded certificate found",
+			},
+		},
+		{
+			name: "server_config certificate",
+			cfg: &config.Config{
+				Http: []config.HTTPConfig{{
+					ServerConfig: map[string]config.ServerConfig{"api.example.com": {Certfile: "cert.pem", Keyfile: "key.pem"}},
+				}},
+			},
+			want: map[string]string{"http[0].server_config[api.example.com].certfile": `does not cover server_name "api.example.com"`},
+		},
+		{
+			name: "auth tables",
+			cfg: &config.Config{
+				Socks: []config.SocksConfig{
+					{Forward: config.SocksForwardConfig{AuthTable: "auth_bad_header.csv"}},
+					{Forward: config.SocksForwardConfig{AuthTable: "auth_bad_fields.csv"}},
+					{Forward: config.SocksForwardConfig{AuthTable: "auth_empty_user.csv"}},
+				},
+			},
+			want: map[string]string{
+				"socks[0].forward.auth_table": "auth_bad_header.csv: header has no username column",
+				"socks[1].forward.auth_table": "is not valid CSV",
+				"socks[2].forward.auth_table": "line 3 has an empty username",
+			},
+		},
+		{
+			name: "file contents are not echoed",
+			cfg: &config.Config{
+				Socks: []config.SocksConfig{{Forward: config.SocksForwardConfig{AuthTable: "shadow"}}},
+			},
+			want: map[string]string{"socks[0].forward.auth_table": "shadow: header has no username column"},
+		},
+		{
+			name: "paths are confined to base_dir",
+			cfg: &config.Config{
+				Global: config.GlobalConfig{GeoipDir: outside},
+				Socks: []config.SocksConfig{
+					{Forward: config.SocksForwardConfig{AuthTable: filepath.Join(outside, "secret.csv")}},
+					{Forward: config.SocksForwardConfig{AuthTable: "../secret.csv"}},
+					{Forward: config.SocksForwardConfig{AuthTable: "link.csv"}},
+					{Forward: config.SocksForwardConfig{AuthTable: "sock"}},
+				},
+			},
+			want: map[string]string{
+				"global.geoip_dir":            "is outside base_dir",
+				"socks[0].forward.auth_table": "is outside base_dir",
+				"socks[1].forward.auth_table": "is outside base_dir",
+				"socks[2].forward.auth_table": "resolves to a path outside base_dir",
+				"socks[3].forward.auth_table": "is not a regular file",
+			},
+		},
+		{
+			name: "loose permissions are warnings",
+			cfg: &config.Config{
+				Global: config.GlobalConfig{GeoipDir: "webdav_loose"},
+			},
+			want:         map[string]string{"global.geoip_dir": "permissions 0777 which are too loose"},
+			warningsOnly: true,
+		},
+		{
+			name: "loose permissions and bad host key",
+			cfg: &config.Config{
+				Global: config.GlobalConfig{AutocertDir: "autocert_loose"},
+				Https: []config.HTTPConfig{{Web: []config.HTTPWebConfig{
+					{Dav: config.HTTPWebDavConfig{Enabled: true, Root: "webdav_loose"}},
+					{Fastcgi: config.HTTPWebFastcgiConfig{Enabled: true, Root: "banner.txt"}},
+				}}},
+				Ssh: []config.SshConfig{{HostKey: "not_pem.txt"}},
+			},
+			want: map[string]string{
+				"global.autocert_dir":          "permissions 0755 which are too loose, remove 0055",
+				"https[0].web[0].dav.root":     "permissions 0777 which are too loose, remove 0002",
+				"https[0].web[1].fastcgi.root": "is not a directory",
+				"ssh[0].host_key":              "is not a PEM encoded private key",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &ValidationResult{Valid: true}
+			ValidateFiles(tt.cfg, dir, result)
+			if len(result.Errors) != len(tt.want) {
+				t.Fatalf("got %d errors, want %d: %v", len(result.Errors), len(tt.want), result.Errors)
+			}
+			for _, err := range result.Errors {
+				want, ok := tt.want[err.Field]
+				if !ok || !strings.Contains(err.Message, want) {
+					t.Errorf("unexpected error %s: %s (want %q)", err.Field, err.Message, want)
+				}
+				if strings.Contains(err.Message, "SECRET") || strings.Contains(err.Message, "root,secret") {
+					t.Errorf("%s echoes file contents: %s", err.Field, err.Message)
+				}
+			}
+			if wantValid := len(tt.want) == 0 || tt.warningsOnly; result.Valid != wantValid {
+				t.Errorf("Valid = %v, want %v", result.Valid, wantValid)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package validation
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+)
+
+// validateListenAddresses 检查所有监听地址的格式以及地址、端口冲突
+func validateListenAddresses(cfg *config.Config, result *ValidationResult) {
+	for i, httpsCfg := range cfg.Https {
+		validateAllowListens(httpsCfg.Tunnel.AllowListens, fmt.Sprintf("https[%d].tunnel.allow_listens", i), result)
+	}
+	for i, httpCfg := range cfg.Http {
+		validateAllowListens(httpCfg.Tunnel.AllowListens, fmt.Sprintf("http[%d].tunnel.allow_listens", i), result)
+	}
+
+	listeners, errs := config.Listeners(cfg)
+	addListenErrors(errs, result)
+
+	// 每对冲突只报告一次，报告在后出现的监听上
+	for i := range listeners {
+		for j := 0; j < i; j++ {
+			a, b := listeners[j], listeners[i]
+			proto := a.Conflict(b)
+			if proto == "" {
+				continue
+			}
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   b.Field,
+				Code:    CodeListenConflict,
+				Message: fmt.Sprintf("listen address %q conflicts with %s (%q) on %s port %d", b.Addr, a.Field, a.Addr, proto, b.Port),
+			})
+		}
+	}
+
+	validateRemoteListens(cfg, result)
+}
+
+// validateRemoteListens 检查隧道 remote_listen 的格式，以及经同一 dialer（即同一服务端）时的冲突
+func validateRemoteListens(cfg *config.Config, result *ValidationResult) {
+	remotes, errs := config.RemoteListeners(cfg)
+	addListenErrors(errs, result)
+
+	for i := range remotes {
+		for j := 0; j < i; j++ {
+			a, b := remotes[j], remotes[i]
+			if !a.Conflict(b) {
+				continue
+			}
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   b.Field,
+				Code:    CodeRemoteListenConflict,
+				Message: fmt.Sprintf("remote_listen %q conflicts with %s (%q) on the server reached through dialer '%s'", b.Addr, a.Field, a.Addr, b.Dialer),
+			})
+		}
+	}
+}
+
+// addListenErrors 将无法解析的监听地址加入验证结果
+func addListenErrors(errs []config.ListenError, result *ValidationResult) {
+	for _, e := range errs {
+		result.Errors = append(result.Errors, ValidationError{Field: e.Field, Code: CodeInvalidListenAddress, Message: e.Err.Error()})
+	}
+}
+
+// validateAllowListens 检查 tunnel.allow_listens，每项为IP或CIDR
+func validateAllowListens(allowListens []string, field string, result *ValidationResult) {
+	for i, allow := range allowListens {
+		if _, err := netip.ParseAddr(allow); err == nil {
+			continue
+		}
+		if _, err := netip.ParsePrefix(allow); err == nil {
+			continue
+		}
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   fmt.Sprintf("%s[%d]", field, i),
+			Code:    CodeInvalidAllowListens,
+			Message: fmt.Sprintf("invalid allow_listens entry %q, must be an IP address or CIDR", allow),
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+)
+
+func TestValidateListenAddresses(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		// want 为 字段 -> 错误信息片段
+		want map[string]string
+	}{
+		{
+			name: "distinct ports",
+			cfg: &config.Config{
+				Https: []config.HTTPConfig{{Listen: []string{":443"}, ServerName: []string{"a.example"}}},
+				Socks: []config.SocksConfig{{Listen: []string{":1080"}}},
+				Dns:   []config.DnsConfig{{Listen: []string{":53"}}},
+			},
+		},
+		{
+			name: "https and stream on same port",
+			cfg: &config.Config{
+				Https:  []config.HTTPConfig{{Listen: []string{":443"}, ServerName: []string{"a.example"}}},
+				Stream: []config.StreamConfig{{Listen: []string{":8443"}}, {Listen: []string{"0.0.0.0:443"}}},
+			},
+			want: map[string]string{"stream[1].listen[0]": `"0.0.0.0:443" conflicts with https[0].listen[0] (":443") on tcp port 443`},
+		},
+		{
+			name: "socks shadows dns tcp",
+			cfg: &config.Config{
+				Dns:   []config.DnsConfig{{Listen: []string{":53"}}},
+				Socks: []config.SocksConfig{{Listen: []string{"127.0.0.1:53"}}},
+			},
+			want: map[string]string{"dns[0].listen[0]": `conflicts with socks[0].listen[0] ("127.0.0.1:53") on tcp port 53`},
+		},
+		{
+			name: "udp-only dns does not conflict with tcp",
+			cfg: &config.Config{
+				Dns:   []config.DnsConfig{{Listen: []string{"udp://:53"}}},
+				Socks: []config.SocksConfig{{Listen: []string{":53"}}},
+			},
+		},
+		{
+			name: "http3 conflicts with udp dns",
+			cfg: &config.Config{
+				Https: []config.HTTPConfig{{Listen: []string{":443"}, ServerName: []string{"a.example"}}},
+				Dns:   []config.DnsConfig{{Listen: []string{"udp://:443"}}},
+			},
+			want: map[string]string{"dns[0].listen[0]": "on udp port 443"},
+		},
+		{
+			name: "http3 disabled",
+			cfg: &config.Config{
+				Global: config.GlobalConfig{DisableHttp3: true},
+				Https:  []config.HTTPConfig{{Listen: []string{":443"}, ServerName: []string{"a.example"}}},
+				Dns:    []config.DnsConfig{{Listen: []string{"udp://:443"}}},
+			},
+		},
+		{
+			name: "specific binds on different addresses",
+			cfg: &config.Config{
+				Http:  []config.HTTPConfig{{Listen: []string{"127.0.0.1:8080"}}},
+				Socks: []config.SocksConfig{{Listen: []string{"10.0.0.1:8080", "[::1]:8080"}}},
+			},
+		},
+		{
+			name: "ipv6 wildcard covers ipv4",
+			cfg: &config.Config{
+				Http:  []config.HTTPConfig{{Listen: []string{"[::]:8080"}}},
+				Socks: []config.SocksConfig{{Listen: []string{"127.0.0.1:8080"}}},
+			},
+			want: map[string]string{"socks[0].listen[0]": `conflicts with http[0].listen[0] ("[::]:8080")`},
+		},
+		{
+			name: "ipv4 wildcard does not cover ipv6",
+			cfg: &config.Config{
+				Http:  []config.HTTPConfig{{Listen: []string{"0.0.0.0:8080"}}},
+				Socks: []config.SocksConfig{{Listen: []string{"[::1]:8080"}}},
+			},
+		},
+		{
+			name: "https sharing port via SNI",
+			cfg: &config.Config{
+				Https: []config.HTTPConfig{
+					{Listen: []string{":443"}, ServerName: []string{"a.example", "b.example"}},
+					{Listen: []string{":443"}, ServerName: []string{"C.example"}},
+				},
+			},
+		},
+		{
+			name: "https with overlapping server_name",
+			cfg: &config.Config{
+				Https: []config.HTTPConfig{
+					{Listen: []string{":443"}, ServerName: []string{"a.example", "b.example"}},
+					{Listen: []string{":443"}, ServerName: []string{"B.example"}},
+				},
+			},
+			want: map[string]string{"https[1].listen[0]": "conflicts with https[0].listen[0]"},
+		},
+		{
+			name: "duplicate in one list",
+			cfg:  &config.Config{Ssh: []config.SshConfig{{Listen: []string{":2022", ":2022"}}}},
+			want: map[string]string{"ssh[0].listen[1]": "conflicts with ssh[0].listen[0]"},
+		},
+		{
+			name: "malformed addresses",
+			cfg: &config.Config{
+				Http:     []config.HTTPConfig{{Listen: []string{":99999"}}},
+				Redsocks: []config.RedsocksConfig{{Listen: []string{"8080"}}},
+				Dns:      []config.DnsConfig{{Listen: []string{"quic://:853"}}},
+			},
+			want: map[string]string{
+				"http[0].listen[0]":     `invalid port "99999"`,
+				"redsocks[0].listen[0]": `invalid listen address "8080"`,
+				"dns[0].listen[0]":      `unsupported scheme "quic"`,
+			},
+		},
+		{
+			name: "allow_listens",
+			cfg: &config.Config{
+				Https: []config.HTTPConfig{{
+					Listen:     []string{":443"},
+					ServerName: []string{"a.example"},
+					Tunnel:     config.HTTPTunnelConfig{Enabled: true, AllowListens: []string{"127.0.0.1", "10.0.0.0/8", "localhost"}},
+				}},
+			},
+			want: map[string]string{"https[0].tunnel.allow_listens[2]": `invalid allow_listens entry "localhost"`},
+		},
+		{
+			name: "tunnel remote_listen through the same dialer",
+			cfg: &config.Config{
+				Tunnel: []config.TunnelConfig{
+					{RemoteListen: []string{"127.0.0.1:10022"}, Dialer: "a"},
+					{RemoteListen: []string{"127.0.0.1:10022"}, Dialer: "b"},
+					{RemoteListen: []string{":10022"}, Dialer: "a"},
+					{RemoteListen: []string{"127.0.0.1:0"}, Dialer: "a"},
+				},
+			},
+			want: map[string]string{
+				"tunnel[2].remote_listen[0]": `conflicts with tunnel[0].remote_listen[0] ("127.0.0.1:10022") on the server reached through dialer 'a'`,
+				"tunnel[3].remote_listen[0]": `invalid port "0"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &ValidationResult{Valid: true}
+			validateListenAddresses(tt.cfg, result)
+			if len(result.Errors) != len(tt.want) {
+				t.Fatalf("got %d errors, want %d: %v", len(result.Errors), len(tt.want), result.Errors)
+			}
+			for _, err := range result.Errors {
+				want, ok := tt.want[err.Field]
+				if !ok || !strings.Contains(err.Message, want) {
+					t.Errorf("unexpected error %s: %s (want %q)", err.Field, err.Message, want)
+				}
+			}
+		})
+	}
+}
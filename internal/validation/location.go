@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ignorePattern 匹配忽略注释：# mcp-liner:ignore code1,code2
+// 独占一行时对整个配置生效，写在值后面时只对该行的发现生效
+var ignorePattern = regexp.MustCompile(`#\s*mcp-liner:ignore\s+([\w\s,-]+)`)
+
+// Annotate 根据YAML原文为每条发现补充行列位置，并移除被忽略注释覆盖的发现
+// content 应为验证时使用的同一份配置，无法解析时只应用忽略注释
+func Annotate(result *ValidationResult, content string) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &root); err == nil && len(root.Content) > 0 {
+		for i := range result.Errors {
+			// 按schema检查的发现自带位置，合并键中的字段无法按路径定位
+			if e := &result.Errors[i]; e.Line == 0 {
+				e.Line, e.Column = locateField(root.Content[0], e.Field)
+			}
+		}
+	}
+
+	global, perLine := parseIgnoreComments(content)
+	if len(global) == 0 && len(perLine) == 0 {
+		return
+	}
+	kept := result.Errors[:0]
+	for _, e := range result.Errors {
+		if global[e.Code] || (e.Line > 0 && perLine[e.Line][e.Code]) {
+			result.Suppressed++
+			continue
+		}
+		kept = append(kept, e)
+	}
+	result.Errors = kept
+	result.finalize()
+}
+
+// parseIgnoreComments 解析忽略注释，返回全局忽略的代码和按行号忽略的代码
+func parseIgnoreComments(content string) (map[string]bool, map[int]map[string]bool) {
+	global := map[string]bool{}
+	perLine := map[int]map[string]bool{}
+	for i, line := range strings.Split(content, "\n") {
+		m := ignorePattern.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		codes := global
+		if strings.TrimSpace(line[:m[0]]) != "" {
+			codes = map[string]bool{}
+			perLine[i+1] = codes
+		}
+		for _, code := range strings.FieldsFunc(line[m[2]:m[3]], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			codes[code] = true
+		}
+	}
+	return global, perLine
+}
+
+// locateField 返回字段路径（如 https[0].forward.policy）在YAML中的行列位置
+// 字段不存在时返回最近的已存在的上级位置，都不存在时返回0
+func locateField(node *yaml.Node, field string) (line, column int) {
+	for _, part := range splitFieldPath(field) {
+		key, value := childNode(node, part)
+		if value == nil {
+			break
+		}
+		line, column = key.Line, key.Column
+		node = value
+	}
+	return line, column
+}
+
+// childNode 在映射中按键、在序列中按下标查找子节点，返回用于定位的节点和值节点
+func childNode(node *yaml.Node, part string) (*yaml.Node, *yaml.Node) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == part {
+				value := node.Content[i+1]
+				if value.Kind == yaml.AliasNode {
+					value = value.Alias
+				}
+				return node.Content[i], value
+			}
+		}
+	case yaml.SequenceNode:
+		if idx, err := strconv.Atoi(part); err == nil && idx >= 0 && idx < len(node.Content) {
+			return node.Content[idx], node.Content[idx]
+		}
+	}
+	return nil, nil
+}
+
+// splitFieldPath 将字段路径拆分为键和下标，方括号中的内容（下标或映射键）作为一段
+func splitFieldPath(field string) []string {
+	var parts []string
+	for field != "" {
+		switch field[0] {
+		case '.':
+			field = field[1:]
+		case '[':
+			end := strings.IndexByte(field, ']')
+			if end < 0 {
+				return append(parts, field[1:])
+			}
+			parts = append(parts, field[1:end])
+			field = field[end+1:]
+		default:
+			end := strings.IndexAny(field, ".[")
+			if end < 0 {
+				end = len(field)
+			}
+			parts = append(parts, field[:end])
+			field = field[end:]
+		}
+	}
+	return parts
+}
@@ -0,0 +1,67 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+)
+
+func TestSplitFieldPath(t *testing.T) {
+	tests := map[string][]string{
+		"global.log_level":                          {"global", "log_level"},
+		"https[0].forward.policy":                   {"https", "0", "forward", "policy"},
+		"https[1].server_config[a.example].keyfile": {"https", "1", "server_config", "a.example", "keyfile"},
+		"dialer.cloud":                              {"dialer", "cloud"},
+	}
+	for field, want := range tests {
+		if got := splitFieldPath(field); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitFieldPath(%q) = %q, want %q", field, got, want)
+		}
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	content := `# mcp-liner:ignore plain-dns-server
+global:
+    log_level: loud
+    dns_server: 8.8.8.8:53
+    tls_insecure: true # mcp-liner:ignore tls-insecure
+    dial_read_buffer: 65536
+https:
+    - listen: [':443']
+      forward:
+          policy: proxy_pass
+`
+	cfg, err := config.FromYAML(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := ValidateConfig(cfg)
+	Annotate(result, content)
+
+	type finding struct {
+		code         string
+		line, column int
+	}
+	want := map[string]finding{
+		"global.log_level":        {CodeInvalidLogLevel, 3, 5},
+		"global.dial_read_buffer": {CodeDialReadBuffer, 6, 5},
+		"https[0].server_name":    {CodeRequiredField, 8, 7},
+		"https[0].forward":        {CodeLocalAddrAllowed, 9, 7},
+	}
+	if len(result.Errors) != len(want) {
+		t.Fatalf("got %d findings, want %d: %v", len(result.Errors), len(want), result.Errors)
+	}
+	for _, e := range result.Errors {
+		if got := (finding{e.Code, e.Line, e.Column}); got != want[e.Field] {
+			t.Errorf("%s: got %+v, want %+v", e.Field, got, want[e.Field])
+		}
+	}
+	if result.Suppressed != 2 {
+		t.Errorf("Suppressed = %d, want 2", result.Suppressed)
+	}
+	if result.Valid {
+		t.Error("invalid log level should still fail validation")
+	}
+}
@@ -0,0 +1,258 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/bensonfx/mcp-liner/internal/dialer"
+)
+
+// linerPolicyFuncs liner 在 policy 模板中额外注册的函数
+var linerPolicyFuncs = []string{
+	"country", "geoip", "geosite", "dnsResolve", "domain", "host", "hasIPv6",
+	"ipInt", "ipRange", "isInNet",
+	"hasPrefixes", "hasSuffixes", "regexMatch", "wildcardMatch",
+	"inFileLine", "inFileIPSet", "readFile",
+	"fetch", "greased",
+	"get", "set", "unset", "hasKey",
+}
+
+// sprigPolicyFuncs liner 通过 slim-sprig 提供的函数
+var sprigPolicyFuncs = []string{
+	// 日期
+	"ago", "date", "dateInZone", "dateModify", "date_in_zone", "date_modify", "duration", "durationRound",
+	"htmlDate", "htmlDateInZone", "mustDateModify", "must_date_modify", "mustToDate", "now", "toDate", "unixEpoch",
+	// 字符串
+	"abbrev", "abbrevboth", "trunc", "trim", "trimAll", "trimall", "trimPrefix", "trimSuffix",
+	"upper", "lower", "title", "untitle", "substr", "repeat", "nospace", "initials", "wrap", "wrapWith",
+	"contains", "hasPrefix", "hasSuffix", "quote", "squote", "cat", "indent", "nindent", "replace", "plural",
+	"snakecase", "camelcase", "kebabcase", "swapcase", "shuffle", "toString", "toStrings",
+	"split", "splitList", "splitn", "join", "sortAlpha",
+	// 类型转换与数学
+	"atoi", "int", "int64", "float64", "seq", "toDecimal", "until", "untilStep",
+	"add", "add1", "sub", "mul", "div", "mod", "max", "min", "biggest", "ceil", "floor", "round", "randInt",
+	"addf", "add1f", "subf", "mulf", "divf", "maxf", "minf",
+	// 默认值与流程
+	"default", "empty", "coalesce", "all", "any", "compact", "mustCompact", "ternary", "fail",
+	"fromJson", "toJson", "toPrettyJson", "toRawJson", "mustFromJson", "mustToJson", "mustToPrettyJson", "mustToRawJson",
+	"deepCopy", "mustDeepCopy",
+	// 反射
+	"typeOf", "typeIs", "typeIsLike", "kindOf", "kindIs", "deepEqual",
+	// 系统、路径、编码、加密
+	"env", "expandenv", "getHostByName",
+	"base", "dir", "clean", "ext", "isAbs", "osBase", "osClean", "osDir", "osExt", "osIsAbs",
+	"b64enc", "b64dec", "b32enc", "b32dec",
+	"sha1sum", "sha256sum", "adler32sum", "uuidv4",
+	"derivePassword", "genPrivateKey", "buildCustomCert", "htpasswd", "semver", "semverCompare",
+	// 列表与字典
+	"tuple", "list", "dict", "pluck", "keys", "pick", "omit", "values", "dig", "merge", "mergeOverwrite",
+	"append", "push", "mustAppend", "mustPush", "prepend", "mustPrepend", "first", "mustFirst",
+	"rest", "mustRest", "last", "mustLast", "initial", "mustInitial", "reverse", "mustReverse",
+	"uniq", "mustUniq", "without", "mustWithout", "has", "mustHas", "slice", "mustSlice",
+	"concat", "chunk", "mustChunk",
+	// 正则与URL
+	"regexFind", "regexFindAll", "regexReplaceAll", "regexReplaceAllLiteral", "regexSplit", "regexQuoteMeta",
+	"mustRegexMatch", "mustRegexFind", "mustRegexFindAll", "mustRegexReplaceAll", "mustRegexReplaceAllLiteral", "mustRegexSplit",
+	"urlParse", "urlJoin",
+}
+
+// PolicyActions liner forward policy 的特殊返回值，不是拨号器名称
+var PolicyActions = []string{
+	"proxy_pass", "direct", "deny", "reject", "reset", "close",
+	"generate_204", "require_auth", "bypass_auth", "forward",
+}
+
+// maxUnknownFuncs 单个模板最多报告的未知函数数量
+const maxUnknownFuncs = 10
+
+var (
+	// templateErrorPattern 匹配 text/template 的解析错误：template: <name>:<line>: <message>
+	templateErrorPattern = regexp.MustCompile(`^template: .*?:(\d+): (.*)$`)
+	// undefinedFuncPattern 匹配未定义函数的错误信息
+	undefinedFuncPattern = regexp.MustCompile(`^function "([^"]+)" not defined$`)
+)
+
+// TemplateError policy 模板中的一处错误，Line、Column 从1开始，无法确定时为0
+type TemplateError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e TemplateError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	default:
+		return e.Message
+	}
+}
+
+// PolicyFuncMap 返回与 liner 一致的 policy 模板函数表，函数只用于解析检查，不能执行
+func PolicyFuncMap() template.FuncMap {
+	stub := func(...any) any { return nil }
+	funcs := make(template.FuncMap, len(linerPolicyFuncs)+len(sprigPolicyFuncs))
+	for _, name := range sprigPolicyFuncs {
+		funcs[name] = stub
+	}
+	for _, name := range linerPolicyFuncs {
+		funcs[name] = stub
+	}
+	return funcs
+}
+
+// CheckPolicyTemplate 按 liner 的方式解析 policy 模板，返回语法错误和未知函数
+// 未知函数会逐个报告，其余语法错误只报告第一处
+func CheckPolicyTemplate(policy string) []TemplateError {
+	funcs := PolicyFuncMap()
+	var errs []TemplateError
+
+	for len(errs) < maxUnknownFuncs {
+		_, err := template.New("policy").Funcs(funcs).Parse(policy)
+		if err == nil {
+			break
+		}
+
+		tplErr := parseTemplateError(err)
+		m := undefinedFuncPattern.FindStringSubmatch(tplErr.Message)
+		if m == nil {
+			errs = append(errs, tplErr)
+			break
+		}
+		// 定位函数名所在的列，并补上该函数后继续解析，以便报告所有未知函数
+		tplErr.Column = identifierColumn(policy, tplErr.Line, m[1])
+		tplErr.Message = fmt.Sprintf("unknown function %q", m[1])
+		errs = append(errs, tplErr)
+		funcs[m[1]] = func(...any) any { return nil }
+	}
+
+	return errs
+}
+
+// parseTemplateError 从 text/template 的错误中提取行号和错误信息
+func parseTemplateError(err error) TemplateError {
+	msg := err.Error()
+	m := templateErrorPattern.FindStringSubmatch(msg)
+	if m == nil {
+		return TemplateError{Message: msg}
+	}
+	line, _ := strconv.Atoi(m[1])
+	return TemplateError{Line: line, Message: m[2]}
+}
+
+// identifierColumn 返回标识符在指定行中第一次作为完整单词出现的列号，找不到时返回0
+func identifierColumn(text string, line int, ident string) int {
+	lines := strings.Split(text, "\n")
+	if line < 1 || line > len(lines) {
+		return 0
+	}
+	pattern := regexp.MustCompile(`(^|[^\w.$])` + regexp.QuoteMeta(ident) + `\b`)
+	loc := pattern.FindStringSubmatchIndex(lines[line-1])
+	if loc == nil {
+		return 0
+	}
+	// loc[3] 为前导字符分组的结束位置，即标识符的起始位置
+	return loc[3] + 1
+}
+
+// policyDialerNames 返回 policy 模板各输出分支中以文本形式写出的拨号器名称，按出现顺序去重
+// 支持纯名称、JSON（{"dialer":"x"}）和查询串（dialer=x&...）三种输出格式；
+// 只有整个分支的输出是一段文本时才视为拨号器名称，文本与模板动作拼接的分支
+// （如 cloud-{{ .Region }}）以及动态生成的名称无法静态确定，不会返回
+func policyDialerNames(policy string) []string {
+	tmpl, err := template.New("policy").Funcs(PolicyFuncMap()).Parse(policy)
+	if err != nil || tmpl.Tree == nil {
+		// 语法错误由 validatePolicyTemplates 报告
+		return nil
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	var walk func(list *parse.ListNode)
+	walk = func(list *parse.ListNode) {
+		if list == nil {
+			return
+		}
+		// 去掉只含空白的文本，liner 会对输出做 TrimSpace
+		var nodes []parse.Node
+		for _, node := range list.Nodes {
+			if text, ok := node.(*parse.TextNode); ok && strings.TrimSpace(string(text.Text)) == "" {
+				continue
+			}
+			nodes = append(nodes, node)
+		}
+
+		if len(nodes) == 1 {
+			if text, ok := nodes[0].(*parse.TextNode); ok {
+				name := dialer.FromPolicyOutput(string(text.Text))
+				if !seen[name] && !slices.Contains(PolicyActions, name) && dialer.ValidateName(name) == nil {
+					seen[name] = true
+					names = append(names, name)
+				}
+				return
+			}
+		}
+		for _, node := range nodes {
+			switch n := node.(type) {
+			case *parse.IfNode:
+				walk(n.List)
+				walk(n.ElseList)
+			case *parse.RangeNode:
+				walk(n.List)
+				walk(n.ElseList)
+			case *parse.WithNode:
+				walk(n.List)
+				walk(n.ElseList)
+			default:
+				// 文本或动作与其他输出拼接，整个分支的输出无法静态确定
+				return
+			}
+		}
+	}
+	walk(tmpl.Tree.Root)
+
+	return names
+}
+
+// validatePolicyTemplates 检查所有 policy 字段的模板语法
+func validatePolicyTemplates(cfg *config.Config, result *ValidationResult) {
+	check := func(field, policy string) {
+		if !strings.Contains(policy, "{{") {
+			return
+		}
+		for _, e := range CheckPolicyTemplate(policy) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   field,
+				Code:    CodeInvalidPolicy,
+				Message: "invalid policy template: " + e.Error(),
+			})
+		}
+	}
+
+	checkHTTP := func(section string, httpCfgs []config.HTTPConfig) {
+		for i, httpCfg := range httpCfgs {
+			check(fmt.Sprintf("%s[%d].forward.policy", section, i), httpCfg.Forward.Policy)
+			for j, webCfg := range httpCfg.Web {
+				check(fmt.Sprintf("%s[%d].web[%d].doh.policy", section, i, j), webCfg.Doh.Policy)
+			}
+		}
+	}
+	checkHTTP("https", cfg.Https)
+	checkHTTP("http", cfg.Http)
+
+	for i, socksCfg := range cfg.Socks {
+		check(fmt.Sprintf("socks[%d].forward.policy", i), socksCfg.Forward.Policy)
+	}
+	check("sni.forward.policy", cfg.Sni.Forward.Policy)
+	for i, dnsCfg := range cfg.Dns {
+		check(fmt.Sprintf("dns[%d].policy", i), dnsCfg.Policy)
+	}
+}
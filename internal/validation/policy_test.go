@@ -0,0 +1,148 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+)
+
+func TestCheckPolicyTemplate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   []string
+	}{
+		{name: "plain policy", policy: "proxy_pass"},
+		{
+			name:   "geoip routing",
+			policy: `{{ if eq (country .Request.RemoteAddr) "CN" }}direct{{ else }}proxy{{ end }}`,
+		},
+		{
+			name:   "sprig and liner functions",
+			policy: "{{ $host := host .Request.Host }}\n{{ if hasSuffixes \"cn|com.cn\" $host }}direct\n{{ else if isInNet (dnsResolve $host) \"10.0.0.0/8\" }}local\n{{ else }}{{ now | date \"15\" | atoi | ternary \"a\" \"b\" }}{{ end }}",
+		},
+		{
+			name:   "unknown function with column",
+			policy: "{{ if eq (countyr .Request.RemoteAddr) \"CN\" }}direct{{ end }}",
+			want:   []string{`line 1, column 11: unknown function "countyr"`},
+		},
+		{
+			name:   "all unknown functions reported",
+			policy: "{{ if foo .Request.Host }}a\n{{ else if bar .Request.Host }}b{{ end }}",
+			want: []string{
+				`line 1, column 7: unknown function "foo"`,
+				`line 2, column 12: unknown function "bar"`,
+			},
+		},
+		{
+			name:   "missing end",
+			policy: "{{ if eq .Request.Host \"a\" }}direct",
+			want:   []string{"line 1: unexpected EOF"},
+		},
+		{
+			name:   "unclosed action",
+			policy: "direct\n{{ if eq (country .Request.RemoteAddr \"CN\" }}",
+			want:   []string{"line 2: unclosed left paren"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := CheckPolicyTemplate(tt.policy)
+			got := make([]string, 0, len(errs))
+			for _, e := range errs {
+				got = append(got, e.Error())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("CheckPolicyTemplate() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.want[i]) {
+					t.Errorf("CheckPolicyTemplate()[%d] = %q, want prefix %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidatePolicyTemplates(t *testing.T) {
+	broken := `{{ if eq (countyr .Request.RemoteAddr) "CN" }}direct{{ end }}`
+	cfg := &config.Config{
+		Https: []config.HTTPConfig{{
+			Listen:     []string{":443"},
+			ServerName: []string{"example.org"},
+			Forward:    config.HTTPForwardConfig{Policy: broken},
+			Web:        []config.HTTPWebConfig{{Location: "/dns-query", Doh: config.HTTPWebDohConfig{Enabled: true, Policy: "{{ if }}"}}},
+		}},
+		Socks: []config.SocksConfig{{Listen: []string{":1080"}, Forward: config.SocksForwardConfig{Policy: broken}}},
+		Sni:   config.SniConfig{Enabled: true, Forward: config.SniForwardConfig{Policy: broken}},
+		Dns:   []config.DnsConfig{{Listen: []string{":53"}, Policy: broken}},
+	}
+
+	result := ValidateConfig(cfg)
+	fields := map[string]bool{}
+	for _, e := range result.Errors {
+		fields[e.Field] = true
+	}
+	for _, want := range []string{
+		"https[0].forward.policy",
+		"https[0].web[0].doh.policy",
+		"socks[0].forward.policy",
+		"sni.forward.policy",
+		"dns[0].policy",
+	} {
+		if !fields[want] {
+			t.Errorf("expected template error for %s, got %v", want, result.Errors)
+		}
+	}
+}
+
+func TestPolicyDialerNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   []string
+	}{
+		{name: "action", policy: "proxy_pass"},
+		{name: "fixed target", policy: "http://backend:8080"},
+		{name: "plain dialer", policy: "cloud", want: []string{"cloud"}},
+		{
+			name:   "branches",
+			policy: "{{ if eq .Request.Host \"a\" }}\n  cloud\n{{ else if eq .Request.Host \"b\" }}\n  direct\n{{ else }}\n  us\n{{ end }}",
+			want:   []string{"cloud", "us"},
+		},
+		{
+			name:   "json and query string",
+			policy: `{{ if .User.Username }}{"dialer":"us","disable_ipv6":true}{{ else }}dialer=cloud&prefer_ipv6=true{{ end }}`,
+			want:   []string{"us", "cloud"},
+		},
+		{
+			name:   "range and with bodies, duplicates removed",
+			policy: `{{ range .List }}cloud{{ end }}{{ with .User }}cloud{{ else }}hk{{ end }}`,
+			want:   []string{"cloud", "hk"},
+		},
+		{name: "dynamic name ignored", policy: `{{ .User.Attrs.dialer }}`},
+		{name: "partial text ignored", policy: `dialer={{ .User.Attrs.dialer }}`},
+		{
+			name:   "mixed branch ignored",
+			policy: `{{ if eq .Request.Host "a" }}cloud-{{ "us" }}{{ else }}proxy_pass{{ end }}`,
+		},
+		{
+			name:   "text around a nested branch ignored",
+			policy: `{{ if .User.Username }}hk{{ else }}cloud{{ if .Request.Host }}-us{{ end }}{{ end }}`,
+			want:   []string{"hk"},
+		},
+		{name: "invalid json ignored", policy: `{{ if .User.Username }}{"dialer":"us"{{ else }}cloud{{ end }}`, want: []string{"cloud"}},
+		{name: "invalid template", policy: `{{ if }}cloud`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policyDialerNames(tt.policy)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("policyDialerNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package validation
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+)
+
+// validateRisks 检查能运行但不安全或有隐患的配置，结果为警告
+func validateRisks(cfg *config.Config, result *ValidationResult) {
+	if cfg.Global.TlsInsecure {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "global.tls_insecure",
+			Code:    CodeTLSInsecure,
+			Message: "tls_insecure disables certificate verification for every upstream TLS connection, allowing man-in-the-middle attacks",
+		})
+	}
+	if cfg.Global.DialReadBuffer > 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "global.dial_read_buffer",
+			Code:    CodeDialReadBuffer,
+			Message: fmt.Sprintf("dial_read_buffer %d sets SO_RCVBUF on outgoing connections, which disables TCP receive window auto-tuning and can stall transfers", cfg.Global.DialReadBuffer),
+		})
+	}
+
+	// 监听公网地址的正向代理可以被用来访问服务器本机和内网
+	checkForward := func(prefix string, listen []string) {
+		if cfg.Global.ForbidLocalAddr {
+			return
+		}
+		for _, addr := range listen {
+			if IsPublicListen(addr) {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   prefix + ".forward",
+					Code:    CodeLocalAddrAllowed,
+					Message: fmt.Sprintf("forward proxy listens on public address %q while global.forbid_local_addr is false, so clients can reach 127.0.0.1 and private networks through it", addr),
+				})
+				return
+			}
+		}
+	}
+
+	checkHTTP := func(section string, httpCfgs []config.HTTPConfig) {
+		for i, httpCfg := range httpCfgs {
+			prefix := fmt.Sprintf("%s[%d]", section, i)
+			if httpCfg.Forward.Policy != "" {
+				checkForward(prefix, httpCfg.Listen)
+			}
+			for j, webCfg := range httpCfg.Web {
+				if webCfg.Shell.Enabled && webCfg.Shell.AuthTable == "" {
+					result.Errors = append(result.Errors, ValidationError{
+						Field:   fmt.Sprintf("%s.web[%d].shell.auth_table", prefix, j),
+						Code:    CodeWebShellNoAuth,
+						Message: fmt.Sprintf("web shell at location %q has no auth_table, anyone who can reach it gets a shell", webCfg.Location),
+					})
+				}
+			}
+		}
+	}
+	checkHTTP("https", cfg.Https)
+	checkHTTP("http", cfg.Http)
+
+	for i, socksCfg := range cfg.Socks {
+		checkForward(fmt.Sprintf("socks[%d]", i), socksCfg.Listen)
+	}
+}
+
+// IsPublicListen 判断监听地址是否可能暴露在公网：监听所有地址、公网IP或主机名
+func IsPublicListen(addr string) bool {
+	host, _, err := config.ParseListenAddress(addr)
+	if err != nil {
+		return false
+	}
+	if host == "" || strings.EqualFold(host, "localhost") {
+		return host == ""
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		// 主机名可能解析为公网地址
+		return true
+	}
+	ip = ip.Unmap()
+	if ip.IsUnspecified() {
+		return true
+	}
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast()
+}
@@ -0,0 +1,95 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+)
+
+func TestValidateRisks(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		// want 为 字段 -> 规则代码
+		want map[string]string
+	}{
+		{
+			name: "safe config",
+			cfg: &config.Config{
+				Global: config.GlobalConfig{ForbidLocalAddr: true},
+				Https: []config.HTTPConfig{{
+					Listen:     []string{":443"},
+					ServerName: []string{"a.example"},
+					Forward:    config.HTTPForwardConfig{Policy: "proxy_pass"},
+					Web:        []config.HTTPWebConfig{{Location: "/shell/", Shell: config.HTTPWebShellConfig{Enabled: true, AuthTable: "auth.csv"}}},
+				}},
+			},
+		},
+		{
+			name: "global risks",
+			cfg: &config.Config{
+				Global: config.GlobalConfig{TlsInsecure: true, DialReadBuffer: 65536},
+			},
+			want: map[string]string{
+				"global.tls_insecure":     CodeTLSInsecure,
+				"global.dial_read_buffer": CodeDialReadBuffer,
+			},
+		},
+		{
+			name: "public forward proxies can reach local addresses",
+			cfg: &config.Config{
+				Http:  []config.HTTPConfig{{Listen: []string{"127.0.0.1:8080", "0.0.0.0:8080"}, Forward: config.HTTPForwardConfig{Policy: "proxy_pass"}}},
+				Socks: []config.SocksConfig{{Listen: []string{"192.168.1.1:1080"}}, {Listen: []string{"8.8.8.8:1080"}}},
+			},
+			want: map[string]string{
+				"http[0].forward":  CodeLocalAddrAllowed,
+				"socks[1].forward": CodeLocalAddrAllowed,
+			},
+		},
+		{
+			name: "web shell without auth",
+			cfg: &config.Config{
+				Http: []config.HTTPConfig{{
+					Listen: []string{"127.0.0.1:80"},
+					Web:    []config.HTTPWebConfig{{Location: "/shell/", Shell: config.HTTPWebShellConfig{Enabled: true, Command: "bash"}}},
+				}},
+			},
+			want: map[string]string{"http[0].web[0].shell.auth_table": CodeWebShellNoAuth},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &ValidationResult{Valid: true}
+			validateRisks(tt.cfg, result)
+			result.finalize()
+			if len(result.Errors) != len(tt.want) {
+				t.Fatalf("got %d findings, want %d: %v", len(result.Errors), len(tt.want), result.Errors)
+			}
+			for _, e := range result.Errors {
+				if tt.want[e.Field] != e.Code {
+					t.Errorf("unexpected finding %s [%s]: %s", e.Field, e.Code, e.Message)
+				}
+				if e.Severity != SeverityWarning || e.Suggestion == "" {
+					t.Errorf("%s: severity = %q, suggestion = %q, want a warning with a suggestion", e.Field, e.Severity, e.Suggestion)
+				}
+			}
+			if !result.Valid {
+				t.Error("warnings should not make the config invalid")
+			}
+		})
+	}
+}
+
+func TestRulesComplete(t *testing.T) {
+	for _, rule := range Rules() {
+		if rule.Summary == "" || rule.Fix == "" {
+			t.Errorf("rule %s needs a summary and a fix", rule.Code)
+		}
+		switch rule.Severity {
+		case SeverityError, SeverityWarning, SeverityInfo:
+		default:
+			t.Errorf("rule %s has unknown severity %q", rule.Code, rule.Severity)
+		}
+	}
+}
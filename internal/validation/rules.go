@@ -0,0 +1,117 @@
+package validation
+
+import (
+	"sort"
+)
+
+// Severity 验证发现的严重程度
+type Severity string
+
+const (
+	// SeverityError liner 无法启动或配置无法按预期工作
+	SeverityError Severity = "error"
+	// SeverityWarning 配置可以运行，但存在安全或稳定性隐患
+	SeverityWarning Severity = "warning"
+	// SeverityInfo 可以改进的地方
+	SeverityInfo Severity = "info"
+)
+
+// 规则代码，发布后保持不变，可用于 # mcp-liner:ignore 注释
+const (
+	CodeRequiredField         = "required-field"
+	CodeInvalidLogLevel       = "invalid-log-level"
+	CodeInvalidDNSServer      = "invalid-dns-server"
+	CodePlainDNSServer        = "plain-dns-server"
+	CodeEmptyDialerName       = "empty-dialer-name"
+	CodeInvalidDialerName     = "invalid-dialer-name"
+	CodeInvalidDialerURL      = "invalid-dialer-url"
+	CodeUndefinedDialer       = "undefined-dialer"
+	CodePolicyUndefinedDialer = "policy-undefined-dialer"
+	CodeInvalidPolicy         = "invalid-policy-template"
+	CodeSniWithoutHTTPS       = "sni-without-https"
+	CodeIncompleteTLSPair     = "incomplete-tls-pair"
+	CodeInvalidProxyProtocol  = "invalid-proxy-protocol"
+	CodeSSHNoAuth             = "ssh-no-auth"
+	CodeInvalidCronSpec       = "invalid-cron-spec"
+	CodeInvalidListenAddress  = "invalid-listen-address"
+	CodeListenConflict        = "listen-conflict"
+	CodeRemoteListenConflict  = "remote-listen-conflict"
+	CodeInvalidAllowListens   = "invalid-allow-listens"
+	CodeTLSInsecure           = "tls-insecure"
+	CodeLocalAddrAllowed      = "local-addr-allowed"
+	CodeWebShellNoAuth        = "webshell-no-auth"
+	CodeDialReadBuffer        = "dial-read-buffer"
+	CodeFileUnreadable        = "file-unreadable"
+	CodeFileOutsideBaseDir    = "file-outside-base-dir"
+	CodeLoosePermissions      = "loose-permissions"
+	CodeInvalidCertificate    = "invalid-certificate"
+	CodeKeyMismatch           = "key-mismatch"
+	CodeCertificateValidity   = "certificate-validity"
+	CodeCertificateName       = "certificate-name-mismatch"
+	CodeInvalidAuthTable      = "invalid-auth-table"
+	CodeInvalidHostKey        = "invalid-host-key"
+	CodeUnknownKey            = "unknown-key"
+	CodeInvalidType           = "invalid-type"
+)
+
+// Rule 一条验证规则：默认严重程度、说明和通用的修复建议
+type Rule struct {
+	Code     string   `json:"code"`
+	Severity Severity `json:"severity"`
+	Summary  string   `json:"summary"`
+	Fix      string   `json:"fix"`
+}
+
+// rules 所有验证规则，按代码索引
+var rules = map[string]Rule{
+	CodeRequiredField:         {CodeRequiredField, SeverityError, "a required field is missing", "set the field to a non-empty value"},
+	CodeInvalidLogLevel:       {CodeInvalidLogLevel, SeverityError, "log_level is not a known level", "use one of trace, debug, info, warn, error, fatal or panic"},
+	CodeInvalidDNSServer:      {CodeInvalidDNSServer, SeverityError, "dns_server is not a DNS server address", "use a DoH URL such as https://8.8.8.8/dns-query or host:port"},
+	CodePlainDNSServer:        {CodePlainDNSServer, SeverityInfo, "DNS queries are sent unencrypted", "use a DoH server such as https://1.1.1.1/dns-query"},
+	CodeEmptyDialerName:       {CodeEmptyDialerName, SeverityError, "a dialer has an empty name", "give the dialer a name"},
+	CodeInvalidDialerName:     {CodeInvalidDialerName, SeverityError, "a dialer name contains characters other than letters, digits, '_' and '-'", "rename the dialer and every reference to it"},
+	CodeInvalidDialerURL:      {CodeInvalidDialerURL, SeverityError, "a dialer URL cannot be parsed", "fix the dialer URL, see query_liner_docs topic dialer"},
+	CodeUndefinedDialer:       {CodeUndefinedDialer, SeverityError, "a referenced dialer is not defined", "define the dialer in the dialer section or use local"},
+	CodePolicyUndefinedDialer: {CodePolicyUndefinedDialer, SeverityError, "a policy template returns an undefined dialer", "define the dialer in the dialer section or fix the policy output"},
+	CodeInvalidPolicy:         {CodeInvalidPolicy, SeverityError, "a policy template does not parse", "fix the template syntax, see query_liner_docs topic policy"},
+	CodeSniWithoutHTTPS:       {CodeSniWithoutHTTPS, SeverityError, "sni is enabled without an https listener", "add an https listener or disable sni"},
+	CodeIncompleteTLSPair:     {CodeIncompleteTLSPair, SeverityError, "only one of keyfile and certfile is set", "set both keyfile and certfile, or neither"},
+	CodeInvalidProxyProtocol:  {CodeInvalidProxyProtocol, SeverityError, "proxy_protocol is not a supported version", "use 0 (disabled), 1 or 2"},
+	CodeSSHNoAuth:             {CodeSSHNoAuth, SeverityError, "ssh has no authentication method", "set auth_table or authorized_keys"},
+	CodeInvalidCronSpec:       {CodeInvalidCronSpec, SeverityError, "cron spec cannot be parsed", "use 5 or 6 fields, a descriptor such as @daily, or @every <duration>"},
+	CodeInvalidListenAddress:  {CodeInvalidListenAddress, SeverityError, "a listen address cannot be parsed", "use host:port such as :443 or 127.0.0.1:443"},
+	CodeListenConflict:        {CodeListenConflict, SeverityError, "two listeners bind the same address and port", "change the port or bind different addresses"},
+	CodeRemoteListenConflict:  {CodeRemoteListenConflict, SeverityError, "two tunnels request the same remote address", "use different remote_listen ports"},
+	CodeInvalidAllowListens:   {CodeInvalidAllowListens, SeverityError, "an allow_listens entry is not an IP or CIDR", "use an IP address or CIDR such as 127.0.0.1 or 10.0.0.0/8"},
+	CodeTLSInsecure:           {CodeTLSInsecure, SeverityWarning, "TLS certificate verification is disabled", "remove tls_insecure and trust the upstream certificate instead"},
+	CodeLocalAddrAllowed:      {CodeLocalAddrAllowed, SeverityWarning, "a public forward proxy can reach local addresses", "set global.forbid_local_addr: true"},
+	CodeWebShellNoAuth:        {CodeWebShellNoAuth, SeverityWarning, "the web shell has no auth_table", "set shell.auth_table"},
+	CodeDialReadBuffer:        {CodeDialReadBuffer, SeverityWarning, "dial_read_buffer can stall connections", "remove dial_read_buffer, see https://issues.apache.org/jira/browse/KAFKA-16496"},
+	CodeFileUnreadable:        {CodeFileUnreadable, SeverityError, "a referenced file or directory cannot be read", "create the file or fix the path and permissions"},
+	CodeFileOutsideBaseDir:    {CodeFileOutsideBaseDir, SeverityError, "a referenced file is outside base_dir and was not checked", "move the file under base_dir or set base_dir to a directory that contains it"},
+	CodeLoosePermissions:      {CodeLoosePermissions, SeverityWarning, "a directory is writable or readable by other users", "tighten the directory permissions with chmod"},
+	CodeInvalidCertificate:    {CodeInvalidCertificate, SeverityError, "a certificate cannot be parsed", "use a PEM encoded certificate"},
+	CodeKeyMismatch:           {CodeKeyMismatch, SeverityError, "a private key does not match its certificate", "use the key the certificate was issued for"},
+	CodeCertificateValidity:   {CodeCertificateValidity, SeverityError, "a certificate is expired or not yet valid", "renew the certificate"},
+	CodeCertificateName:       {CodeCertificateName, SeverityError, "a certificate does not cover a server_name", "issue a certificate that includes the server_name"},
+	CodeInvalidAuthTable:      {CodeInvalidAuthTable, SeverityError, "an auth table is not a valid CSV", "use a CSV with a username,password header, see generate_auth_user"},
+	CodeInvalidHostKey:        {CodeInvalidHostKey, SeverityError, "the ssh host key is not a PEM private key", "generate one with ssh-keygen -t ed25519 -m PEM"},
+	CodeUnknownKey:            {CodeUnknownKey, SeverityError, "liner does not know this key and silently ignores it", "fix the spelling or remove the key, see the liner://schema resource"},
+	CodeInvalidType:           {CodeInvalidType, SeverityError, "the value has the wrong type and liner will fail to load the config", "use the type given in the liner://schema resource"},
+}
+
+// Rules 返回所有验证规则，按代码排序
+func Rules() []Rule {
+	list := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		list = append(list, rule)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// LookupRule 按代码查找规则
+func LookupRule(code string) (Rule, bool) {
+	rule, ok := rules[code]
+	return rule, ok
+}
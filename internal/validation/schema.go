@@ -0,0 +1,34 @@
+package validation
+
+import (
+	"github.com/bensonfx/mcp-liner/internal/schema"
+)
+
+// schemaCodes schema检查类型对应的规则代码
+var schemaCodes = map[schema.Kind]string{
+	schema.KindInvalidType: CodeInvalidType,
+}
+
+// ValidateSchema 按liner配置schema检查YAML原文，报告类型错误
+// 类型不符的值会使 yaml.Unmarshal 失败，只能在原文上逐项定位
+// 未知键由严格解码报告（见 ValidateUnknownKeys），这里不重复报告
+func ValidateSchema(content string, result *ValidationResult) error {
+	findings, err := schema.Check(content)
+	if err != nil {
+		return err
+	}
+	for _, f := range findings {
+		if f.Kind == schema.KindUnknownKey {
+			continue
+		}
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   f.Path,
+			Code:    schemaCodes[f.Kind],
+			Message: f.Message,
+			Line:    f.Line,
+			Column:  f.Column,
+		})
+	}
+	result.finalize()
+	return nil
+}
@@ -0,0 +1,32 @@
+package validation
+
+import (
+	"testing"
+)
+
+func TestValidateSchema(t *testing.T) {
+	content := "global:\n  log_levl: info\n  log_backups: two\n"
+	result := &ValidationResult{Valid: true}
+	if err := ValidateSchema(content, result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Valid || len(result.Errors) != 1 {
+		t.Fatalf("expected one error, got %+v", result)
+	}
+	e := result.Errors[0]
+	// 未知键由严格解码报告，这里只有类型错误
+	if e.Field != "global.log_backups" || e.Code != CodeInvalidType || e.Severity != SeverityError || e.Line != 3 || e.Column != 16 || e.Suggestion == "" {
+		t.Errorf("unexpected finding %+v", e)
+	}
+
+	// 忽略注释同样适用于schema检查的发现
+	content = "global:\n  log_backups: two # mcp-liner:ignore invalid-type\n"
+	result = &ValidationResult{}
+	if err := ValidateSchema(content, result); err != nil {
+		t.Fatal(err)
+	}
+	Annotate(result, content)
+	if !result.Valid || result.Suppressed != 1 {
+		t.Errorf("expected suppressed finding, got %+v", result)
+	}
+}
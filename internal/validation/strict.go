@@ -0,0 +1,81 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/bensonfx/mcp-liner/internal/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// keyLocation 键在YAML中的字段路径和位置
+type keyLocation struct {
+	path         string
+	line, column int
+}
+
+// ValidateUnknownKeys 将 config.FromYAMLStrict 发现的未知键转换为发现
+// yaml.v3 只报告行号，这里根据 content 补全字段路径和列号，并附上"did you mean"建议
+func ValidateUnknownKeys(content string, unknown []config.UnknownKey, result *ValidationResult) {
+	if len(unknown) == 0 {
+		return
+	}
+	skip := map[string]bool{}
+	for _, u := range unknown {
+		skip[fmt.Sprintf("%d:%s", u.Line, u.Key)] = true
+	}
+	locations := map[string][]keyLocation{}
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &root); err == nil && len(root.Content) > 0 {
+		collectKeys(root.Content[0], "", skip, locations)
+	}
+
+	for _, u := range unknown {
+		e := ValidationError{
+			Field:   u.Key,
+			Code:    CodeUnknownKey,
+			Message: fmt.Sprintf("unknown key %q in %s", u.Key, u.Type),
+			Line:    u.Line,
+		}
+		// 同一个键经锚点或合并键被多次解码时按出现顺序依次对应
+		id := fmt.Sprintf("%d:%s", u.Line, u.Key)
+		if locs := locations[id]; len(locs) > 0 {
+			e.Field, e.Line, e.Column = locs[0].path, locs[0].line, locs[0].column
+			locations[id] = locs[1:]
+		}
+		if u.Suggestion != "" {
+			e.Message += fmt.Sprintf(", did you mean %q?", u.Suggestion)
+			e.Suggestion = fmt.Sprintf("rename %q to %q", u.Key, u.Suggestion)
+		}
+		result.Errors = append(result.Errors, e)
+	}
+	result.finalize()
+}
+
+// collectKeys 按文档顺序记录每个映射键的字段路径，与 yaml.v3 一样展开锚点和合并键
+// 未知键的值不会被解码，skip 中的键不再向下查找
+func collectKeys(node *yaml.Node, path string, skip map[string]bool, locations map[string][]keyLocation) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag == "!!merge" {
+				collectKeys(value, path, skip, locations)
+				continue
+			}
+			childPath := schema.JoinPath(path, key.Value)
+			id := fmt.Sprintf("%d:%s", key.Line, key.Value)
+			locations[id] = append(locations[id], keyLocation{childPath, key.Line, key.Column})
+			if !skip[id] {
+				collectKeys(value, childPath, skip, locations)
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			collectKeys(item, fmt.Sprintf("%s[%d]", path, i), skip, locations)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+)
+
+func TestValidateUnknownKeys(t *testing.T) {
+	content := `x-forward: &fwd
+  policy: proxy_pass
+  dialr: local
+https:
+  - listen: [':443']
+    server_names: [a.example]
+    server_config:
+      a.example:
+        certfle: a.pem
+socks:
+  - forward: *fwd
+  - forward:
+      <<: *fwd
+      log: true
+`
+	_, unknown, err := config.FromYAMLStrict(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &ValidationResult{}
+	ValidateUnknownKeys(content, unknown, result)
+
+	type finding struct {
+		field        string
+		line, column int
+		suggestion   string
+	}
+	want := []finding{
+		{"x-forward", 1, 1, ""},
+		{"https[0].server_names", 6, 5, `rename "server_names" to "server_name"`},
+		{"https[0].server_config[a.example].certfle", 9, 9, `rename "certfle" to "certfile"`},
+		{"socks[0].forward.dialr", 3, 3, `rename "dialr" to "dialer"`},
+		{"socks[1].forward.dialr", 3, 3, `rename "dialr" to "dialer"`},
+	}
+	if len(result.Errors) != len(want) {
+		t.Fatalf("got %d findings, want %d: %+v", len(result.Errors), len(want), result.Errors)
+	}
+	for i, e := range result.Errors {
+		got := finding{e.Field, e.Line, e.Column, e.Suggestion}
+		if e.Code != CodeUnknownKey || (want[i].suggestion == "" && got.suggestion != rules[CodeUnknownKey].Fix) {
+			t.Errorf("finding %d: unexpected code or suggestion %+v", i, e)
+			continue
+		}
+		if want[i].suggestion == "" {
+			got.suggestion = ""
+		}
+		if got != want[i] {
+			t.Errorf("finding %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+	if result.Valid {
+		t.Error("unknown keys should fail validation")
+	}
+}
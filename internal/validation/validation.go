@@ -3,16 +3,26 @@ package validation
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/bensonfx/mcp-liner/internal/dialer"
 	"gopkg.in/yaml.v3"
 )
 
-// ValidationError 验证错误
+// ValidationError 一条验证发现
+// Severity 为空时视为错误；Line、Column 为该字段在YAML中的位置，从1开始，未定位时为0
 type ValidationError struct {
-	Field   string
-	Message string
+	Field      string
+	Message    string
+	Code       string
+	Severity   Severity
+	Suggestion string
+	Line       int
+	Column     int
 }
 
 func (e *ValidationError) Error() string {
@@ -20,9 +30,40 @@ func (e *ValidationError) Error() string {
 }
 
 // ValidationResult 验证结果
+// Errors 包含所有级别的发现，只有错误级别的发现会使 Valid 为 false
 type ValidationResult struct {
-	Valid  bool
-	Errors []ValidationError
+	Valid      bool
+	Errors     []ValidationError
+	Suppressed int // 被 # mcp-liner:ignore 注释忽略的发现数量
+}
+
+// Count 返回指定严重程度的发现数量
+func (r *ValidationResult) Count(severity Severity) int {
+	n := 0
+	for _, e := range r.Errors {
+		if e.Severity == severity || (e.Severity == "" && severity == SeverityError) {
+			n++
+		}
+	}
+	return n
+}
+
+// finalize 按规则补全严重程度和修复建议，并根据错误数量更新 Valid
+func (r *ValidationResult) finalize() {
+	for i := range r.Errors {
+		e := &r.Errors[i]
+		rule, ok := rules[e.Code]
+		if e.Severity == "" {
+			e.Severity = SeverityError
+			if ok {
+				e.Severity = rule.Severity
+			}
+		}
+		if e.Suggestion == "" && ok {
+			e.Suggestion = rule.Fix
+		}
+	}
+	r.Valid = r.Count(SeverityError) == 0
 }
 
 // ValidateYAML 验证YAML语法
@@ -73,12 +114,42 @@ func ValidateConfig(cfg *config.Config) *ValidationResult {
 		validateSocksConfig(socksCfg, fmt.Sprintf("socks[%d]", i), result)
 	}
 
+	// 验证SNI配置
+	validateSniConfig(cfg, result)
+
+	// 验证Redsocks配置
+	for i, redsocksCfg := range cfg.Redsocks {
+		validateRedsocksConfig(redsocksCfg, fmt.Sprintf("redsocks[%d]", i), result)
+	}
+
+	// 验证Stream配置
+	for i, streamCfg := range cfg.Stream {
+		validateStreamConfig(streamCfg, fmt.Sprintf("stream[%d]", i), result)
+	}
+
+	// 验证SSH配置
+	for i, sshCfg := range cfg.Ssh {
+		validateSshConfig(sshCfg, fmt.Sprintf("ssh[%d]", i), result)
+	}
+
+	// 验证Cron配置
+	for i, cronCfg := range cfg.Cron {
+		validateCronConfig(cronCfg, fmt.Sprintf("cron[%d]", i), result)
+	}
+
+	// 验证监听地址格式与冲突
+	validateListenAddresses(cfg, result)
+
 	// 验证dialer引用
 	validateDialerReferences(cfg, result)
 
-	if len(result.Errors) > 0 {
-		result.Valid = false
-	}
+	// 验证policy模板语法
+	validatePolicyTemplates(cfg, result)
+
+	// 检查不安全或有隐患的配置
+	validateRisks(cfg, result)
+
+	result.finalize()
 
 	return result
 }
@@ -88,9 +159,10 @@ func validateGlobal(global *config.GlobalConfig, result *ValidationResult) {
 	// 验证日志级别
 	if global.LogLevel != "" {
 		validLevels := []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}
-		if !contains(validLevels, global.LogLevel) {
+		if !slices.Contains(validLevels, global.LogLevel) {
 			result.Errors = append(result.Errors, ValidationError{
 				Field:   "global.log_level",
+				Code:    CodeInvalidLogLevel,
 				Message: fmt.Sprintf("invalid log level: %s, must be one of: %s", global.LogLevel, strings.Join(validLevels, ", ")),
 			})
 		}
@@ -101,8 +173,15 @@ func validateGlobal(global *config.GlobalConfig, result *ValidationResult) {
 		if !strings.HasPrefix(global.DnsServer, "https://") && !strings.HasPrefix(global.DnsServer, "udp://") && !strings.Contains(global.DnsServer, ":") {
 			result.Errors = append(result.Errors, ValidationError{
 				Field:   "global.dns_server",
+				Code:    CodeInvalidDNSServer,
 				Message: "dns_server should be a valid DNS server address (e.g., 'https://8.8.8.8/dns-query' or '8.8.8.8:53')",
 			})
+		} else if strings.HasPrefix(global.DnsServer, "udp://") || !strings.Contains(global.DnsServer, "://") {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "global.dns_server",
+				Code:    CodePlainDNSServer,
+				Message: fmt.Sprintf("dns_server %q resolves names over plain DNS, which can be observed and spoofed on the path", global.DnsServer),
+			})
 		}
 	}
 }
@@ -113,17 +192,32 @@ func validateDialers(dialers map[string]string, result *ValidationResult) {
 		return
 	}
 
-	for name, url := range dialers {
+	names := make([]string, 0, len(dialers))
+	for name := range dialers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
 		if name == "" {
 			result.Errors = append(result.Errors, ValidationError{
 				Field:   "dialer",
+				Code:    CodeEmptyDialerName,
 				Message: "dialer name cannot be empty",
 			})
+		} else if err := dialer.ValidateName(name); err != nil {
+			// policy 模板以文本输出拨号器名称，与 generate_dialer_config 使用相同的规则
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("dialer.%s", name),
+				Code:    CodeInvalidDialerName,
+				Message: err.Error(),
+			})
 		}
-		if url == "" {
+		if _, err := dialer.Parse(dialers[name]); err != nil {
 			result.Errors = append(result.Errors, ValidationError{
 				Field:   fmt.Sprintf("dialer.%s", name),
-				Message: "dialer URL cannot be empty",
+				Code:    CodeInvalidDialerURL,
+				Message: "invalid dialer URL: " + err.Error(),
 			})
 		}
 	}
@@ -135,6 +229,7 @@ func validateHTTPConfig(httpCfg config.HTTPConfig, prefix string, result *Valida
 	if len(httpCfg.Listen) == 0 {
 		result.Errors = append(result.Errors, ValidationError{
 			Field:   fmt.Sprintf("%s.listen", prefix),
+			Code:    CodeRequiredField,
 			Message: "listen field is required and cannot be empty",
 		})
 	}
@@ -143,6 +238,7 @@ func validateHTTPConfig(httpCfg config.HTTPConfig, prefix string, result *Valida
 	if isHTTPS && len(httpCfg.ServerName) == 0 {
 		result.Errors = append(result.Errors, ValidationError{
 			Field:   fmt.Sprintf("%s.server_name", prefix),
+			Code:    CodeRequiredField,
 			Message: "server_name field is required for HTTPS configuration",
 		})
 	}
@@ -152,6 +248,7 @@ func validateHTTPConfig(httpCfg config.HTTPConfig, prefix string, result *Valida
 		if httpCfg.Forward.Policy == "" {
 			result.Errors = append(result.Errors, ValidationError{
 				Field:   fmt.Sprintf("%s.forward.policy", prefix),
+				Code:    CodeRequiredField,
 				Message: "policy is required when forward is configured",
 			})
 		}
@@ -168,6 +265,7 @@ func validateTunnelConfig(tunnelCfg config.TunnelConfig, prefix string, result *
 	if len(tunnelCfg.RemoteListen) == 0 {
 		result.Errors = append(result.Errors, ValidationError{
 			Field:   fmt.Sprintf("%s.remote_listen", prefix),
+			Code:    CodeRequiredField,
 			Message: "remote_listen field is required and cannot be empty",
 		})
 	}
@@ -176,6 +274,7 @@ func validateTunnelConfig(tunnelCfg config.TunnelConfig, prefix string, result *
 	if tunnelCfg.ProxyPass == "" {
 		result.Errors = append(result.Errors, ValidationError{
 			Field:   fmt.Sprintf("%s.proxy_pass", prefix),
+			Code:    CodeRequiredField,
 			Message: "proxy_pass field is required",
 		})
 	}
@@ -184,6 +283,7 @@ func validateTunnelConfig(tunnelCfg config.TunnelConfig, prefix string, result *
 	if tunnelCfg.Dialer == "" {
 		result.Errors = append(result.Errors, ValidationError{
 			Field:   fmt.Sprintf("%s.dialer", prefix),
+			Code:    CodeRequiredField,
 			Message: "dialer field is required",
 		})
 	}
@@ -195,6 +295,7 @@ func validateDNSConfig(dnsCfg config.DnsConfig, prefix string, result *Validatio
 	if len(dnsCfg.Listen) == 0 {
 		result.Errors = append(result.Errors, ValidationError{
 			Field:   fmt.Sprintf("%s.listen", prefix),
+			Code:    CodeRequiredField,
 			Message: "listen field is required and cannot be empty",
 		})
 	}
@@ -203,6 +304,7 @@ func validateDNSConfig(dnsCfg config.DnsConfig, prefix string, result *Validatio
 	if dnsCfg.Policy == "forward" && dnsCfg.ProxyPass == "" {
 		result.Errors = append(result.Errors, ValidationError{
 			Field:   fmt.Sprintf("%s.proxy_pass", prefix),
+			Code:    CodeRequiredField,
 			Message: "proxy_pass is required when policy is 'forward'",
 		})
 	}
@@ -214,9 +316,144 @@ func validateSocksConfig(socksCfg config.SocksConfig, prefix string, result *Val
 	if len(socksCfg.Listen) == 0 {
 		result.Errors = append(result.Errors, ValidationError{
 			Field:   fmt.Sprintf("%s.listen", prefix),
+			Code:    CodeRequiredField,
+			Message: "listen field is required and cannot be empty",
+		})
+	}
+}
+
+// validateSniConfig 验证SNI配置
+// SNI 转发挂在 https 监听上，处理 server_name 不匹配的 TLS 连接
+func validateSniConfig(cfg *config.Config, result *ValidationResult) {
+	if cfg.Sni.Enabled && len(cfg.Https) == 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "sni.enabled",
+			Code:    CodeSniWithoutHTTPS,
+			Message: "sni requires at least one https listener",
+		})
+	}
+}
+
+// validateRedsocksConfig 验证Redsocks配置
+func validateRedsocksConfig(redsocksCfg config.RedsocksConfig, prefix string, result *ValidationResult) {
+	// 验证listen字段
+	if len(redsocksCfg.Listen) == 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   fmt.Sprintf("%s.listen", prefix),
+			Code:    CodeRequiredField,
+			Message: "listen field is required and cannot be empty",
+		})
+	}
+}
+
+// validateStreamConfig 验证Stream配置
+func validateStreamConfig(streamCfg config.StreamConfig, prefix string, result *ValidationResult) {
+	// 验证listen字段
+	if len(streamCfg.Listen) == 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   fmt.Sprintf("%s.listen", prefix),
+			Code:    CodeRequiredField,
+			Message: "listen field is required and cannot be empty",
+		})
+	}
+
+	// 验证proxy_pass字段
+	if streamCfg.ProxyPass == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   fmt.Sprintf("%s.proxy_pass", prefix),
+			Code:    CodeRequiredField,
+			Message: "proxy_pass field is required",
+		})
+	}
+
+	// TLS证书和私钥必须同时配置
+	if (streamCfg.Keyfile == "") != (streamCfg.Certfile == "") {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   fmt.Sprintf("%s.certfile", prefix),
+			Code:    CodeIncompleteTLSPair,
+			Message: "keyfile and certfile must be configured together",
+		})
+	}
+
+	// 验证proxy_protocol版本
+	if streamCfg.ProxyProtocol > 2 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   fmt.Sprintf("%s.proxy_protocol", prefix),
+			Code:    CodeInvalidProxyProtocol,
+			Message: fmt.Sprintf("invalid proxy_protocol: %d, must be 0 (disabled), 1 or 2", streamCfg.ProxyProtocol),
+		})
+	}
+}
+
+// validateSshConfig 验证SSH配置
+func validateSshConfig(sshCfg config.SshConfig, prefix string, result *ValidationResult) {
+	// 验证listen字段
+	if len(sshCfg.Listen) == 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   fmt.Sprintf("%s.listen", prefix),
+			Code:    CodeRequiredField,
 			Message: "listen field is required and cannot be empty",
 		})
 	}
+
+	// 没有认证方式时所有登录都会被拒绝
+	if sshCfg.AuthTable == "" && sshCfg.AuthorizedKeys == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   fmt.Sprintf("%s.auth_table", prefix),
+			Code:    CodeSSHNoAuth,
+			Message: "auth_table or authorized_keys is required, otherwise every login is rejected",
+		})
+	}
+}
+
+// cronDescriptors cron 支持的预定义表达式
+var cronDescriptors = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+// validateCronConfig 验证Cron配置
+func validateCronConfig(cronCfg config.CronConfig, prefix string, result *ValidationResult) {
+	// 验证spec字段：5个字段（可带秒为6个）、预定义表达式或 @every <duration>
+	spec := strings.TrimSpace(cronCfg.Spec)
+	switch {
+	case spec == "":
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   fmt.Sprintf("%s.spec", prefix),
+			Code:    CodeRequiredField,
+			Message: "spec field is required",
+		})
+	case strings.HasPrefix(spec, "@every "):
+		if _, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every "))); err != nil {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.spec", prefix),
+				Code:    CodeInvalidCronSpec,
+				Message: fmt.Sprintf("invalid @every duration in spec %q", spec),
+			})
+		}
+	case strings.HasPrefix(spec, "@"):
+		if !slices.Contains(cronDescriptors, spec) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.spec", prefix),
+				Code:    CodeInvalidCronSpec,
+				Message: fmt.Sprintf("unknown descriptor %q, must be one of: %s or @every <duration>", spec, strings.Join(cronDescriptors, ", ")),
+			})
+		}
+	default:
+		if n := len(strings.Fields(spec)); n != 5 && n != 6 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("%s.spec", prefix),
+				Code:    CodeInvalidCronSpec,
+				Message: fmt.Sprintf("spec %q has %d fields, expected 5 (minute hour day month weekday) or 6 with seconds", spec, n),
+			})
+		}
+	}
+
+	// 验证command字段
+	if cronCfg.Command == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   fmt.Sprintf("%s.command", prefix),
+			Code:    CodeRequiredField,
+			Message: "command field is required",
+		})
+	}
 }
 
 // validateWebConfig 验证Web配置
@@ -226,6 +463,7 @@ func validateWebConfig(webCfg config.HTTPWebConfig, prefix string, result *Valid
 		if webCfg.Fastcgi.Root == "" {
 			result.Errors = append(result.Errors, ValidationError{
 				Field:   fmt.Sprintf("%s.fastcgi.root", prefix),
+				Code:    CodeRequiredField,
 				Message: "root is required when fastcgi is enabled",
 			})
 		}
@@ -236,6 +474,7 @@ func validateWebConfig(webCfg config.HTTPWebConfig, prefix string, result *Valid
 		if webCfg.Dav.Root == "" {
 			result.Errors = append(result.Errors, ValidationError{
 				Field:   fmt.Sprintf("%s.dav.root", prefix),
+				Code:    CodeRequiredField,
 				Message: "root is required when dav is enabled",
 			})
 		}
@@ -246,6 +485,7 @@ func validateWebConfig(webCfg config.HTTPWebConfig, prefix string, result *Valid
 		if webCfg.Shell.Command == "" {
 			result.Errors = append(result.Errors, ValidationError{
 				Field:   fmt.Sprintf("%s.shell.command", prefix),
+				Code:    CodeRequiredField,
 				Message: "command is required when shell is enabled",
 			})
 		}
@@ -253,6 +493,7 @@ func validateWebConfig(webCfg config.HTTPWebConfig, prefix string, result *Valid
 }
 
 // validateDialerReferences 验证dialer引用
+// 除 dialer 字段外，还会检查 forward policy 模板中以文本形式返回的拨号器名称
 func validateDialerReferences(cfg *config.Config, result *ValidationResult) {
 	// 收集所有定义的dialer
 	定义的dialers := make(map[string]bool)
@@ -261,66 +502,100 @@ func validateDialerReferences(cfg *config.Config, result *ValidationResult) {
 		定义的dialers[name] = true
 	}
 
-	// 检查HTTP配置中的dialer引用
-	for i, httpsCfg := range cfg.Https {
-		if httpsCfg.Forward.Dialer != "" && !定义的dialers[httpsCfg.Forward.Dialer] {
+	checkDialer := func(field, name string) {
+		if name != "" && !定义的dialers[name] {
 			result.Errors = append(result.Errors, ValidationError{
-				Field:   fmt.Sprintf("https[%d].forward.dialer", i),
-				Message: fmt.Sprintf("dialer '%s' is not defined", httpsCfg.Forward.Dialer),
+				Field:   field,
+				Code:    CodeUndefinedDialer,
+				Message: fmt.Sprintf("dialer '%s' is not defined", name),
 			})
 		}
 	}
+	checkPolicy := func(field, policy string) {
+		for _, name := range policyDialerNames(policy) {
+			if !定义的dialers[name] {
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   field,
+					Code:    CodePolicyUndefinedDialer,
+					Message: fmt.Sprintf("policy may return dialer '%s', which is not defined", name),
+				})
+			}
+		}
+	}
+
+	// 检查HTTP配置中的dialer引用
+	for i, httpsCfg := range cfg.Https {
+		checkDialer(fmt.Sprintf("https[%d].forward.dialer", i), httpsCfg.Forward.Dialer)
+		checkPolicy(fmt.Sprintf("https[%d].forward.policy", i), httpsCfg.Forward.Policy)
+	}
 
 	for i, httpCfg := range cfg.Http {
-		if httpCfg.Forward.Dialer != "" && !定义的dialers[httpCfg.Forward.Dialer] {
-			result.Errors = append(result.Errors, ValidationError{
-				Field:   fmt.Sprintf("http[%d].forward.dialer", i),
-				Message: fmt.Sprintf("dialer '%s' is not defined", httpCfg.Forward.Dialer),
-			})
-		}
+		checkDialer(fmt.Sprintf("http[%d].forward.dialer", i), httpCfg.Forward.Dialer)
+		checkPolicy(fmt.Sprintf("http[%d].forward.policy", i), httpCfg.Forward.Policy)
 	}
 
 	// 检查隧道配置中的dialer引用
 	for i, tunnelCfg := range cfg.Tunnel {
-		if tunnelCfg.Dialer != "" && !定义的dialers[tunnelCfg.Dialer] {
-			result.Errors = append(result.Errors, ValidationError{
-				Field:   fmt.Sprintf("tunnel[%d].dialer", i),
-				Message: fmt.Sprintf("dialer '%s' is not defined", tunnelCfg.Dialer),
-			})
-		}
+		checkDialer(fmt.Sprintf("tunnel[%d].dialer", i), tunnelCfg.Dialer)
 	}
 
 	// 检查Socks配置中的dialer引用
 	for i, socksCfg := range cfg.Socks {
-		if socksCfg.Forward.Dialer != "" && !定义的dialers[socksCfg.Forward.Dialer] {
-			result.Errors = append(result.Errors, ValidationError{
-				Field:   fmt.Sprintf("socks[%d].forward.dialer", i),
-				Message: fmt.Sprintf("dialer '%s' is not defined", socksCfg.Forward.Dialer),
-			})
-		}
+		checkDialer(fmt.Sprintf("socks[%d].forward.dialer", i), socksCfg.Forward.Dialer)
+		checkPolicy(fmt.Sprintf("socks[%d].forward.policy", i), socksCfg.Forward.Policy)
 	}
-}
 
-// contains 检查字符串是否在切片中
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
-		}
+	// 检查SNI配置中的dialer引用
+	checkDialer("sni.forward.dialer", cfg.Sni.Forward.Dialer)
+	checkPolicy("sni.forward.policy", cfg.Sni.Forward.Policy)
+
+	// 检查Redsocks配置中的dialer引用
+	for i, redsocksCfg := range cfg.Redsocks {
+		checkDialer(fmt.Sprintf("redsocks[%d].forward.dialer", i), redsocksCfg.Forward.Dialer)
+	}
+
+	// 检查Stream配置中的dialer引用
+	for i, streamCfg := range cfg.Stream {
+		checkDialer(fmt.Sprintf("stream[%d].dialer", i), streamCfg.Dialer)
 	}
-	return false
 }
 
-// FormatValidationErrors 格式化验证错误
+// FormatValidationErrors 格式化验证结果，包括警告和提示
 func FormatValidationErrors(result *ValidationResult) string {
-	if result.Valid {
+	if len(result.Errors) == 0 {
 		return "Configuration is valid"
 	}
 
 	var builder strings.Builder
-	builder.WriteString("Configuration validation failed:\n\n")
+	if result.Valid {
+		builder.WriteString("Configuration is valid with findings:\n\n")
+	} else {
+		builder.WriteString("Configuration validation failed:\n\n")
+	}
 	for i, err := range result.Errors {
-		builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, err.Error()))
+		builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, FormatFinding(err)))
+		if err.Suggestion != "" {
+			builder.WriteString(fmt.Sprintf("   fix: %s\n", err.Suggestion))
+		}
+	}
+	return builder.String()
+}
+
+// FormatFinding 将一条发现格式化为单行：[severity code] line L, column C: field: message
+func FormatFinding(e ValidationError) string {
+	severity := e.Severity
+	if severity == "" {
+		severity = SeverityError
+	}
+	var builder strings.Builder
+	builder.WriteString("[" + string(severity))
+	if e.Code != "" {
+		builder.WriteString(" " + e.Code)
+	}
+	builder.WriteString("] ")
+	if e.Line > 0 {
+		builder.WriteString(fmt.Sprintf("line %d, column %d: ", e.Line, e.Column))
 	}
+	builder.WriteString(e.Error())
 	return builder.String()
 }
@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bensonfx/mcp-liner/internal/audit"
+	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/phuslu/log"
+)
+
+// AuditLinerConfigParams audit_liner_config工具的参数
+type AuditLinerConfigParams struct {
+	ConfigContent string            `json:"config_content" jsonschema:"liner configuration in YAML format"`                                                              // YAML配置内容
+	AuthTables    map[string]string `json:"auth_tables,omitempty" jsonschema:"auth_table path as written in the config to its CSV content, used to find weak passwords"` // 认证表路径 -> CSV内容
+}
+
+// AuditLinerConfigInputSchema audit_liner_config工具的输入schema
+func AuditLinerConfigInputSchema() *jsonschema.Schema {
+	return inputSchema[AuditLinerConfigParams](schemaOptions{
+		Required: []string{"config_content"},
+	})
+}
+
+// AuditLinerConfig 审计liner配置的安全暴露面，返回按风险排序的发现和修复建议
+func AuditLinerConfig(arguments json.RawMessage) (*responses.Result, error) {
+	var params AuditLinerConfigParams
+	if err := json.Unmarshal(arguments, &params); err != nil {
+		log.Error().Err(err).Msg("failed to parse parameters")
+		return responses.ErrorResponse(
+			fmt.Sprintf("Invalid parameters: %v", err),
+			"Please provide 'config_content' parameter with YAML configuration",
+		)
+	}
+
+	log.Info().Int("auth_tables", len(params.AuthTables)).Msg("auditing liner config")
+
+	cfg, err := parseConfigFragment(params.ConfigContent)
+	if err != nil {
+		return responses.ErrorResponse(
+			fmt.Sprintf("config_content: %v", err),
+			"Please check the YAML syntax of the configuration",
+		)
+	}
+
+	report := audit.Audit(cfg, audit.Options{AuthTables: params.AuthTables})
+	log.Info().Int("score", report.Score).Int("findings", len(report.Findings)).Msg("config audit finished")
+
+	return responses.AuditResponse(report)
+}
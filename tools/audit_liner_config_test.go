@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/responses"
+)
+
+func TestAuditLinerConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		params       AuditLinerConfigParams
+		wantIsError  bool
+		wantFindings int
+		wantContain  []string
+	}{
+		{
+			name: "open socks proxy and weak password",
+			params: AuditLinerConfigParams{
+				ConfigContent: `global:
+  forbid_local_addr: true
+socks:
+  - listen: [':1080']
+https:
+  - listen: [':443']
+    server_name: [a.example]
+    server_config:
+      a.example:
+        disable_tls11: true
+    forward:
+      policy: proxy_pass
+      auth_table: auth_user.csv
+`,
+				AuthTables: map[string]string{"auth_user.csv": "username,password\nalice,password\n"},
+			},
+			wantFindings: 2,
+			wantContain: []string{
+				"Score: 40/100 (grade D)",
+				"1. [critical] `open-forward-proxy` **socks[0].forward.auth_table**",
+				"2. [high] `weak-password` **https[0].forward.auth_table**: auth_user.csv has weak passwords for: alice (common password)",
+			},
+		},
+		{
+			name:        "passwords not checked without auth tables",
+			params:      AuditLinerConfigParams{ConfigContent: "socks:\n  - listen: ['127.0.0.1:1080']\n"},
+			wantContain: []string{"Score: 100/100 (grade A)", "No exposure issues found", "passwords were not checked"},
+		},
+		{
+			name:        "invalid YAML",
+			params:      AuditLinerConfigParams{ConfigContent: "socks: ["},
+			wantIsError: true,
+			wantContain: []string{"config_content"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, _ := json.Marshal(tt.params)
+			result, err := AuditLinerConfig(args)
+			if err != nil {
+				t.Fatalf("AuditLinerConfig() error: %v", err)
+			}
+			if result.IsError != tt.wantIsError {
+				t.Fatalf("IsError = %v, want %v: %s", result.IsError, tt.wantIsError, result.Text())
+			}
+			for _, want := range tt.wantContain {
+				if !strings.Contains(result.Text(), want) {
+					t.Errorf("result does not contain %q:\n%s", want, result.Text())
+				}
+			}
+			if tt.wantIsError {
+				return
+			}
+			output := result.Structured.(responses.AuditOutput)
+			if len(output.Findings) != tt.wantFindings {
+				t.Errorf("got %d findings, want %d: %v", len(output.Findings), tt.wantFindings, output.Findings)
+			}
+		})
+	}
+}
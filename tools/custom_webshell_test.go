@@ -40,8 +40,8 @@ func TestGenerateWebshellConfig(t *testing.T) {
 	}
 
 	for _, s := range expectedStrings {
-		if !strings.Contains(result, s) {
-			t.Errorf("Expected output to contain %q, but it didn't.\nOutput:\n%s", s, result)
+		if !strings.Contains(result.Text(), s) {
+			t.Errorf("Expected output to contain %q, but it didn't.\nOutput:\n%s", s, result.Text())
 		}
 	}
 }
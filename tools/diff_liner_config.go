@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/phuslu/log"
+)
+
+// DiffLinerConfigParams diff_liner_config工具的参数
+type DiffLinerConfigParams struct {
+	OldConfig string `json:"old_config" jsonschema:"original liner configuration in YAML format"` // 原配置YAML
+	NewConfig string `json:"new_config" jsonschema:"updated liner configuration in YAML format"`  // 新配置YAML
+}
+
+// DiffLinerConfigInputSchema diff_liner_config工具的输入schema
+func DiffLinerConfigInputSchema() *jsonschema.Schema {
+	return inputSchema[DiffLinerConfigParams](schemaOptions{
+		Required: []string{"old_config", "new_config"},
+	})
+}
+
+// DiffLinerConfig 比较两份liner配置，按配置项报告新增、删除和修改
+func DiffLinerConfig(arguments json.RawMessage) (*responses.Result, error) {
+	var params DiffLinerConfigParams
+	if err := json.Unmarshal(arguments, &params); err != nil {
+		log.Error().Err(err).Msg("failed to parse parameters")
+		return responses.ErrorResponse(
+			fmt.Sprintf("Invalid parameters: %v", err),
+			"Please provide 'old_config' and 'new_config' as YAML strings",
+		)
+	}
+
+	log.Info().Msg("diffing liner configs")
+
+	oldConfig, err := parseConfigFragment(params.OldConfig)
+	if err != nil {
+		return responses.ErrorResponse(
+			fmt.Sprintf("old_config: %v", err),
+			"Please check the YAML syntax of the original configuration",
+		)
+	}
+	newConfig, err := parseConfigFragment(params.NewConfig)
+	if err != nil {
+		return responses.ErrorResponse(
+			fmt.Sprintf("new_config: %v", err),
+			"Please check the YAML syntax of the updated configuration",
+		)
+	}
+
+	return responses.DiffResponse(config.Diff(oldConfig, newConfig))
+}
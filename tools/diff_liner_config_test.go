@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/responses"
+)
+
+func TestDiffLinerConfig(t *testing.T) {
+	oldConfig := `dialer:
+  cloud: socks5://1.2.3.4:1080
+https:
+  - listen: [':443']
+    server_name: [a.example]
+    forward:
+      policy: proxy_pass
+      dialer: cloud
+`
+
+	tests := []struct {
+		name        string
+		newConfig   string
+		wantIsError bool
+		wantChanges int
+		wantContain []string
+	}{
+		{
+			name:        "equivalent configs",
+			newConfig:   oldConfig,
+			wantContain: []string{"equivalent"},
+		},
+		{
+			name: "dialer changed and tunnel added",
+			newConfig: `dialer:
+  cloud: socks5://5.6.7.8:1080
+https:
+  - listen: [':443']
+    server_name: [a.example]
+    forward:
+      policy: proxy_pass
+      dialer: cloud
+tunnel:
+  - remote_listen: ['127.0.0.1:10022']
+    proxy_pass: 127.0.0.1:22
+    dialer: cloud
+`,
+			wantChanges: 2,
+			wantContain: []string{
+				`~ dialer[cloud]: "socks5://1.2.3.4:1080" → "socks5://5.6.7.8:1080"`,
+				"+ tunnel[127.0.0.1:10022]: added {remote_listen=[127.0.0.1:10022]",
+			},
+		},
+		{
+			name:        "invalid new config",
+			newConfig:   "dialer: [",
+			wantIsError: true,
+			wantContain: []string{"new_config"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonData, err := json.Marshal(DiffLinerConfigParams{OldConfig: oldConfig, NewConfig: tt.newConfig})
+			if err != nil {
+				t.Fatalf("failed to marshal params: %v", err)
+			}
+
+			result, err := DiffLinerConfig(jsonData)
+			if err != nil {
+				t.Fatalf("DiffLinerConfig() unexpected error: %v", err)
+			}
+			if result.IsError != tt.wantIsError {
+				t.Errorf("DiffLinerConfig() IsError = %v, want %v: %s", result.IsError, tt.wantIsError, result.Text())
+			}
+			if output, ok := result.Structured.(responses.DiffOutput); ok && len(output.Changes) != tt.wantChanges {
+				t.Errorf("DiffLinerConfig() returned %d changes, want %d", len(output.Changes), tt.wantChanges)
+			}
+			for _, want := range tt.wantContain {
+				if !strings.Contains(result.Text(), want) {
+					t.Errorf("DiffLinerConfig() result does not contain %q:\n%s", want, result.Text())
+				}
+			}
+		})
+	}
+}
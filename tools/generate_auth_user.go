@@ -1,33 +1,54 @@
 package tools
 
 import (
+	"crypto/rand"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // AuthUserParams defines parameters for a single user
 type AuthUserParams struct {
-	Username    string            `json:"username"`
-	Password    string            `json:"password"`
-	SpeedLimit  int64             `json:"speed_limit,omitempty"`
-	AllowTunnel bool              `json:"allow_tunnel,omitempty"`
-	AllowClient bool              `json:"allow_client,omitempty"`
-	AllowSSH    bool              `json:"allow_ssh,omitempty"`
-	AllowWebDAV bool              `json:"allow_webdav,omitempty"`
-	Attrs       map[string]string `json:"attrs,omitempty"` // For any extra attributes
+	Username    string            `json:"username" jsonschema:"user name"`
+	Password    string            `json:"password,omitempty" jsonschema:"user password, a strong random password is generated when empty"`
+	SpeedLimit  int64             `json:"speed_limit,omitempty" jsonschema:"speed limit in bytes per second, -1 for unlimited"`
+	AllowTunnel bool              `json:"allow_tunnel,omitempty" jsonschema:"allow the user to open tunnels"`
+	AllowClient bool              `json:"allow_client,omitempty" jsonschema:"allow the user to connect as a tunnel client"`
+	AllowSSH    bool              `json:"allow_ssh,omitempty" jsonschema:"allow SSH access"`
+	AllowWebDAV bool              `json:"allow_webdav,omitempty" jsonschema:"allow WebDAV access"`
+	Attrs       map[string]string `json:"attrs,omitempty" jsonschema:"extra user attributes, written as extra columns"` // For any extra attributes
 }
 
 // GenerateAuthUserConfigParams parameters for the tool
 type GenerateAuthUserConfigParams struct {
-	Users []AuthUserParams `json:"users"`
+	Users []AuthUserParams `json:"users" jsonschema:"users to write to auth_user.csv"`
+}
+
+// authTableColumns auth_user.csv 的标准列，Attrs 写在这些列之后
+var authTableColumns = []string{"username", "password", "speed_limit", "allow_tunnel", "allow_client", "allow_ssh", "allow_webdav"}
+
+// generatedPasswordLength 自动生成的密码长度，62个字符中取20个约有119位熵
+const generatedPasswordLength = 20
+
+// GenerateAuthUserConfigInputSchema returns the input schema of generate_auth_user_config
+func GenerateAuthUserConfigInputSchema() *jsonschema.Schema {
+	s := inputSchema[GenerateAuthUserConfigParams](schemaOptions{
+		Required: []string{"users"},
+	})
+	s.Properties["users"].MinItems = jsonschema.Ptr(1)
+	return s
 }
 
 // GenerateAuthUserConfig generates auth_user.csv content
-func GenerateAuthUserConfig(arguments json.RawMessage) (string, error) {
+func GenerateAuthUserConfig(arguments json.RawMessage) (*responses.Result, error) {
 	var params GenerateAuthUserConfigParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
@@ -36,48 +57,152 @@ func GenerateAuthUserConfig(arguments json.RawMessage) (string, error) {
 			"Please provide valid parameters for auth user configuration",
 		)
 	}
-
 	log.Info().Int("users_count", len(params.Users)).Msg("generating auth_user.csv")
 
-	var sb strings.Builder
-	// Header: username,password,speed_limit,allow_tunnel,allow_client,allow_ssh,allow_webdav
-	sb.WriteString("username,password,speed_limit,allow_tunnel,allow_client,allow_ssh,allow_webdav\n")
-
-	for _, u := range params.Users {
-		// Bool to int (0/1)
-		tunnel := 0
-		if u.AllowTunnel {
-			tunnel = 1
+	if len(params.Users) == 0 {
+		return responses.ErrorResponse("No users given", "Please provide at least one user in 'users'")
+	}
+	if err := checkAuthUsers(params.Users); err != nil {
+		return responses.ErrorResponse(err.Error(), "Usernames must be non-empty and unique, and attrs must not reuse a standard column name")
+	}
+
+	// 未提供密码的用户生成随机密码，明文只在本次响应中出现
+	var generated []string
+	users := make([]AuthUserParams, len(params.Users))
+	for i, u := range params.Users {
+		if u.Password == "" {
+			password, err := generatePassword(generatedPasswordLength)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to generate password")
+				return responses.ErrorResponse(fmt.Sprintf("Failed to generate password: %v", err), "")
+			}
+			u.Password = password
+			generated = append(generated, fmt.Sprintf("%s: %s", u.Username, password))
 		}
-		client := 0
-		if u.AllowClient {
-			client = 1
+		users[i] = u
+	}
+
+	columns := append([]string{}, authTableColumns...)
+	columns = append(columns, attrColumns(users)...)
+	content, err := writeAuthTable(columns, users)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to write auth table")
+		return responses.ErrorResponse(fmt.Sprintf("Failed to write auth_user.csv: %v", err), "")
+	}
+
+	description := "Generated auth_user.csv configuration"
+	if len(generated) > 0 {
+		description += "\n\nGenerated passwords, store them now as they are not shown again:\n" + strings.Join(generated, "\n")
+	}
+	return responses.ContentResponse(content, "csv", description)
+}
+
+// checkAuthUsers 检查用户名非空且不重复，Attrs 不与标准列重名
+func checkAuthUsers(users []AuthUserParams) error {
+	seen := make(map[string]bool, len(users))
+	for i, u := range users {
+		if strings.TrimSpace(u.Username) == "" {
+			return fmt.Errorf("users[%d] has an empty username", i)
 		}
-		ssh := 0
-		if u.AllowSSH {
-			ssh = 1
+		if seen[u.Username] {
+			return fmt.Errorf("duplicate username %q", u.Username)
 		}
-		webdav := 0
-		if u.AllowWebDAV {
-			webdav = 1
+		seen[u.Username] = true
+		for key := range u.Attrs {
+			if key == "" || isAuthTableColumn(key) {
+				return fmt.Errorf("user %q has an invalid attr name %q", u.Username, key)
+			}
 		}
+	}
+	return nil
+}
 
-		// Handle default speed limit if not set?
-		// liner example uses -1 or 0. -1 usually means no limit? 0 means default?
-		// Let's assume user provides exact value, or we assume 0.
-		// If user wants -1, they pass -1.
-
-		line := fmt.Sprintf("%s,%s,%d,%d,%d,%d,%d\n",
-			u.Username,
-			u.Password,
-			u.SpeedLimit,
-			tunnel,
-			client,
-			ssh,
-			webdav,
-		)
-		sb.WriteString(line)
+// isAuthTableColumn 判断是否为标准列
+func isAuthTableColumn(name string) bool {
+	for _, column := range authTableColumns {
+		if column == name {
+			return true
+		}
 	}
+	return false
+}
+
+// attrColumns 返回所有用户 Attrs 键的并集，按名称排序以保证输出稳定
+func attrColumns(users []AuthUserParams) []string {
+	seen := map[string]bool{}
+	var columns []string
+	for _, u := range users {
+		for key := range u.Attrs {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// writeAuthTable 按 columns 的顺序写出认证表，字段中的逗号、引号和换行由 encoding/csv 转义
+func writeAuthTable(columns []string, users []AuthUserParams) (string, error) {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	if err := w.Write(columns); err != nil {
+		return "", err
+	}
+	for _, u := range users {
+		if err := w.Write(u.record(columns)); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return sb.String(), w.Error()
+}
+
+// record 按列名返回用户的一行，布尔值写为 0/1
+func (u AuthUserParams) record(columns []string) []string {
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "username":
+			record[i] = u.Username
+		case "password":
+			record[i] = u.Password
+		case "speed_limit":
+			record[i] = strconv.FormatInt(u.SpeedLimit, 10)
+		case "allow_tunnel":
+			record[i] = flagValue(u.AllowTunnel)
+		case "allow_client":
+			record[i] = flagValue(u.AllowClient)
+		case "allow_ssh":
+			record[i] = flagValue(u.AllowSSH)
+		case "allow_webdav":
+			record[i] = flagValue(u.AllowWebDAV)
+		default:
+			record[i] = u.Attrs[column]
+		}
+	}
+	return record
+}
 
-	return responses.SuccessResponse(sb.String(), "Generated auth_user.csv configuration")
+// flagValue 将布尔值写为 0/1
+func flagValue(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// generatePassword 使用 crypto/rand 生成只含字母和数字的随机密码，避免在CSV和shell中转义
+func generatePassword(length int) (string, error) {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", err
+		}
+		b[i] = alphabet[n.Int64()]
+	}
+	return string(b), nil
 }
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/responses"
+)
+
+// generateAuthTable 调用工具并解析生成的CSV
+func generateAuthTable(t *testing.T, params GenerateAuthUserConfigParams) (*responses.Result, [][]string) {
+	t.Helper()
+	raw, err := json.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := GenerateAuthUserConfig(raw)
+	if err != nil {
+		t.Fatalf("GenerateAuthUserConfig() error = %v", err)
+	}
+	if result.IsError {
+		return result, nil
+	}
+	output, ok := result.Structured.(responses.ContentOutput)
+	if !ok {
+		t.Fatalf("unexpected structured output %T", result.Structured)
+	}
+	records, err := csv.NewReader(strings.NewReader(output.Content)).ReadAll()
+	if err != nil {
+		t.Fatalf("generated CSV does not parse: %v\n%s", err, output.Content)
+	}
+	return result, records
+}
+
+func TestGenerateAuthUserConfig(t *testing.T) {
+	t.Run("escapes fields and writes attrs as columns", func(t *testing.T) {
+		_, records := generateAuthTable(t, GenerateAuthUserConfigParams{Users: []AuthUserParams{
+			{Username: "alice", Password: `pa,ss"word`, SpeedLimit: -1, AllowTunnel: true, Attrs: map[string]string{"email": "alice@example.org"}},
+			{Username: "bob", Password: "secret", AllowSSH: true, Attrs: map[string]string{"group": "ops, dev"}},
+		}})
+		want := [][]string{
+			{"username", "password", "speed_limit", "allow_tunnel", "allow_client", "allow_ssh", "allow_webdav", "email", "group"},
+			{"alice", `pa,ss"word`, "-1", "1", "0", "0", "0", "alice@example.org", ""},
+			{"bob", "secret", "0", "0", "0", "1", "0", "", "ops, dev"},
+		}
+		if fmt.Sprint(records) != fmt.Sprint(want) {
+			t.Errorf("records = %q, want %q", records, want)
+		}
+	})
+
+	t.Run("generates strong passwords", func(t *testing.T) {
+		result, records := generateAuthTable(t, GenerateAuthUserConfigParams{Users: []AuthUserParams{{Username: "alice"}, {Username: "bob"}}})
+		alice, bob := records[1][1], records[2][1]
+		if len(alice) != generatedPasswordLength || alice == bob {
+			t.Errorf("generated passwords %q and %q", alice, bob)
+		}
+		if !strings.Contains(result.Text(), "alice: "+alice) {
+			t.Errorf("generated password is not reported:\n%s", result.Text())
+		}
+	})
+
+	t.Run("passwords are written as given", func(t *testing.T) {
+		// liner 读取的格式只能确认明文，不写入它可能无法校验的哈希
+		_, records := generateAuthTable(t, GenerateAuthUserConfigParams{Users: []AuthUserParams{{Username: "alice", Password: "correct horse"}}})
+		if records[1][1] != "correct horse" {
+			t.Errorf("password = %q, want it unchanged", records[1][1])
+		}
+	})
+
+	errorCases := []struct {
+		name   string
+		params GenerateAuthUserConfigParams
+		want   string
+	}{
+		{"duplicate username", GenerateAuthUserConfigParams{Users: []AuthUserParams{{Username: "alice"}, {Username: "alice"}}}, `duplicate username "alice"`},
+		{"empty username", GenerateAuthUserConfigParams{Users: []AuthUserParams{{Password: "x"}}}, "users[0] has an empty username"},
+		{"attr shadows column", GenerateAuthUserConfigParams{Users: []AuthUserParams{{Username: "alice", Attrs: map[string]string{"password": "x"}}}}, `invalid attr name "password"`},
+		{"no users", GenerateAuthUserConfigParams{}, "No users given"},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := generateAuthTable(t, tt.params)
+			if !result.IsError || !strings.Contains(result.Text(), tt.want) {
+				t.Errorf("expected error %q, got:\n%s", tt.want, result.Text())
+			}
+		})
+	}
+}
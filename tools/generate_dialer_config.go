@@ -3,20 +3,36 @@ package tools
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/bensonfx/mcp-liner/internal/dialer"
 	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // GenerateDialerConfigParams generate_dialer_config工具的参数
 type GenerateDialerConfigParams struct {
-	Name    string `json:"name"`    // 拨号器名称，如 "cloud"
-	Type    string `json:"type"`    // 类型：local, socks5, http2, http3, ssh, wss
-	Address string `json:"address"` // 地址，如 "example.com:1080"
+	Name    string            `json:"name" jsonschema:"dialer name referenced by other sections, e.g. cloud"`                                   // 拨号器名称，如 "cloud"
+	Type    string            `json:"type" jsonschema:"dialer type"`                                                                            // 类型：local, socks5, http, https, http2, http3, ssh, wss
+	Address string            `json:"address" jsonschema:"dialer address such as user:pass@host:port, or interface for local"`                  // 地址，如 "example.com:1080"
+	Options map[string]string `json:"options,omitempty" jsonschema:"query options such as insecure, ech, max_clients, resolve, or key for ssh"` // 查询参数，如 {"max_clients": "4"}
+}
+
+// GenerateDialerConfigInputSchema generate_dialer_config工具的输入schema
+func GenerateDialerConfigInputSchema() *jsonschema.Schema {
+	return inputSchema[GenerateDialerConfigParams](schemaOptions{
+		Required: []string{"name", "type"},
+		Enum: map[string][]any{
+			"type": enumValues(dialer.Schemes),
+		},
+	})
 }
 
 // GenerateDialerConfig 生成代理拨号器配置
-func GenerateDialerConfig(arguments json.RawMessage) (string, error) {
+func GenerateDialerConfig(arguments json.RawMessage) (*responses.Result, error) {
 	var params GenerateDialerConfigParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
@@ -33,54 +49,43 @@ func GenerateDialerConfig(arguments json.RawMessage) (string, error) {
 		Msg("generating dialer config")
 
 	// 验证参数
-	if params.Name == "" {
+	if err := dialer.ValidateName(params.Name); err != nil {
 		return responses.ErrorResponse(
-			"Dialer name is required",
+			fmt.Sprintf("Invalid dialer name: %v", err),
 			"Please provide a name for the dialer, e.g., 'cloud', 'proxy'",
 		)
 	}
 
-	validTypes := []string{"local", "socks5", "http2", "http3", "ssh", "wss"}
-	isValidType := false
-	for _, t := range validTypes {
-		if params.Type == t {
-			isValidType = true
-			break
-		}
-	}
-	if !isValidType {
+	if !slices.Contains(dialer.Schemes, params.Type) {
 		return responses.ErrorResponse(
 			fmt.Sprintf("Invalid dialer type: %s", params.Type),
-			fmt.Sprintf("Supported types: %v", validTypes),
+			fmt.Sprintf("Supported types: %v", dialer.Schemes),
 		)
 	}
 
-	// 构建拨号器URL
-	var dialerURL string
-	switch params.Type {
-	case "local":
-		// 本地 dialer 格式必须为 local:// 或 local://interface（如 local://wg0）
-		if params.Address != "" {
-			dialerURL = fmt.Sprintf("local://%s", params.Address)
-		} else {
-			dialerURL = "local://"
+	// 构建并校验拨号器URL，local 的地址为网卡名，可为空（local://）
+	dialerURL, err := dialer.Build(params.Type, params.Address, params.Options)
+	if err != nil {
+		supported := "none"
+		if opts := dialer.Options(params.Type); len(opts) > 0 {
+			supported = strings.Join(opts, ", ")
 		}
-	case "socks5":
-		dialerURL = fmt.Sprintf("socks5://%s", params.Address)
-	case "http2":
-		dialerURL = fmt.Sprintf("http2://%s", params.Address)
-	case "http3":
-		dialerURL = fmt.Sprintf("http3://%s", params.Address)
-	case "ssh":
-		dialerURL = fmt.Sprintf("ssh://%s", params.Address)
-	case "wss":
-		dialerURL = fmt.Sprintf("wss://%s", params.Address)
+		return responses.ErrorResponse(
+			fmt.Sprintf("Invalid %s dialer: %v", params.Type, err),
+			fmt.Sprintf("Expected address format: %s; supported options: %s", addressFormat(params.Type), supported),
+		)
 	}
 
 	// 生成YAML配置片段
-	yamlContent := fmt.Sprintf(`dialer:
-  %s: %s
-`, params.Name, dialerURL)
+	cfg := config.Config{Dialer: map[string]string{params.Name: dialerURL}}
+	yamlContent, err := cfg.ToYAML()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to convert config to YAML")
+		return responses.ErrorResponse(
+			fmt.Sprintf("Failed to generate YAML: %v", err),
+			"",
+		)
+	}
 
 	example := fmt.Sprintf(`Usage example:
 1. Add this dialer configuration to your liner config
@@ -104,3 +109,15 @@ https:
 	log.Info().Str("dialer_name", params.Name).Msg("dialer config generated successfully")
 	return responses.ConfigWithExampleResponse(yamlContent, example)
 }
+
+// addressFormat 返回拨号器类型的地址格式说明
+func addressFormat(scheme string) string {
+	switch scheme {
+	case "local":
+		return "empty, or a network interface such as wg0"
+	case "ssh":
+		return "user@host:port with the key option, or user:password@host:port"
+	default:
+		return "[user:password@]host[:port]"
+	}
+}
@@ -7,20 +7,32 @@ import (
 	"github.com/bensonfx/mcp-liner/internal/config"
 	"github.com/bensonfx/mcp-liner/internal/responses"
 	"github.com/bensonfx/mcp-liner/internal/templates"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // GenerateDNSConfigParams generate_dns_config工具的参数
 type GenerateDNSConfigParams struct {
-	Listen         []string `json:"listen"`          // 监听地址，如 [":53"]
-	ProxyPass      string   `json:"proxy_pass"`      // 上游DNS服务器，如 "https://8.8.8.8/dns-query"
-	PolicyTemplate string   `json:"policy_template"` // Policy模板（go template），用于自定义DNS路由
-	CacheSize      int      `json:"cache_size"`      // DNS缓存大小
-	Log            bool     `json:"log"`             // 是否启用日志
+	Listen         []string `json:"listen" jsonschema:"listen addresses, e.g. [\":53\"]"`                   // 监听地址，如 [":53"]
+	ProxyPass      string   `json:"proxy_pass" jsonschema:"upstream DNS server (DoH, DoT or UDP)"`          // 上游DNS服务器，如 "https://8.8.8.8/dns-query"
+	PolicyTemplate string   `json:"policy_template" jsonschema:"Go template policy for custom DNS routing"` // Policy模板（go template），用于自定义DNS路由
+	CacheSize      int      `json:"cache_size" jsonschema:"number of cached DNS entries"`                   // DNS缓存大小
+	Log            bool     `json:"log" jsonschema:"enable query logging"`                                  // 是否启用日志
+}
+
+// GenerateDNSConfigInputSchema generate_dns_config工具的输入schema
+func GenerateDNSConfigInputSchema() *jsonschema.Schema {
+	return inputSchema[GenerateDNSConfigParams](schemaOptions{
+		Default: map[string]any{
+			"listen":     []string{":53"},
+			"proxy_pass": "https://8.8.8.8/dns-query",
+			"cache_size": 4096,
+		},
+	})
 }
 
 // GenerateDNSConfig 生成DNS配置
-func GenerateDNSConfig(arguments json.RawMessage) (string, error) {
+func GenerateDNSConfig(arguments json.RawMessage) (*responses.Result, error) {
 	var params GenerateDNSConfigParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
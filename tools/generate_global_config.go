@@ -6,19 +6,35 @@ import (
 
 	"github.com/bensonfx/mcp-liner/internal/config"
 	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // GenerateGlobalConfigParams generate_global_config工具的参数
 type GenerateGlobalConfigParams struct {
-	LogLevel     string `json:"log_level"`     // info, debug, warn, error
-	DnsServer    string `json:"dns_server"`    // DNS服务器地址
-	DisableHttp3 bool   `json:"disable_http3"` // 是否禁用HTTP3
-	DialTimeout  int    `json:"dial_timeout"`  // 拨号超时（秒）
+	LogLevel     string `json:"log_level" jsonschema:"log level"`                                                          // info, debug, warn, error
+	DnsServer    string `json:"dns_server" jsonschema:"upstream DNS server, e.g. https://8.8.8.8/dns-query or 8.8.8.8:53"` // DNS服务器地址
+	DisableHttp3 bool   `json:"disable_http3" jsonschema:"disable HTTP/3 support"`                                         // 是否禁用HTTP3
+	DialTimeout  int    `json:"dial_timeout" jsonschema:"dial timeout in seconds"`                                         // 拨号超时（秒）
+}
+
+// GenerateGlobalConfigInputSchema generate_global_config工具的输入schema
+func GenerateGlobalConfigInputSchema() *jsonschema.Schema {
+	defaults := config.NewDefaultGlobalConfig()
+	return inputSchema[GenerateGlobalConfigParams](schemaOptions{
+		Enum: map[string][]any{
+			"log_level": {"trace", "debug", "info", "warn", "error", "fatal", "panic"},
+		},
+		Default: map[string]any{
+			"log_level":    defaults.LogLevel,
+			"dns_server":   defaults.DnsServer,
+			"dial_timeout": defaults.DialTimeout,
+		},
+	})
 }
 
 // GenerateGlobalConfig 生成liner全局配置
-func GenerateGlobalConfig(arguments json.RawMessage) (string, error) {
+func GenerateGlobalConfig(arguments json.RawMessage) (*responses.Result, error) {
 	var params GenerateGlobalConfigParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
@@ -7,23 +7,36 @@ import (
 	"github.com/bensonfx/mcp-liner/internal/config"
 	"github.com/bensonfx/mcp-liner/internal/responses"
 	"github.com/bensonfx/mcp-liner/internal/templates"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // GenerateHTTPConfigParams generate_http_config工具的参数
 type GenerateHTTPConfigParams struct {
-	Listen         []string `json:"listen"`          // 监听地址，如 [":443"]
-	ServerName     []string `json:"server_name"`     // 服务器名称，如 ["example.com"]
-	ForwardPolicy  string   `json:"forward_policy"`  // 转发策略，如 "proxy_pass"
-	PolicyTemplate string   `json:"policy_template"` // Policy模板（go template），如果提供则覆盖forward_policy
-	Dialer         string   `json:"dialer"`          // 拨号器名称，如 "local"
-	DialerURL      string   `json:"dialer_url"`      // 拨号器URL（如果需要配置dialer）
-	EnableTunnel   bool     `json:"enable_tunnel"`   // 是否启用tunnel功能
-	AuthTable      string   `json:"auth_table"`      // 认证表（tunnel模式使用）
+	Listen         []string `json:"listen" jsonschema:"listen addresses, e.g. [\":443\"]"`                                   // 监听地址，如 [":443"]
+	ServerName     []string `json:"server_name" jsonschema:"TLS server names, e.g. [\"example.com\"]"`                       // 服务器名称，如 ["example.com"]
+	ForwardPolicy  string   `json:"forward_policy" jsonschema:"forward policy, e.g. proxy_pass"`                             // 转发策略，如 "proxy_pass"
+	PolicyTemplate string   `json:"policy_template" jsonschema:"Go template policy; overrides forward_policy when provided"` // Policy模板（go template），如果提供则覆盖forward_policy
+	Dialer         string   `json:"dialer" jsonschema:"name of the dialer used for forwarding"`                              // 拨号器名称，如 "local"
+	DialerURL      string   `json:"dialer_url" jsonschema:"dialer URL added to the dialer section, e.g. socks5://host:1080"` // 拨号器URL（如果需要配置dialer）
+	EnableTunnel   bool     `json:"enable_tunnel" jsonschema:"enable tunnel server support on this listener"`                // 是否启用tunnel功能
+	AuthTable      string   `json:"auth_table" jsonschema:"auth table CSV file used when tunnel is enabled"`                 // 认证表（tunnel模式使用）
+}
+
+// GenerateHTTPConfigInputSchema generate_http_config工具的输入schema
+func GenerateHTTPConfigInputSchema() *jsonschema.Schema {
+	return inputSchema[GenerateHTTPConfigParams](schemaOptions{
+		Default: map[string]any{
+			"listen":         []string{":443"},
+			"server_name":    []string{"example.org"},
+			"forward_policy": "proxy_pass",
+			"dialer":         "local",
+		},
+	})
 }
 
 // GenerateHTTPConfig 生成HTTP/HTTPS配置
-func GenerateHTTPConfig(arguments json.RawMessage) (string, error) {
+func GenerateHTTPConfig(arguments json.RawMessage) (*responses.Result, error) {
 	var params GenerateHTTPConfigParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
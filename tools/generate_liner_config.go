@@ -9,17 +9,28 @@ import (
 	"github.com/bensonfx/mcp-liner/internal/responses"
 	"github.com/bensonfx/mcp-liner/internal/templates"
 	"github.com/bensonfx/mcp-liner/internal/validation"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // GenerateLinerConfigParams generate_liner_config工具的参数
 type GenerateLinerConfigParams struct {
-	Template string                 `json:"template"` // http_forward, tunnel, dns, full
-	Params   map[string]interface{} `json:"params"`   // 模板参数
+	Template string                 `json:"template" jsonschema:"scenario template to generate"`                                                    // http_forward, tunnel, dns, full
+	Params   map[string]interface{} `json:"params" jsonschema:"template parameters such as listen, server_name, dialer, dialer_url, remote_listen"` // 模板参数
+}
+
+// GenerateLinerConfigInputSchema generate_liner_config工具的输入schema
+func GenerateLinerConfigInputSchema() *jsonschema.Schema {
+	return inputSchema[GenerateLinerConfigParams](schemaOptions{
+		Required: []string{"template"},
+		Enum: map[string][]any{
+			"template": {"http_forward", "tunnel_server", "tunnel_client", "dns", "full"},
+		},
+	})
 }
 
 // GenerateLinerConfig 生成完整的liner配置文件
-func GenerateLinerConfig(arguments json.RawMessage) (string, error) {
+func GenerateLinerConfig(arguments json.RawMessage) (*responses.Result, error) {
 	var params GenerateLinerConfigParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
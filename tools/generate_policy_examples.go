@@ -3,19 +3,35 @@ package tools
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // GeneratePolicyExamplesParams generate_policy_examples工具的参数
 type GeneratePolicyExamplesParams struct {
-	ConfigType string `json:"config_type"` // http_forward|sni_forward|socks_forward|web_doh|dns
-	PolicyType string `json:"policy_type"` // geoip|geosite|domain_match|ip_range|file_based|fetch_based|custom
+	ConfigType string `json:"config_type" jsonschema:"section the policy is used in"`   // http_forward|sni_forward|socks_forward|web_doh|dns
+	PolicyType string `json:"policy_type" jsonschema:"routing strategy of the example"` // geoip|geosite|domain_match|ip_range|file_based|fetch_based|custom
+}
+
+// GeneratePolicyExamplesInputSchema generate_policy_examples工具的输入schema
+func GeneratePolicyExamplesInputSchema() *jsonschema.Schema {
+	return inputSchema[GeneratePolicyExamplesParams](schemaOptions{
+		Enum: map[string][]any{
+			"config_type": enumValues(PolicyConfigTypes),
+			"policy_type": enumValues(PolicyTypes),
+		},
+		Default: map[string]any{
+			"config_type": "http_forward",
+			"policy_type": "geoip",
+		},
+	})
 }
 
 // GeneratePolicyExamples 生成Policy模板示例和文档
-func GeneratePolicyExamples(arguments json.RawMessage) (string, error) {
+func GeneratePolicyExamples(arguments json.RawMessage) (*responses.Result, error) {
 	var params GeneratePolicyExamplesParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
@@ -38,34 +54,45 @@ func GeneratePolicyExamples(arguments json.RawMessage) (string, error) {
 		params.PolicyType = "geoip"
 	}
 
-	var content string
-
 	// 生成对应的Policy模板示例
-	switch params.PolicyType {
-	case "geoip":
-		content = generateGeoIPPolicyExample(params.ConfigType)
-	case "geosite":
-		content = generateGeositePolicyExample(params.ConfigType)
-	case "domain_match":
-		content = generateDomainMatchPolicyExample(params.ConfigType)
-	case "ip_range":
-		content = generateIPRangePolicyExample(params.ConfigType)
-	case "file_based":
-		content = generateFileBasedPolicyExample(params.ConfigType)
-	case "fetch_based":
-		content = generateFetchBasedPolicyExample(params.ConfigType)
-	case "custom":
-		content = generateCustomPolicyExample(params.ConfigType)
-	default:
+	content, ok := PolicyExample(params.PolicyType, params.ConfigType)
+	if !ok {
 		return responses.ErrorResponse(
 			fmt.Sprintf("Unknown policy_type: %s", params.PolicyType),
-			"Supported types: geoip, geosite, domain_match, ip_range, file_based, fetch_based, custom",
+			fmt.Sprintf("Supported types: %s", strings.Join(PolicyTypes, ", ")),
 		)
 	}
 
 	description := fmt.Sprintf("Policy template example for %s with %s routing", params.ConfigType, params.PolicyType)
 	log.Info().Msg("policy examples generated successfully")
-	return responses.SuccessResponse(content, description)
+	return responses.ContentResponse(content, "go-template", description)
+}
+
+// PolicyTypes 支持的Policy示例类型
+var PolicyTypes = []string{"geoip", "geosite", "domain_match", "ip_range", "file_based", "fetch_based", "custom"}
+
+// PolicyConfigTypes Policy可以使用的配置位置
+var PolicyConfigTypes = []string{"http_forward", "sni_forward", "socks_forward", "web_doh", "dns"}
+
+// policyExamples Policy类型到示例生成函数的映射
+var policyExamples = map[string]func(configType string) string{
+	"geoip":        generateGeoIPPolicyExample,
+	"geosite":      generateGeositePolicyExample,
+	"domain_match": generateDomainMatchPolicyExample,
+	"ip_range":     generateIPRangePolicyExample,
+	"file_based":   generateFileBasedPolicyExample,
+	"fetch_based":  generateFetchBasedPolicyExample,
+	"custom":       generateCustomPolicyExample,
+}
+
+// PolicyExample 返回指定类型的Policy模板示例
+// configType 决定自定义示例中列出的模板上下文
+func PolicyExample(policyType string, configType string) (string, bool) {
+	example, ok := policyExamples[policyType]
+	if !ok {
+		return "", false
+	}
+	return example(configType), true
 }
 
 func generateGeoIPPolicyExample(configType string) string {
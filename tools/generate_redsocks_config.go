@@ -6,19 +6,30 @@ import (
 
 	"github.com/bensonfx/mcp-liner/internal/config"
 	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // GenerateRedsocksConfigParams generate_redsocks_config工具的参数
 type GenerateRedsocksConfigParams struct {
-	Listen    []string `json:"listen"`     // 监听地址，如 [":12345"]
-	Dialer    string   `json:"dialer"`     // 拨号器名称，如 "proxy"
-	DialerURL string   `json:"dialer_url"` // 拨号器URL（可选）
-	Log       bool     `json:"log"`        // 是否启用日志
+	Listen    []string `json:"listen" jsonschema:"listen addresses for redirected traffic, e.g. [\":12345\"]"` // 监听地址，如 [":12345"]
+	Dialer    string   `json:"dialer" jsonschema:"name of the dialer used for redirected connections"`         // 拨号器名称，如 "proxy"
+	DialerURL string   `json:"dialer_url" jsonschema:"dialer URL added to the dialer section"`                 // 拨号器URL（可选）
+	Log       bool     `json:"log" jsonschema:"enable connection logging"`                                     // 是否启用日志
+}
+
+// GenerateRedsocksConfigInputSchema generate_redsocks_config工具的输入schema
+func GenerateRedsocksConfigInputSchema() *jsonschema.Schema {
+	return inputSchema[GenerateRedsocksConfigParams](schemaOptions{
+		Default: map[string]any{
+			"listen": []string{fmt.Sprintf(":%d", defaultRedsocksPort)},
+			"dialer": "proxy",
+		},
+	})
 }
 
 // GenerateRedsocksConfig 生成Redsocks透明代理配置
-func GenerateRedsocksConfig(arguments json.RawMessage) (string, error) {
+func GenerateRedsocksConfig(arguments json.RawMessage) (*responses.Result, error) {
 	var params GenerateRedsocksConfigParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
@@ -36,7 +47,7 @@ func GenerateRedsocksConfig(arguments json.RawMessage) (string, error) {
 
 	// 设置默认值
 	if len(params.Listen) == 0 {
-		params.Listen = []string{":12345"}
+		params.Listen = []string{fmt.Sprintf(":%d", defaultRedsocksPort)}
 	}
 	if params.Dialer == "" {
 		params.Dialer = "proxy"
@@ -3,24 +3,82 @@ package tools
 import (
 	"encoding/json"
 	"fmt"
+	"net/netip"
+	"sort"
 	"strings"
 
 	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // GenerateRedsocksIptablesParams generate_redsocks_iptables工具的参数
 type GenerateRedsocksIptablesParams struct {
-	RedsocksPort int      `json:"redsocks_port"` // Redsocks监听端口
-	LANInterface string   `json:"lan_interface"` // LAN接口名称，如 "mlan0"
-	WANInterface string   `json:"wan_interface"` // WAN接口名称，如 "enp1s0"
-	ProxyPorts   []int    `json:"proxy_ports"`   // 需要代理的端口，如 [80, 443]
-	ExcludeCIDRs []string `json:"exclude_cidrs"` // 排除的CIDR，防止路由循环
-	Format       string   `json:"format"`        // 输出格式: iptables-save|shell-script
+	RedsocksPort int      `json:"redsocks_port" jsonschema:"port the redsocks listener is bound to"`                                                 // Redsocks监听端口
+	LANInterface string   `json:"lan_interface" jsonschema:"LAN interface whose traffic is redirected, e.g. mlan0"`                                  // LAN接口名称，如 "mlan0"
+	WANInterface string   `json:"wan_interface" jsonschema:"WAN interface used for masquerading, e.g. enp1s0"`                                       // WAN接口名称，如 "enp1s0"
+	ProxyPorts   []int    `json:"proxy_ports" jsonschema:"destination ports to redirect"`                                                            // 需要代理的端口，如 [80, 443]
+	ExcludeCIDRs []string `json:"exclude_cidrs" jsonschema:"extra IPv4 or IPv6 CIDRs or addresses to bypass, added to the built-in reserved ranges"` // 排除的CIDR，防止路由循环
+	Format       string   `json:"format" jsonschema:"output format"`                                                                                 // 输出格式: iptables-save|ip6tables-save|shell-script|nft
+	IPv6         bool     `json:"ipv6" jsonschema:"also proxy IPv6 traffic, adds ip6tables commands to shell-script and IPv6 rules to nft output"`
+}
+
+// defaultRedsocksPort 透明代理的默认端口，generate_redsocks_config 与 generate_redsocks_iptables 共用
+const defaultRedsocksPort = 12345
+
+// 默认排除私有网络和特殊地址，防止路由循环
+var (
+	defaultExcludesV4 = []string{
+		"0.0.0.0/8",      // 当前网络
+		"10.0.0.0/8",     // 私有网络A
+		"127.0.0.0/8",    // 本地回环
+		"169.254.0.0/16", // 链路本地
+		"172.16.0.0/12",  // 私有网络B
+		"192.168.0.0/16", // 私有网络C
+		"224.0.0.0/4",    // 组播
+		"240.0.0.0/4",    // 保留
+	}
+	defaultExcludesV6 = []string{
+		"::/128",            // 未指定地址
+		"::1/128",           // 本地回环
+		"::ffff:0.0.0.0/96", // IPv4映射地址
+		"100::/64",          // 丢弃前缀
+		"2001:db8::/32",     // 文档示例
+		"fc00::/7",          // 唯一本地地址
+		"fe80::/10",         // 链路本地
+		"ff00::/8",          // 组播
+	}
+)
+
+// ipFamily iptables 规则集的地址族
+type ipFamily struct {
+	iptables string         // 规则命令: iptables 或 ip6tables
+	excludes []netip.Prefix // 不经过redsocks的地址
+}
+
+// GenerateRedsocksIptablesInputSchema generate_redsocks_iptables工具的输入schema
+func GenerateRedsocksIptablesInputSchema() *jsonschema.Schema {
+	s := inputSchema[GenerateRedsocksIptablesParams](schemaOptions{
+		Enum: map[string][]any{
+			"format": {"iptables-save", "ip6tables-save", "shell-script", "nft"},
+		},
+		Default: map[string]any{
+			"redsocks_port": defaultRedsocksPort,
+			"lan_interface": "eth0",
+			"wan_interface": "eth1",
+			"proxy_ports":   []int{80, 443},
+			"format":        "iptables-save",
+		},
+	})
+	s.Properties["redsocks_port"].Minimum = jsonschema.Ptr(1.0)
+	s.Properties["redsocks_port"].Maximum = jsonschema.Ptr(65535.0)
+	s.Properties["proxy_ports"].Items.Minimum = jsonschema.Ptr(1.0)
+	s.Properties["proxy_ports"].Items.Maximum = jsonschema.Ptr(65535.0)
+	return s
 }
 
 // GenerateRedsocksIptables 生成Redsocks iptables规则
-func GenerateRedsocksIptables(arguments json.RawMessage) (string, error) {
+func GenerateRedsocksIptables(arguments json.RawMessage) (*responses.Result, error) {
 	var params GenerateRedsocksIptablesParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
@@ -35,11 +93,12 @@ func GenerateRedsocksIptables(arguments json.RawMessage) (string, error) {
 		Str("lan_interface", params.LANInterface).
 		Str("wan_interface", params.WANInterface).
 		Str("format", params.Format).
+		Bool("ipv6", params.IPv6).
 		Msg("generating redsocks iptables rules")
 
 	// 设置默认值
 	if params.RedsocksPort == 0 {
-		params.RedsocksPort = 12345
+		params.RedsocksPort = defaultRedsocksPort
 	}
 	if params.LANInterface == "" {
 		params.LANInterface = "eth0"
@@ -54,40 +113,43 @@ func GenerateRedsocksIptables(arguments json.RawMessage) (string, error) {
 		params.Format = "iptables-save"
 	}
 
-	// 默认排除私有网络和特殊地址，防止路由循环
-	defaultExcludes := []string{
-		"0.0.0.0/8",      // 当前网络
-		"10.0.0.0/8",     // 私有网络A
-		"127.0.0.0/8",    // 本地回环
-		"169.254.0.0/16", // 链路本地
-		"172.16.0.0/12",  // 私有网络B
-		"192.168.0.0/16", // 私有网络C
-		"224.0.0.0/4",    // 组播
-		"240.0.0.0/4",    // 保留
-	}
-
-	// 合并用户提供的排除列表
-	excludeCIDRs := make(map[string]bool)
-	for _, cidr := range defaultExcludes {
-		excludeCIDRs[cidr] = true
-	}
-	for _, cidr := range params.ExcludeCIDRs {
-		if cidr != "" {
-			excludeCIDRs[cidr] = true
-		}
+	// 合并用户提供的排除列表，按地址族排序并合并重叠的CIDR
+	v4, v6, err := excludePrefixes(params.ExcludeCIDRs)
+	if err != nil {
+		return responses.ErrorResponse(
+			err.Error(),
+			"Use CIDR notation such as 203.0.113.0/24 or 2001:db8::/32, or a single address such as 203.0.113.10",
+		)
 	}
+	ipv4 := ipFamily{iptables: "iptables", excludes: v4}
+	ipv6 := ipFamily{iptables: "ip6tables", excludes: v6}
 
 	var content string
+	var families []ipFamily
 
 	switch params.Format {
 	case "iptables-save":
-		content = generateIptablesSaveFormat(params, excludeCIDRs)
+		families = []ipFamily{ipv4}
+		content = generateIptablesSaveFormat(params, ipv4)
+	case "ip6tables-save":
+		families = []ipFamily{ipv6}
+		content = generateIptablesSaveFormat(params, ipv6)
 	case "shell-script":
-		content = generateShellScriptFormat(params, excludeCIDRs)
+		families = []ipFamily{ipv4}
+		if params.IPv6 {
+			families = append(families, ipv6)
+		}
+		content = generateShellScriptFormat(params, families)
+	case "nft":
+		families = []ipFamily{ipv4}
+		if params.IPv6 {
+			families = append(families, ipv6)
+		}
+		content = generateNftFormat(params, ipv4, ipv6)
 	default:
 		return responses.ErrorResponse(
 			fmt.Sprintf("Unknown format: %s", params.Format),
-			"Supported formats: iptables-save, shell-script",
+			"Supported formats: iptables-save, ip6tables-save, shell-script, nft",
 		)
 	}
 
@@ -98,14 +160,26 @@ func GenerateRedsocksIptables(arguments json.RawMessage) (string, error) {
 	description += "3. Test in a safe environment first\n"
 	description += "4. Make sure redsocks is running before applying rules\n\n"
 
-	if params.Format == "iptables-save" {
+	switch params.Format {
+	case "iptables-save", "ip6tables-save":
+		file := "redsocks.rules"
+		if params.Format == "ip6tables-save" {
+			file = "redsocks.rules.v6"
+		}
+		restore := families[0].iptables + "-restore"
 		description += "To apply these rules:\n"
-		description += "1. Save output to a file (e.g., redsocks.rules)\n"
-		description += "2. Test: sudo iptables-restore --test < redsocks.rules\n"
-		description += "3. Apply: sudo iptables-restore < redsocks.rules\n"
+		description += fmt.Sprintf("1. Save output to a file (e.g., %s)\n", file)
+		description += fmt.Sprintf("2. Test: sudo %s --test < %s\n", restore, file)
+		description += fmt.Sprintf("3. Apply: sudo %s < %s\n", restore, file)
 		description += "4. Make persistent (Ubuntu/Debian): sudo apt install iptables-persistent\n"
 		description += "5. Save: sudo netfilter-persistent save\n"
-	} else {
+	case "nft":
+		description += "To apply these rules:\n"
+		description += "1. Save output to a file (e.g., redsocks.nft)\n"
+		description += "2. Test: sudo nft -c -f redsocks.nft\n"
+		description += "3. Apply: sudo nft -f redsocks.nft\n"
+		description += "4. Make persistent: include redsocks.nft from /etc/nftables.conf\n"
+	default:
 		description += "To apply these rules:\n"
 		description += "1. Save output to a file (e.g., setup-redsocks.sh)\n"
 		description += "2. Make executable: chmod +x setup-redsocks.sh\n"
@@ -113,13 +187,73 @@ func GenerateRedsocksIptables(arguments json.RawMessage) (string, error) {
 	}
 
 	log.Info().Msg("redsocks iptables rules generated successfully")
-	return responses.SuccessResponse(content, description)
+	return responses.ContentResponse(content, params.Format, description)
+}
+
+// excludePrefixes 解析并合并默认和用户提供的排除地址，按地址族分开返回
+// 单个地址视为 /32 或 /128，主机位被清零，空字符串被忽略
+func excludePrefixes(cidrs []string) (v4, v6 []netip.Prefix, err error) {
+	entries := append(append(append([]string{}, defaultExcludesV4...), defaultExcludesV6...), cidrs...)
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, err := parseExcludeCIDR(entry)
+		if err != nil {
+			return nil, nil, err
+		}
+		if prefix.Addr().Is4() {
+			v4 = append(v4, prefix)
+		} else {
+			v6 = append(v6, prefix)
+		}
+	}
+	return collapsePrefixes(v4), collapsePrefixes(v6), nil
+}
+
+// parseExcludeCIDR 解析一个CIDR或单个地址，返回清零主机位后的前缀
+func parseExcludeCIDR(entry string) (netip.Prefix, error) {
+	if !strings.Contains(entry, "/") {
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid exclude_cidrs entry %q: not an IP address or CIDR", entry)
+		}
+		if addr.Zone() != "" {
+			return netip.Prefix{}, fmt.Errorf("invalid exclude_cidrs entry %q: zoned addresses are not supported", entry)
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(entry)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid exclude_cidrs entry %q: %v", entry, err)
+	}
+	return prefix.Masked(), nil
 }
 
-func generateIptablesSaveFormat(params GenerateRedsocksIptablesParams, excludeCIDRs map[string]bool) string {
+// collapsePrefixes 按地址和前缀长度排序，去掉重复的和被其他前缀包含的前缀
+func collapsePrefixes(prefixes []netip.Prefix) []netip.Prefix {
+	sort.Slice(prefixes, func(i, j int) bool {
+		if c := prefixes[i].Addr().Compare(prefixes[j].Addr()); c != 0 {
+			return c < 0
+		}
+		return prefixes[i].Bits() < prefixes[j].Bits()
+	})
+	// 排序后与某个前缀重叠的前缀一定被上一个保留的前缀包含
+	var result []netip.Prefix
+	for _, prefix := range prefixes {
+		if n := len(result); n > 0 && result[n-1].Overlaps(prefix) {
+			continue
+		}
+		result = append(result, prefix)
+	}
+	return result
+}
+
+func generateIptablesSaveFormat(params GenerateRedsocksIptablesParams, family ipFamily) string {
 	var b strings.Builder
 
-	b.WriteString("# Generated iptables rules for Redsocks transparent proxy\n")
+	b.WriteString(fmt.Sprintf("# Generated %s rules for Redsocks transparent proxy\n", family.iptables))
 	b.WriteString("# Generated by mcp-liner\n")
 	b.WriteString(fmt.Sprintf("# Redsocks port: %d\n", params.RedsocksPort))
 	b.WriteString(fmt.Sprintf("# LAN interface: %s\n", params.LANInterface))
@@ -136,14 +270,7 @@ func generateIptablesSaveFormat(params GenerateRedsocksIptablesParams, excludeCI
 	b.WriteString("\n")
 
 	// PREROUTING: 将LAN流量导向REDSOCKS链
-	if len(params.ProxyPorts) > 0 {
-		for _, port := range params.ProxyPorts {
-			b.WriteString(fmt.Sprintf("-A PREROUTING -i %s -p tcp --dport %d -j REDSOCKS\n",
-				params.LANInterface, port))
-		}
-	} else {
-		b.WriteString(fmt.Sprintf("-A PREROUTING -i %s -p tcp -j REDSOCKS\n", params.LANInterface))
-	}
+	writeIptablesPrerouting(&b, "", params)
 	b.WriteString("\n")
 
 	// POSTROUTING: 出站流量MASQUERADE
@@ -152,8 +279,8 @@ func generateIptablesSaveFormat(params GenerateRedsocksIptablesParams, excludeCI
 
 	// REDSOCKS链: 排除私有地址，然后重定向到redsocks端口
 	b.WriteString("# REDSOCKS chain: exclude private addresses to prevent routing loops\n")
-	for cidr := range excludeCIDRs {
-		b.WriteString(fmt.Sprintf("-A REDSOCKS -d %s -j RETURN\n", cidr))
+	for _, prefix := range family.excludes {
+		b.WriteString(fmt.Sprintf("-A REDSOCKS -d %s -j RETURN\n", prefix))
 	}
 	b.WriteString("\n")
 	b.WriteString("# Redirect to redsocks port\n")
@@ -179,7 +306,23 @@ func generateIptablesSaveFormat(params GenerateRedsocksIptablesParams, excludeCI
 	return b.String()
 }
 
-func generateShellScriptFormat(params GenerateRedsocksIptablesParams, excludeCIDRs map[string]bool) string {
+// writeIptablesPrerouting 写出将LAN流量导向REDSOCKS链的 PREROUTING 规则
+// prefix 为空时写 iptables-save 格式，否则为 shell 脚本中的命令前缀，如 "iptables -t nat"
+func writeIptablesPrerouting(b *strings.Builder, prefix string, params GenerateRedsocksIptablesParams) {
+	if prefix != "" {
+		prefix += " "
+	}
+	if len(params.ProxyPorts) > 0 {
+		for _, port := range params.ProxyPorts {
+			b.WriteString(fmt.Sprintf("%s-A PREROUTING -i %s -p tcp --dport %d -j REDSOCKS\n",
+				prefix, params.LANInterface, port))
+		}
+	} else {
+		b.WriteString(fmt.Sprintf("%s-A PREROUTING -i %s -p tcp -j REDSOCKS\n", prefix, params.LANInterface))
+	}
+}
+
+func generateShellScriptFormat(params GenerateRedsocksIptablesParams, families []ipFamily) string {
 	var b strings.Builder
 
 	b.WriteString("#!/bin/bash\n")
@@ -194,51 +337,168 @@ func generateShellScriptFormat(params GenerateRedsocksIptablesParams, excludeCID
 	b.WriteString("echo \"Setting up iptables rules for Redsocks...\"\n")
 	b.WriteString("\n")
 
+	for i, family := range families {
+		if i > 0 {
+			b.WriteString(fmt.Sprintf("# ---- %s rules ----\n", family.iptables))
+			b.WriteString("\n")
+		}
+		writeShellScriptRules(&b, params, family)
+	}
+
+	b.WriteString("echo \"Redsocks iptables rules applied successfully!\"\n")
+	b.WriteString("echo \"Current NAT rules:\"\n")
+	for _, family := range families {
+		b.WriteString(fmt.Sprintf("%s -t nat -L -n -v\n", family.iptables))
+	}
+
+	return b.String()
+}
+
+// writeShellScriptRules 写出一个地址族的 shell 命令
+func writeShellScriptRules(b *strings.Builder, params GenerateRedsocksIptablesParams, family ipFamily) {
+	ipt := family.iptables
+
 	// 创建REDSOCKS链
 	b.WriteString("# Create REDSOCKS chain\n")
-	b.WriteString("iptables -t nat -N REDSOCKS 2>/dev/null || iptables -t nat -F REDSOCKS\n")
+	b.WriteString(fmt.Sprintf("%s -t nat -N REDSOCKS 2>/dev/null || %s -t nat -F REDSOCKS\n", ipt, ipt))
 	b.WriteString("\n")
 
 	// 排除私有地址
 	b.WriteString("# Exclude private addresses to prevent routing loops\n")
-	for cidr := range excludeCIDRs {
-		b.WriteString(fmt.Sprintf("iptables -t nat -A REDSOCKS -d %s -j RETURN\n", cidr))
+	for _, prefix := range family.excludes {
+		b.WriteString(fmt.Sprintf("%s -t nat -A REDSOCKS -d %s -j RETURN\n", ipt, prefix))
 	}
 	b.WriteString("\n")
 
 	// 重定向到redsocks
 	b.WriteString("# Redirect to redsocks port\n")
-	b.WriteString(fmt.Sprintf("iptables -t nat -A REDSOCKS -p tcp -j REDIRECT --to-ports %d\n", params.RedsocksPort))
+	b.WriteString(fmt.Sprintf("%s -t nat -A REDSOCKS -p tcp -j REDIRECT --to-ports %d\n", ipt, params.RedsocksPort))
 	b.WriteString("\n")
 
 	// PREROUTING规则
 	b.WriteString("# Add PREROUTING rules\n")
-	if len(params.ProxyPorts) > 0 {
-		for _, port := range params.ProxyPorts {
-			b.WriteString(fmt.Sprintf("iptables -t nat -A PREROUTING -i %s -p tcp --dport %d -j REDSOCKS\n",
-				params.LANInterface, port))
-		}
-	} else {
-		b.WriteString(fmt.Sprintf("iptables -t nat -A PREROUTING -i %s -p tcp -j REDSOCKS\n", params.LANInterface))
-	}
+	writeIptablesPrerouting(b, ipt+" -t nat", params)
 	b.WriteString("\n")
 
 	// POSTROUTING MASQUERADE
 	b.WriteString("# Enable MASQUERADE for outbound traffic\n")
-	b.WriteString(fmt.Sprintf("iptables -t nat -A POSTROUTING -o %s -j MASQUERADE\n", params.WANInterface))
+	b.WriteString(fmt.Sprintf("%s -t nat -A POSTROUTING -o %s -j MASQUERADE\n", ipt, params.WANInterface))
 	b.WriteString("\n")
 
 	// FORWARD规则
 	b.WriteString("# Allow forwarding\n")
-	b.WriteString(fmt.Sprintf("iptables -A FORWARD -i %s -o %s -m state --state NEW,RELATED,ESTABLISHED -j ACCEPT\n",
-		params.LANInterface, params.WANInterface))
-	b.WriteString(fmt.Sprintf("iptables -A FORWARD -i %s -o %s -m state --state RELATED,ESTABLISHED -j ACCEPT\n",
-		params.WANInterface, params.LANInterface))
+	b.WriteString(fmt.Sprintf("%s -A FORWARD -i %s -o %s -m state --state NEW,RELATED,ESTABLISHED -j ACCEPT\n",
+		ipt, params.LANInterface, params.WANInterface))
+	b.WriteString(fmt.Sprintf("%s -A FORWARD -i %s -o %s -m state --state RELATED,ESTABLISHED -j ACCEPT\n",
+		ipt, params.WANInterface, params.LANInterface))
 	b.WriteString("\n")
+}
 
-	b.WriteString("echo \"Redsocks iptables rules applied successfully!\"\n")
-	b.WriteString("echo \"Current NAT rules:\"\n")
-	b.WriteString("iptables -t nat -L -n -v\n")
+// generateNftFormat 生成 nftables 规则集
+// 使用独立的 inet redsocks 表，重复加载时先删除旧表，不影响系统中的其他表
+// IPv6 排除集合总是生成；未开启 ipv6 时 IPv6 流量直接从 redsocks 链返回
+func generateNftFormat(params GenerateRedsocksIptablesParams, ipv4, ipv6 ipFamily) string {
+	var b strings.Builder
+
+	b.WriteString("#!/usr/sbin/nft -f\n")
+	b.WriteString("# Generated nftables rules for Redsocks transparent proxy\n")
+	b.WriteString("# Generated by mcp-liner\n")
+	b.WriteString(fmt.Sprintf("# Redsocks port: %d\n", params.RedsocksPort))
+	b.WriteString(fmt.Sprintf("# LAN interface: %s\n", params.LANInterface))
+	b.WriteString(fmt.Sprintf("# WAN interface: %s\n", params.WANInterface))
+	b.WriteString("\n")
+
+	// 先声明再删除，表不存在时 delete 也不会失败
+	b.WriteString("table inet redsocks\n")
+	b.WriteString("delete table inet redsocks\n")
+	b.WriteString("\n")
+	b.WriteString("table inet redsocks {\n")
+
+	// 排除地址集合，前缀已合并，不会出现重叠的区间
+	b.WriteString("\t# Private and reserved addresses, excluded to prevent routing loops\n")
+	writeNftPrefixSet(&b, "exclude_v4", "ipv4_addr", ipv4.excludes)
+	writeNftPrefixSet(&b, "exclude_v6", "ipv6_addr", ipv6.excludes)
+
+	// 代理端口集合，重复的端口在集合中只能出现一次
+	ports := nftPorts(params.ProxyPorts)
+	if len(ports) > 0 {
+		b.WriteString("\tset proxy_ports {\n")
+		b.WriteString("\t\ttype inet_service\n")
+		b.WriteString(fmt.Sprintf("\t\telements = { %s }\n", strings.Join(ports, ", ")))
+		b.WriteString("\t}\n")
+		b.WriteString("\n")
+	}
+
+	// redsocks链: 排除私有地址，然后重定向到redsocks端口
+	b.WriteString("\tchain redsocks {\n")
+	b.WriteString("\t\tip daddr @exclude_v4 return\n")
+	b.WriteString("\t\tip6 daddr @exclude_v6 return\n")
+	if !params.IPv6 {
+		b.WriteString("\t\tmeta nfproto ipv6 return\n")
+	}
+	b.WriteString(fmt.Sprintf("\t\tmeta l4proto tcp redirect to :%d\n", params.RedsocksPort))
+	b.WriteString("\t}\n")
+	b.WriteString("\n")
+
+	// prerouting: 将LAN流量导向redsocks链
+	b.WriteString("\tchain prerouting {\n")
+	b.WriteString("\t\ttype nat hook prerouting priority dstnat; policy accept;\n")
+	if len(ports) > 0 {
+		b.WriteString(fmt.Sprintf("\t\tiifname %q tcp dport @proxy_ports jump redsocks\n", params.LANInterface))
+	} else {
+		b.WriteString(fmt.Sprintf("\t\tiifname %q meta l4proto tcp jump redsocks\n", params.LANInterface))
+	}
+	b.WriteString("\t}\n")
+	b.WriteString("\n")
+
+	// postrouting: 出站流量masquerade
+	b.WriteString("\tchain postrouting {\n")
+	b.WriteString("\t\ttype nat hook postrouting priority srcnat; policy accept;\n")
+	b.WriteString(fmt.Sprintf("\t\toifname %q masquerade\n", params.WANInterface))
+	b.WriteString("\t}\n")
+	b.WriteString("\n")
+
+	// forward: 允许LAN与WAN之间的转发
+	b.WriteString("\tchain forward {\n")
+	b.WriteString("\t\ttype filter hook forward priority filter; policy accept;\n")
+	b.WriteString(fmt.Sprintf("\t\tiifname %q oifname %q ct state new,related,established accept\n",
+		params.LANInterface, params.WANInterface))
+	b.WriteString(fmt.Sprintf("\t\tiifname %q oifname %q ct state related,established accept\n",
+		params.WANInterface, params.LANInterface))
+	b.WriteString("\t}\n")
+	b.WriteString("}\n")
 
 	return b.String()
 }
+
+// writeNftPrefixSet 写出一个区间集合
+func writeNftPrefixSet(b *strings.Builder, name, typ string, prefixes []netip.Prefix) {
+	b.WriteString(fmt.Sprintf("\tset %s {\n", name))
+	b.WriteString(fmt.Sprintf("\t\ttype %s\n", typ))
+	b.WriteString("\t\tflags interval\n")
+	b.WriteString("\t\telements = {\n")
+	for i, prefix := range prefixes {
+		sep := ","
+		if i == len(prefixes)-1 {
+			sep = ""
+		}
+		b.WriteString(fmt.Sprintf("\t\t\t%s%s\n", prefix, sep))
+	}
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\n")
+}
+
+// nftPorts 返回去重并排序后的端口列表
+func nftPorts(ports []int) []string {
+	sorted := append([]int{}, ports...)
+	sort.Ints(sorted)
+	var result []string
+	for i, port := range sorted {
+		if i > 0 && port == sorted[i-1] {
+			continue
+		}
+		result = append(result, fmt.Sprint(port))
+	}
+	return result
+}
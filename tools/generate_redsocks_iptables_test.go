@@ -0,0 +1,233 @@
+package tools
+
+import (
+	"encoding/json"
+	"flag"
+	"net/netip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/responses"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// TestGenerateRedsocksIptablesGolden 将生成的规则与 testdata/redsocks 下的 golden 文件比较
+// 本机有 nft 时还会用 nft -c 检查语法，非root用户在独立的用户和网络命名空间中运行
+func TestGenerateRedsocksIptablesGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		params GenerateRedsocksIptablesParams
+	}{
+		{
+			name:   "default.nft",
+			params: GenerateRedsocksIptablesParams{Format: "nft"},
+		},
+		{
+			name: "custom.nft",
+			params: GenerateRedsocksIptablesParams{
+				RedsocksPort: 1081,
+				LANInterface: "mlan0",
+				WANInterface: "enp1s0",
+				ProxyPorts:   []int{443, 80, 8080, 443},
+				ExcludeCIDRs: []string{"203.0.113.10/32", "10.0.0.0/8", "198.51.100.0/24"},
+				Format:       "nft",
+			},
+		},
+		{
+			name: "ipv6.nft",
+			params: GenerateRedsocksIptablesParams{
+				Format:       "nft",
+				IPv6:         true,
+				ExcludeCIDRs: []string{"2001:db8:1::/48", "2606:4700::1111"},
+			},
+		},
+		{
+			name: "default.rules",
+			params: GenerateRedsocksIptablesParams{
+				ExcludeCIDRs: []string{"10.1.0.0/16", "203.0.113.77/24", "198.51.100.1"},
+				Format:       "iptables-save",
+			},
+		},
+		{
+			name: "default.rules.v6",
+			params: GenerateRedsocksIptablesParams{
+				ExcludeCIDRs: []string{"2606:4700::1111", "fd00::/8"},
+				Format:       "ip6tables-save",
+			},
+		},
+		{
+			name: "ipv6.sh",
+			params: GenerateRedsocksIptablesParams{
+				LANInterface: "br-lan",
+				WANInterface: "wan",
+				Format:       "shell-script",
+				IPv6:         true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.params)
+			if err != nil {
+				t.Fatalf("failed to marshal params: %v", err)
+			}
+			result, err := GenerateRedsocksIptables(data)
+			if err != nil {
+				t.Fatalf("GenerateRedsocksIptables() unexpected error: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("GenerateRedsocksIptables() returned an error: %s", result.Text())
+			}
+			output, ok := result.Structured.(responses.ContentOutput)
+			if !ok {
+				t.Fatalf("structured output is %T, want responses.ContentOutput", result.Structured)
+			}
+
+			golden := filepath.Join("testdata", "redsocks", tt.name)
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, []byte(output.Content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run go test -update to create it): %v", err)
+			}
+			if output.Content != string(want) {
+				t.Errorf("output differs from %s (run go test -update to refresh it)\ngot:\n%s\nwant:\n%s", golden, output.Content, want)
+			}
+
+			if strings.HasSuffix(tt.name, ".nft") {
+				nftCheck(t, golden)
+			}
+		})
+	}
+}
+
+// nftCheck 使用 nft -c 检查规则文件的语法，nft 不可用或没有权限时跳过
+func nftCheck(t *testing.T, path string) {
+	t.Helper()
+	nft, err := exec.LookPath("nft")
+	if err != nil {
+		t.Skip("nft not installed, skipping syntax check")
+	}
+	cmd := exec.Command(nft, "-c", "-f", path)
+	if os.Geteuid() != 0 {
+		// nft -c 需要 netlink 权限，在新的用户和网络命名空间中以映射的root运行
+		unshare, err := exec.LookPath("unshare")
+		if err != nil {
+			t.Skip("unshare not installed, cannot run nft -c without root")
+		}
+		cmd = exec.Command(unshare, "--user", "--map-root-user", "--net", nft, "-c", "-f", path)
+	}
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return
+	}
+	msg := string(out)
+	if strings.Contains(msg, "Operation not permitted") || strings.Contains(msg, "Permission denied") ||
+		strings.Contains(msg, "unshare failed") || strings.Contains(msg, "Protocol not supported") {
+		t.Skipf("nft -c not permitted in this environment: %s", strings.TrimSpace(msg))
+	}
+	t.Errorf("nft -c -f %s failed: %v\n%s", path, err, msg)
+}
+
+func TestExcludePrefixes(t *testing.T) {
+	tests := []struct {
+		name    string
+		cidrs   []string
+		wantV4  []string // 默认范围之外期望出现的前缀
+		wantV6  []string
+		absent  []string // 被合并或规范化后不应出现的写法
+		wantErr string
+	}{
+		{
+			name:   "host bits are cleared and addresses become host prefixes",
+			cidrs:  []string{"203.0.113.77/24", "198.51.100.1", " 2606:4700::1111 "},
+			wantV4: []string{"203.0.113.0/24", "198.51.100.1/32"},
+			wantV6: []string{"2606:4700::1111/128"},
+			absent: []string{"203.0.113.77/24"},
+		},
+		{
+			name:   "prefixes inside built-in ranges are collapsed",
+			cidrs:  []string{"10.1.0.0/16", "192.168.1.1", "fd00::/8", "10.0.0.0/8"},
+			absent: []string{"10.1.0.0/16", "192.168.1.1/32", "fd00::/8"},
+		},
+		{
+			name:   "nested user prefixes keep the widest",
+			cidrs:  []string{"100.64.1.0/24", "100.64.0.0/10", "100.64.0.0/10"},
+			wantV4: []string{"100.64.0.0/10"},
+			absent: []string{"100.64.1.0/24"},
+		},
+		{
+			name:  "empty entries are ignored",
+			cidrs: []string{"", "  "},
+		},
+		{
+			name:    "prefix length out of range",
+			cidrs:   []string{"10.0.0.0/33"},
+			wantErr: `invalid exclude_cidrs entry "10.0.0.0/33"`,
+		},
+		{
+			name:    "not an address",
+			cidrs:   []string{"example.com"},
+			wantErr: `invalid exclude_cidrs entry "example.com": not an IP address or CIDR`,
+		},
+		{
+			name:    "zoned address",
+			cidrs:   []string{"fe80::1%eth0"},
+			wantErr: "zoned addresses are not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v4, v6, err := excludePrefixes(tt.cidrs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("excludePrefixes() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("excludePrefixes() unexpected error: %v", err)
+			}
+
+			got := map[string]bool{}
+			for _, family := range [][]netip.Prefix{v4, v6} {
+				for i, prefix := range family {
+					got[prefix.String()] = true
+					if i > 0 {
+						prev := family[i-1]
+						if prev.Overlaps(prefix) || prev.Addr().Compare(prefix.Addr()) >= 0 {
+							t.Errorf("prefixes %s and %s are not sorted and disjoint", prev, prefix)
+						}
+					}
+				}
+			}
+			for _, want := range append(append(append([]string{}, defaultExcludesV4...), defaultExcludesV6...), append(tt.wantV4, tt.wantV6...)...) {
+				if !got[want] {
+					t.Errorf("excludePrefixes() missing %s, got %v %v", want, v4, v6)
+				}
+			}
+			for _, prefix := range v6 {
+				if prefix.Addr().Is4() {
+					t.Errorf("IPv4 prefix %s in the IPv6 list", prefix)
+				}
+			}
+			for _, absent := range tt.absent {
+				if got[absent] {
+					t.Errorf("excludePrefixes() should not contain %s", absent)
+				}
+			}
+		})
+	}
+}
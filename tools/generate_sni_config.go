@@ -6,22 +6,33 @@ import (
 
 	"github.com/bensonfx/mcp-liner/internal/config"
 	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // GenerateSniConfigParams generate_sni_config工具的参数
 type GenerateSniConfigParams struct {
-	Enabled     bool   `json:"enabled"`      // 是否启用SNI转发
-	Policy      string `json:"policy"`       // 转发策略（可以是go template）
-	Dialer      string `json:"dialer"`       // 拨号器名称
-	DialerURL   string `json:"dialer_url"`   // 拨号器URL（可选）
-	DisableIpv6 bool   `json:"disable_ipv6"` // 禁用IPv6
-	PreferIpv6  bool   `json:"prefer_ipv6"`  // 优先IPv6
-	Log         bool   `json:"log"`          // 是否启用日志
+	Enabled     bool   `json:"enabled" jsonschema:"enable SNI forwarding"`                                 // 是否启用SNI转发
+	Policy      string `json:"policy" jsonschema:"forward policy, may be a Go template using .ServerName"` // 转发策略（可以是go template）
+	Dialer      string `json:"dialer" jsonschema:"name of the dialer used for forwarding"`                 // 拨号器名称
+	DialerURL   string `json:"dialer_url" jsonschema:"dialer URL added to the dialer section"`             // 拨号器URL（可选）
+	DisableIpv6 bool   `json:"disable_ipv6" jsonschema:"never dial IPv6 addresses"`                        // 禁用IPv6
+	PreferIpv6  bool   `json:"prefer_ipv6" jsonschema:"prefer IPv6 addresses when dialing"`                // 优先IPv6
+	Log         bool   `json:"log" jsonschema:"enable connection logging"`                                 // 是否启用日志
+}
+
+// GenerateSniConfigInputSchema generate_sni_config工具的输入schema
+func GenerateSniConfigInputSchema() *jsonschema.Schema {
+	return inputSchema[GenerateSniConfigParams](schemaOptions{
+		Default: map[string]any{
+			"policy": "proxy_pass",
+			"dialer": "local",
+		},
+	})
 }
 
 // GenerateSniConfig 生成SNI配置
-func GenerateSniConfig(arguments json.RawMessage) (string, error) {
+func GenerateSniConfig(arguments json.RawMessage) (*responses.Result, error) {
 	var params GenerateSniConfigParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
@@ -6,24 +6,37 @@ import (
 
 	"github.com/bensonfx/mcp-liner/internal/config"
 	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // GenerateSSHConfigParams generate_ssh_config工具的参数
 type GenerateSSHConfigParams struct {
-	Listen           []string `json:"listen"`            // 监听地址，如 [":2222"]
-	ServerVersion    string   `json:"server_version"`    // SSH服务版本字符串
-	HostKey          string   `json:"host_key"`          // 主机私钥内容或路径
-	AuthTable        string   `json:"auth_table"`        // 认证表路径
-	AuthorizedKeys   string   `json:"authorized_keys"`   // 公钥认证文件路径
-	Shell            string   `json:"shell"`             // 默认Shell
-	Home             string   `json:"home"`              // 用户主目录模板
-	DisableKeepalive bool     `json:"disable_keepalive"` // 是否禁用Keepalive
-	Log              bool     `json:"log"`               // 是否启用日志
+	Listen           []string `json:"listen" jsonschema:"listen addresses, e.g. [\":2222\"]"`      // 监听地址，如 [":2222"]
+	ServerVersion    string   `json:"server_version" jsonschema:"SSH server version string"`       // SSH服务版本字符串
+	HostKey          string   `json:"host_key" jsonschema:"host private key content or file path"` // 主机私钥内容或路径
+	AuthTable        string   `json:"auth_table" jsonschema:"auth table CSV file"`                 // 认证表路径
+	AuthorizedKeys   string   `json:"authorized_keys" jsonschema:"authorized_keys file path"`      // 公钥认证文件路径
+	Shell            string   `json:"shell" jsonschema:"login shell"`                              // 默认Shell
+	Home             string   `json:"home" jsonschema:"home directory template"`                   // 用户主目录模板
+	DisableKeepalive bool     `json:"disable_keepalive" jsonschema:"disable SSH keepalive"`        // 是否禁用Keepalive
+	Log              bool     `json:"log" jsonschema:"enable connection logging"`                  // 是否启用日志
+}
+
+// GenerateSSHConfigInputSchema generate_ssh_config工具的输入schema
+func GenerateSSHConfigInputSchema() *jsonschema.Schema {
+	return inputSchema[GenerateSSHConfigParams](schemaOptions{
+		Default: map[string]any{
+			"listen":     []string{":2222"},
+			"host_key":   "ssh_host_key",
+			"auth_table": "auth_user.csv",
+			"shell":      "/bin/bash",
+		},
+	})
 }
 
 // GenerateSSHConfig 生成SSH Server配置
-func GenerateSSHConfig(arguments json.RawMessage) (string, error) {
+func GenerateSSHConfig(arguments json.RawMessage) (*responses.Result, error) {
 	var params GenerateSSHConfigParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
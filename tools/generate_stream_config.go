@@ -6,25 +6,41 @@ import (
 
 	"github.com/bensonfx/mcp-liner/internal/config"
 	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // GenerateStreamConfigParams generate_stream_config工具的参数
 type GenerateStreamConfigParams struct {
-	Listen        []string `json:"listen"`         // 监听地址，如 [":3389"]
-	ProxyPass     string   `json:"proxy_pass"`     // 转发目标地址，如 "192.168.1.100:3389"
-	Dialer        string   `json:"dialer"`         // 拨号器名称
-	DialerURL     string   `json:"dialer_url"`     // 拨号器URL（可选）
-	Keyfile       string   `json:"keyfile"`        // TLS密钥文件（可选）
-	Certfile      string   `json:"certfile"`       // TLS证书文件（可选）
-	ProxyProtocol uint     `json:"proxy_protocol"` // PROXY协议版本（0=禁用，1或2=启用）
-	DialTimeout   int      `json:"dial_timeout"`   // 拨号超时（秒）
-	SpeedLimit    int64    `json:"speed_limit"`    // 速度限制（字节/秒）
-	Log           bool     `json:"log"`            // 是否启用日志
+	Listen        []string `json:"listen" jsonschema:"listen addresses, e.g. [\":3389\"]"`                        // 监听地址，如 [":3389"]
+	ProxyPass     string   `json:"proxy_pass" jsonschema:"target address to forward to, e.g. 192.168.1.100:3389"` // 转发目标地址，如 "192.168.1.100:3389"
+	Dialer        string   `json:"dialer" jsonschema:"name of the dialer used to reach the target"`               // 拨号器名称
+	DialerURL     string   `json:"dialer_url" jsonschema:"dialer URL added to the dialer section"`                // 拨号器URL（可选）
+	Keyfile       string   `json:"keyfile" jsonschema:"TLS private key file, enables TLS termination"`            // TLS密钥文件（可选）
+	Certfile      string   `json:"certfile" jsonschema:"TLS certificate file, enables TLS termination"`           // TLS证书文件（可选）
+	ProxyProtocol uint     `json:"proxy_protocol" jsonschema:"PROXY protocol version, 0 disables it"`             // PROXY协议版本（0=禁用，1或2=启用）
+	DialTimeout   int      `json:"dial_timeout" jsonschema:"dial timeout in seconds"`                             // 拨号超时（秒）
+	SpeedLimit    int64    `json:"speed_limit" jsonschema:"speed limit in bytes per second"`                      // 速度限制（字节/秒）
+	Log           bool     `json:"log" jsonschema:"enable connection logging"`                                    // 是否启用日志
+}
+
+// GenerateStreamConfigInputSchema generate_stream_config工具的输入schema
+func GenerateStreamConfigInputSchema() *jsonschema.Schema {
+	return inputSchema[GenerateStreamConfigParams](schemaOptions{
+		Required: []string{"proxy_pass"},
+		Enum: map[string][]any{
+			"proxy_protocol": {0, 1, 2},
+		},
+		Default: map[string]any{
+			"listen":       []string{":8080"},
+			"dialer":       "local",
+			"dial_timeout": 5,
+		},
+	})
 }
 
 // GenerateStreamConfig 生成Stream转发配置
-func GenerateStreamConfig(arguments json.RawMessage) (string, error) {
+func GenerateStreamConfig(arguments json.RawMessage) (*responses.Result, error) {
 	var params GenerateStreamConfigParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
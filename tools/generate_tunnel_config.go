@@ -7,23 +7,34 @@ import (
 	"github.com/bensonfx/mcp-liner/internal/config"
 	"github.com/bensonfx/mcp-liner/internal/responses"
 	"github.com/bensonfx/mcp-liner/internal/templates"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // GenerateTunnelConfigParams generate_tunnel_config工具的参数
 type GenerateTunnelConfigParams struct {
-	Role         string   `json:"role"`          // server 或 client
-	Listen       []string `json:"listen"`        // 监听地址（server模式）
-	ServerName   []string `json:"server_name"`   // 服务器名称（server模式）
-	AuthTable    string   `json:"auth_table"`    // 认证表（server模式）
-	RemoteListen []string `json:"remote_listen"` // 远程监听（client模式）
-	ProxyPass    string   `json:"proxy_pass"`    // 代理目标（client模式）
-	Dialer       string   `json:"dialer"`        // 拨号器名称（client模式）
-	DialerURL    string   `json:"dialer_url"`    // 拨号器URL（client模式）
+	Role         string   `json:"role" jsonschema:"tunnel role: server (public host) or client (private host)"`         // server 或 client
+	Listen       []string `json:"listen" jsonschema:"listen addresses (server role)"`                                   // 监听地址（server模式）
+	ServerName   []string `json:"server_name" jsonschema:"TLS server names (server role)"`                              // 服务器名称（server模式）
+	AuthTable    string   `json:"auth_table" jsonschema:"auth table CSV file (server role)"`                            // 认证表（server模式）
+	RemoteListen []string `json:"remote_listen" jsonschema:"addresses to listen on at the tunnel server (client role)"` // 远程监听（client模式）
+	ProxyPass    string   `json:"proxy_pass" jsonschema:"local address the tunnel forwards to (client role)"`           // 代理目标（client模式）
+	Dialer       string   `json:"dialer" jsonschema:"name of the dialer connecting to the tunnel server (client role)"` // 拨号器名称（client模式）
+	DialerURL    string   `json:"dialer_url" jsonschema:"URL of the tunnel server dialer (client role)"`                // 拨号器URL（client模式）
+}
+
+// GenerateTunnelConfigInputSchema generate_tunnel_config工具的输入schema
+func GenerateTunnelConfigInputSchema() *jsonschema.Schema {
+	return inputSchema[GenerateTunnelConfigParams](schemaOptions{
+		Required: []string{"role"},
+		Enum: map[string][]any{
+			"role": {"server", "client"},
+		},
+	})
 }
 
 // GenerateTunnelConfig 生成内网穿透配置
-func GenerateTunnelConfig(arguments json.RawMessage) (string, error) {
+func GenerateTunnelConfig(arguments json.RawMessage) (*responses.Result, error) {
 	var params GenerateTunnelConfigParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
@@ -6,21 +6,35 @@ import (
 
 	"github.com/bensonfx/mcp-liner/internal/config"
 	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // GenerateWebshellConfigParams generate_webshell_config工具的参数
 type GenerateWebshellConfigParams struct {
-	Listen     []string `json:"listen"`      // 监听地址
-	ServerName []string `json:"server_name"` // 域名
-	Command    string   `json:"command"`     // 执行命令
-	Home       string   `json:"home"`        // Home目录
-	AuthTable  string   `json:"auth_table"`  // 认证表
-	Location   string   `json:"location"`    // URL路径
+	Listen     []string `json:"listen" jsonschema:"listen addresses"`                      // 监听地址
+	ServerName []string `json:"server_name" jsonschema:"TLS server names"`                 // 域名
+	Command    string   `json:"command" jsonschema:"command started for each session"`     // 执行命令
+	Home       string   `json:"home" jsonschema:"home directory of the shell"`             // Home目录
+	AuthTable  string   `json:"auth_table" jsonschema:"auth table CSV file protecting it"` // 认证表
+	Location   string   `json:"location" jsonschema:"URL path of the web shell"`           // URL路径
+}
+
+// GenerateWebshellConfigInputSchema generate_webshell_config工具的输入schema
+func GenerateWebshellConfigInputSchema() *jsonschema.Schema {
+	return inputSchema[GenerateWebshellConfigParams](schemaOptions{
+		Default: map[string]any{
+			"listen":      []string{":443"},
+			"server_name": []string{"shell.example.org"},
+			"command":     "login",
+			"auth_table":  "auth_user.csv",
+			"location":    "/shell/",
+		},
+	})
 }
 
 // GenerateWebshellConfig 生成Web Shell配置
-func GenerateWebshellConfig(arguments json.RawMessage) (string, error) {
+func GenerateWebshellConfig(arguments json.RawMessage) (*responses.Result, error) {
 	var params GenerateWebshellConfigParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
@@ -0,0 +1,469 @@
+package tools
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/phuslu/log"
+)
+
+// manage_auth_table 支持的操作
+const (
+	AuthOpAdd    = "add"
+	AuthOpUpdate = "update"
+	AuthOpRemove = "remove"
+	AuthOpRotate = "rotate_password"
+)
+
+// authOpDisable 禁用用户，liner 的认证表没有禁用标记，该操作会被拒绝
+const authOpDisable = "disable"
+
+// AuthTableOperation manage_auth_table 的一个操作
+type AuthTableOperation struct {
+	Op          string            `json:"op" jsonschema:"operation to apply"`
+	User        *AuthUserParams   `json:"user,omitempty" jsonschema:"user to add, required by add"`
+	Usernames   []string          `json:"usernames,omitempty" jsonschema:"users the operation applies to; update and rotate_password apply to all users when empty"`
+	Password    string            `json:"password,omitempty" jsonschema:"new password for update, only allowed for a single user"`
+	SpeedLimit  *int64            `json:"speed_limit,omitempty" jsonschema:"new speed limit in bytes per second, -1 for unlimited"`
+	AllowTunnel *bool             `json:"allow_tunnel,omitempty" jsonschema:"new allow_tunnel permission"`
+	AllowClient *bool             `json:"allow_client,omitempty" jsonschema:"new allow_client permission"`
+	AllowSSH    *bool             `json:"allow_ssh,omitempty" jsonschema:"new allow_ssh permission"`
+	AllowWebDAV *bool             `json:"allow_webdav,omitempty" jsonschema:"new allow_webdav permission"`
+	Attrs       map[string]string `json:"attrs,omitempty" jsonschema:"extra columns to set, an empty value clears the column"`
+}
+
+// ManageAuthTableParams manage_auth_table工具的参数
+type ManageAuthTableParams struct {
+	TableContent string               `json:"table_content" jsonschema:"existing auth_user.csv content"`
+	Operations   []AuthTableOperation `json:"operations" jsonschema:"operations applied in order"`
+}
+
+// ManageAuthTableInputSchema manage_auth_table工具的输入schema
+func ManageAuthTableInputSchema() *jsonschema.Schema {
+	s := inputSchema[ManageAuthTableParams](schemaOptions{
+		Required: []string{"table_content", "operations"},
+	})
+	s.Properties["operations"].MinItems = jsonschema.Ptr(1)
+	s.Properties["operations"].Items.Properties["op"].Enum = enumValues([]string{AuthOpAdd, AuthOpUpdate, AuthOpRemove, AuthOpRotate})
+	return s
+}
+
+// authRow 认证表中的一行，未修改的行按原样写回
+type authRow struct {
+	user  AuthUserParams
+	raw   []string
+	dirty bool
+}
+
+// authTable 解析后的认证表，columns 保持原文件的列顺序
+type authTable struct {
+	columns []string
+	rows    []*authRow
+}
+
+// ManageAuthTable 修改已有的 auth_user.csv：添加、更新、删除用户和批量轮换密码
+func ManageAuthTable(arguments json.RawMessage) (*responses.Result, error) {
+	var params ManageAuthTableParams
+	if err := json.Unmarshal(arguments, &params); err != nil {
+		log.Error().Err(err).Msg("failed to parse parameters")
+		return responses.ErrorResponse(
+			fmt.Sprintf("Invalid parameters: %v", err),
+			"Please provide 'table_content' with the existing auth_user.csv and a list of 'operations'",
+		)
+	}
+	log.Info().Int("operations", len(params.Operations)).Msg("managing auth table")
+
+	table, err := parseAuthTable(params.TableContent)
+	if err != nil {
+		return responses.ErrorResponse(
+			fmt.Sprintf("Invalid auth table: %v", err),
+			"The table must be CSV with a header row containing at least username and password",
+		)
+	}
+
+	var summary, generated []string
+	for i, op := range params.Operations {
+		changes, passwords, err := table.apply(op)
+		if err != nil {
+			return responses.ErrorResponse(
+				fmt.Sprintf("operations[%d] (%s): %v", i, op.Op, err),
+				"No changes were made; fix the operation and try again",
+			)
+		}
+		summary = append(summary, changes...)
+		generated = append(generated, passwords...)
+	}
+
+	content, err := table.write()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to write auth table")
+		return responses.ErrorResponse(fmt.Sprintf("Failed to write auth_user.csv: %v", err), "")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Updated auth_user.csv\n\nChanges:\n")
+	if len(summary) == 0 {
+		sb.WriteString("(none)\n")
+	}
+	for _, line := range summary {
+		sb.WriteString(line + "\n")
+	}
+	if len(generated) > 0 {
+		sb.WriteString("\nGenerated passwords, store them now as they are not shown again:\n" + strings.Join(generated, "\n"))
+	}
+	return responses.ContentResponse(content, "csv", strings.TrimSpace(sb.String()))
+}
+
+// parseAuthTable 解析认证表，标准列映射到 AuthUserParams 的字段，其余列放入 Attrs
+func parseAuthTable(content string) (*authTable, error) {
+	r := csv.NewReader(strings.NewReader(content))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header row")
+	}
+
+	table := &authTable{columns: records[0]}
+	seen := map[string]bool{}
+	for _, column := range table.columns {
+		if seen[column] {
+			return nil, fmt.Errorf("duplicate column %q", column)
+		}
+		seen[column] = true
+	}
+	if !seen["username"] || !seen["password"] {
+		return nil, fmt.Errorf("header must contain username and password columns, got %q", strings.Join(table.columns, ","))
+	}
+
+	users := map[string]bool{}
+	for i, record := range records[1:] {
+		line := i + 2
+		if len(record) > len(table.columns) {
+			return nil, fmt.Errorf("line %d has %d fields, the header has %d", line, len(record), len(table.columns))
+		}
+		u := AuthUserParams{}
+		for j, value := range record {
+			if err := u.setColumn(table.columns[j], value); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+		}
+		if u.Username == "" {
+			return nil, fmt.Errorf("line %d has an empty username", line)
+		}
+		if users[u.Username] {
+			return nil, fmt.Errorf("line %d: duplicate username %q", line, u.Username)
+		}
+		users[u.Username] = true
+		table.rows = append(table.rows, &authRow{user: u, raw: record})
+	}
+	return table, nil
+}
+
+// setColumn 按列名设置字段，布尔列接受 0/1 和 true/false，空值视为0
+func (u *AuthUserParams) setColumn(column, value string) error {
+	parseFlag := func() (bool, error) {
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "", "0", "false":
+			return false, nil
+		case "1", "true":
+			return true, nil
+		}
+		return false, fmt.Errorf("%s must be 0 or 1, got %q", column, value)
+	}
+
+	var err error
+	switch column {
+	case "username":
+		u.Username = value
+	case "password":
+		u.Password = value
+	case "speed_limit":
+		if strings.TrimSpace(value) != "" {
+			u.SpeedLimit, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return fmt.Errorf("speed_limit must be an integer, got %q", value)
+			}
+		}
+	case "allow_tunnel":
+		u.AllowTunnel, err = parseFlag()
+	case "allow_client":
+		u.AllowClient, err = parseFlag()
+	case "allow_ssh":
+		u.AllowSSH, err = parseFlag()
+	case "allow_webdav":
+		u.AllowWebDAV, err = parseFlag()
+	default:
+		if value != "" {
+			if u.Attrs == nil {
+				u.Attrs = map[string]string{}
+			}
+			u.Attrs[column] = value
+		}
+	}
+	return err
+}
+
+// find 按用户名查找行
+func (t *authTable) find(username string) *authRow {
+	for _, row := range t.rows {
+		if row.user.Username == username {
+			return row
+		}
+	}
+	return nil
+}
+
+// targets 返回操作涉及的行，usernames 为空且 all 为真时返回所有用户
+func (t *authTable) targets(usernames []string, all bool) ([]*authRow, error) {
+	if len(usernames) == 0 {
+		if !all {
+			return nil, fmt.Errorf("usernames is required")
+		}
+		return append([]*authRow(nil), t.rows...), nil
+	}
+	rows := make([]*authRow, 0, len(usernames))
+	for _, name := range usernames {
+		row := t.find(name)
+		if row == nil {
+			return nil, fmt.Errorf("user %q does not exist", name)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// apply 执行一个操作，返回变更说明和生成的密码
+func (t *authTable) apply(op AuthTableOperation) ([]string, []string, error) {
+	var changes, generated []string
+
+	// newPassword 返回新密码，未给出时生成随机密码
+	newPassword := func(username, password string) (string, error) {
+		if password == "" {
+			var err error
+			if password, err = generatePassword(generatedPasswordLength); err != nil {
+				return "", err
+			}
+			generated = append(generated, fmt.Sprintf("%s: %s", username, password))
+		}
+		return password, nil
+	}
+
+	switch op.Op {
+	case AuthOpAdd:
+		if op.User == nil {
+			return nil, nil, fmt.Errorf("user is required")
+		}
+		u := *op.User
+		if err := checkAuthUsers([]AuthUserParams{u}); err != nil {
+			return nil, nil, err
+		}
+		if t.find(u.Username) != nil {
+			return nil, nil, fmt.Errorf("user %q already exists", u.Username)
+		}
+		password, err := newPassword(u.Username, u.Password)
+		if err != nil {
+			return nil, nil, err
+		}
+		u.Password = password
+		t.rows = append(t.rows, &authRow{user: u, dirty: true})
+		changes = append(changes, "+ added "+u.Username)
+
+	case AuthOpRemove:
+		rows, err := t.targets(op.Usernames, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, row := range rows {
+			t.remove(row)
+			changes = append(changes, "- removed "+row.user.Username)
+		}
+
+	case authOpDisable:
+		// liner 按表中的值校验密码，改写密码（如加前缀）得到的只是另一个可用的密码
+		return nil, nil, fmt.Errorf("disable is not supported, liner's auth table has no way to mark a user as disabled; use remove to revoke access")
+
+	case AuthOpRotate:
+		rows, err := t.targets(op.Usernames, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, row := range rows {
+			if row.user.Password, err = newPassword(row.user.Username, ""); err != nil {
+				return nil, nil, err
+			}
+			row.dirty = true
+			changes = append(changes, fmt.Sprintf("~ %s: password rotated", row.user.Username))
+		}
+
+	case AuthOpUpdate:
+		rows, err := t.targets(op.Usernames, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		if op.Password != "" && len(rows) != 1 {
+			return nil, nil, fmt.Errorf("password can only be set for a single user, use rotate_password for several users")
+		}
+		for key := range op.Attrs {
+			if key == "" || isAuthTableColumn(key) {
+				return nil, nil, fmt.Errorf("invalid attr name %q", key)
+			}
+		}
+		for _, row := range rows {
+			fields, err := row.update(op, newPassword)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(fields) > 0 {
+				changes = append(changes, fmt.Sprintf("~ %s: %s", row.user.Username, strings.Join(fields, ", ")))
+			}
+		}
+
+	default:
+		return nil, nil, fmt.Errorf("unknown op %q, expected one of %s, %s, %s or %s", op.Op, AuthOpAdd, AuthOpUpdate, AuthOpRemove, AuthOpRotate)
+	}
+	return changes, generated, nil
+}
+
+// update 修改一行的字段，返回实际变化的字段说明
+func (row *authRow) update(op AuthTableOperation, newPassword func(username, password string) (string, error)) ([]string, error) {
+	u := &row.user
+	var fields []string
+	setFlag := func(name string, field *bool, value *bool) {
+		if value != nil && *field != *value {
+			fields = append(fields, fmt.Sprintf("%s %s→%s", name, flagValue(*field), flagValue(*value)))
+			*field = *value
+		}
+	}
+
+	if op.Password != "" {
+		password, err := newPassword(u.Username, op.Password)
+		if err != nil {
+			return nil, err
+		}
+		u.Password = password
+		fields = append(fields, "password changed")
+	}
+	if op.SpeedLimit != nil && u.SpeedLimit != *op.SpeedLimit {
+		fields = append(fields, fmt.Sprintf("speed_limit %d→%d", u.SpeedLimit, *op.SpeedLimit))
+		u.SpeedLimit = *op.SpeedLimit
+	}
+	setFlag("allow_tunnel", &u.AllowTunnel, op.AllowTunnel)
+	setFlag("allow_client", &u.AllowClient, op.AllowClient)
+	setFlag("allow_ssh", &u.AllowSSH, op.AllowSSH)
+	setFlag("allow_webdav", &u.AllowWebDAV, op.AllowWebDAV)
+
+	keys := make([]string, 0, len(op.Attrs))
+	for key := range op.Attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := op.Attrs[key]
+		if u.Attrs[key] == value {
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%s %q→%q", key, u.Attrs[key], value))
+		if value == "" {
+			delete(u.Attrs, key)
+			continue
+		}
+		if u.Attrs == nil {
+			u.Attrs = map[string]string{}
+		}
+		u.Attrs[key] = value
+	}
+
+	if len(fields) > 0 {
+		row.dirty = true
+	}
+	return fields, nil
+}
+
+// remove 删除一行
+func (t *authTable) remove(target *authRow) {
+	for i, row := range t.rows {
+		if row == target {
+			t.rows = append(t.rows[:i], t.rows[i+1:]...)
+			return
+		}
+	}
+}
+
+// write 按原来的列顺序写出认证表，修改后需要但原表没有的列追加在末尾
+func (t *authTable) write() (string, error) {
+	columns := append([]string{}, t.columns...)
+	has := map[string]bool{}
+	for _, column := range columns {
+		has[column] = true
+	}
+	var missing []string
+	for _, row := range t.rows {
+		if !row.dirty {
+			continue
+		}
+		u := row.user
+		needed := map[string]bool{
+			"speed_limit":  u.SpeedLimit != 0,
+			"allow_tunnel": u.AllowTunnel,
+			"allow_client": u.AllowClient,
+			"allow_ssh":    u.AllowSSH,
+			"allow_webdav": u.AllowWebDAV,
+		}
+		for _, column := range authTableColumns {
+			if needed[column] && !has[column] {
+				has[column] = true
+				missing = append(missing, column)
+			}
+		}
+		for key := range u.Attrs {
+			if !has[key] {
+				has[key] = true
+				missing = append(missing, key)
+			}
+		}
+	}
+	// 标准列按标准顺序在前，其余属性列按名称排序
+	rank := func(column string) int {
+		for i, c := range authTableColumns {
+			if c == column {
+				return i
+			}
+		}
+		return len(authTableColumns)
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		ri, rj := rank(missing[i]), rank(missing[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return missing[i] < missing[j]
+	})
+	columns = append(columns, missing...)
+
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	if err := w.Write(columns); err != nil {
+		return "", err
+	}
+	for _, row := range t.rows {
+		record := row.user.record(columns)
+		if !row.dirty {
+			// 未修改的行保留原始写法，只补齐新增的列
+			record = append(append([]string{}, row.raw...), make([]string, len(columns)-len(row.raw))...)
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return sb.String(), w.Error()
+}
@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/responses"
+)
+
+func TestManageAuthTable(t *testing.T) {
+	const table = "username,password,allow_ssh,email\n" +
+		"alice,secret,1,alice@example.org\n" +
+		"bob,\"pa,ss\",true,\n" +
+		"carol,hunter2,0,\"carol, ops\"\n"
+
+	ptr := func(b bool) *bool { return &b }
+	limit := int64(1048576)
+
+	tests := []struct {
+		name        string
+		params      ManageAuthTableParams
+		wantTable   string
+		wantSummary []string
+		wantErr     string
+	}{
+		{
+			name: "untouched rows keep their formatting",
+			params: ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{
+				{Op: AuthOpUpdate, Usernames: []string{"alice"}, AllowSSH: ptr(false)},
+			}},
+			wantTable: "username,password,allow_ssh,email\n" +
+				"alice,secret,0,alice@example.org\n" +
+				"bob,\"pa,ss\",true,\n" +
+				"carol,hunter2,0,\"carol, ops\"\n",
+			wantSummary: []string{"~ alice: allow_ssh 1→0"},
+		},
+		{
+			name: "add and remove",
+			params: ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{
+				{Op: AuthOpRemove, Usernames: []string{"bob"}},
+				{Op: AuthOpAdd, User: &AuthUserParams{Username: "dave", Password: "pw", AllowSSH: true, Attrs: map[string]string{"email": "d@example.org"}}},
+			}},
+			wantTable: "username,password,allow_ssh,email\n" +
+				"alice,secret,1,alice@example.org\n" +
+				"carol,hunter2,0,\"carol, ops\"\n" +
+				"dave,pw,1,d@example.org\n",
+			wantSummary: []string{"- removed bob", "+ added dave"},
+		},
+		{
+			name: "bulk permission change appends missing columns",
+			params: ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{
+				{Op: AuthOpUpdate, AllowWebDAV: ptr(true), SpeedLimit: &limit, Attrs: map[string]string{"team": "core"}},
+			}},
+			wantTable: "username,password,allow_ssh,email,speed_limit,allow_webdav,team\n" +
+				"alice,secret,1,alice@example.org,1048576,1,core\n" +
+				"bob,\"pa,ss\",1,,1048576,1,core\n" +
+				"carol,hunter2,0,\"carol, ops\",1048576,1,core\n",
+			wantSummary: []string{
+				`~ alice: speed_limit 0→1048576, allow_webdav 0→1, team ""→"core"`,
+				`~ bob: speed_limit 0→1048576, allow_webdav 0→1, team ""→"core"`,
+				`~ carol: speed_limit 0→1048576, allow_webdav 0→1, team ""→"core"`,
+			},
+		},
+		{
+			name: "clear an attr",
+			params: ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{
+				{Op: AuthOpUpdate, Usernames: []string{"carol"}, Attrs: map[string]string{"email": ""}},
+			}},
+			wantTable: "username,password,allow_ssh,email\n" +
+				"alice,secret,1,alice@example.org\n" +
+				"bob,\"pa,ss\",true,\n" +
+				"carol,hunter2,0,\n",
+			wantSummary: []string{`~ carol: email "carol, ops"→""`},
+		},
+		{
+			name:        "no operations change nothing",
+			params:      ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{{Op: AuthOpUpdate, Usernames: []string{"alice"}, AllowSSH: ptr(true)}}},
+			wantTable:   table,
+			wantSummary: []string{"(none)"},
+		},
+		{name: "missing header columns", params: ManageAuthTableParams{TableContent: "name,pass\nalice,x\n", Operations: []AuthTableOperation{{Op: AuthOpRotate}}}, wantErr: "header must contain username and password"},
+		{name: "invalid flag", params: ManageAuthTableParams{TableContent: "username,password,allow_ssh\nalice,x,yes\n", Operations: []AuthTableOperation{{Op: AuthOpRotate}}}, wantErr: "line 2: allow_ssh must be 0 or 1"},
+		{name: "duplicate user in table", params: ManageAuthTableParams{TableContent: "username,password\nalice,x\nalice,y\n", Operations: []AuthTableOperation{{Op: AuthOpRotate}}}, wantErr: `line 3: duplicate username "alice"`},
+		{name: "add existing user", params: ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{{Op: AuthOpAdd, User: &AuthUserParams{Username: "alice"}}}}, wantErr: `user "alice" already exists`},
+		{name: "remove unknown user", params: ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{{Op: AuthOpRemove, Usernames: []string{"zed"}}}}, wantErr: `user "zed" does not exist`},
+		{name: "remove needs usernames", params: ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{{Op: AuthOpRemove}}}, wantErr: "usernames is required"},
+		{name: "shared password", params: ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{{Op: AuthOpUpdate, Password: "x"}}}, wantErr: "password can only be set for a single user"},
+		{name: "unknown op", params: ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{{Op: "rename"}}}, wantErr: `unknown op "rename"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, content := manageAuthTable(t, tt.params)
+			if tt.wantErr != "" {
+				if !result.IsError || !strings.Contains(result.Text(), tt.wantErr) {
+					t.Errorf("expected error %q, got:\n%s", tt.wantErr, result.Text())
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("unexpected error:\n%s", result.Text())
+			}
+			if content != tt.wantTable {
+				t.Errorf("table =\n%s\nwant\n%s", content, tt.wantTable)
+			}
+			for _, line := range tt.wantSummary {
+				if !strings.Contains(result.Text(), line+"\n") {
+					t.Errorf("summary does not contain %q:\n%s", line, result.Text())
+				}
+			}
+		})
+	}
+}
+
+func TestManageAuthTablePasswords(t *testing.T) {
+	const table = "username,password\nalice,secret\nbob,$2a$10$abcdefghijklmnopqrstuv\n"
+
+	// 新密码按原样保存，轮换覆盖所有用户
+	_, content := manageAuthTable(t, ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{
+		{Op: AuthOpRotate, Usernames: []string{"bob"}},
+		{Op: AuthOpUpdate, Usernames: []string{"alice"}, Password: "new password"},
+	}})
+	records := parseRecords(t, content)
+	if records[1][1] != "new password" {
+		t.Errorf("alice password not updated: %q", records[1][1])
+	}
+	if records[2][1] == "$2a$10$abcdefghijklmnopqrstuv" {
+		t.Errorf("bob password not rotated: %q", records[2][1])
+	}
+
+	result, content := manageAuthTable(t, ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{{Op: AuthOpRotate}}})
+	records = parseRecords(t, content)
+	for _, record := range records[1:] {
+		if len(record[1]) != generatedPasswordLength || !strings.Contains(result.Text(), record[0]+": "+record[1]) {
+			t.Errorf("%s: rotated password %q is not reported:\n%s", record[0], record[1], result.Text())
+		}
+	}
+}
+
+// TestManageAuthTableDisable liner 没有禁用标记，改写后的密码本身仍能登录，
+// 因此 disable 必须被拒绝，不能输出任何仍可登录的“已禁用”行
+func TestManageAuthTableDisable(t *testing.T) {
+	const table = "username,password\nalice,secret\n"
+
+	result, content := manageAuthTable(t, ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{
+		{Op: AuthOpUpdate, Usernames: []string{"alice"}, AllowSSH: new(bool)},
+		{Op: "disable", Usernames: []string{"alice"}},
+	}})
+	if !result.IsError || !strings.Contains(result.Text(), "disable is not supported") || !strings.Contains(result.Text(), "use remove") {
+		t.Fatalf("disable should be rejected:\n%s", result.Text())
+	}
+	if content != "" {
+		t.Errorf("rejected operations should not produce a table:\n%s", content)
+	}
+
+	// 删除后表中不再有该用户，任何密码都无法登录
+	_, content = manageAuthTable(t, ManageAuthTableParams{TableContent: table, Operations: []AuthTableOperation{
+		{Op: AuthOpRemove, Usernames: []string{"alice"}},
+	}})
+	if records := parseRecords(t, content); len(records) != 1 {
+		t.Errorf("alice should be removed: %v", records)
+	}
+}
+
+// manageAuthTable 调用工具并返回生成的认证表
+func manageAuthTable(t *testing.T, params ManageAuthTableParams) (*responses.Result, string) {
+	t.Helper()
+	raw, err := json.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ManageAuthTable(raw)
+	if err != nil {
+		t.Fatalf("ManageAuthTable() error = %v", err)
+	}
+	output, _ := result.Structured.(responses.ContentOutput)
+	return result, output.Content
+}
+
+// parseRecords 解析CSV内容
+func parseRecords(t *testing.T, content string) [][]string {
+	t.Helper()
+	table, err := parseAuthTable(content)
+	if err != nil {
+		t.Fatalf("invalid table: %v\n%s", err, content)
+	}
+	records := [][]string{table.columns}
+	for _, row := range table.rows {
+		records = append(records, row.raw)
+	}
+	return records
+}
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/bensonfx/mcp-liner/internal/validation"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/phuslu/log"
+)
+
+// MergeLinerConfigParams merge_liner_config工具的参数
+type MergeLinerConfigParams struct {
+	BaseConfig string   `json:"base_config" jsonschema:"base liner configuration in YAML format, may be empty"`          // 基础配置YAML，可为空
+	Fragments  []string `json:"fragments" jsonschema:"YAML fragments produced by the generate_* tools, merged in order"` // 待合并的配置片段
+}
+
+// MergeLinerConfigInputSchema merge_liner_config工具的输入schema
+func MergeLinerConfigInputSchema() *jsonschema.Schema {
+	s := inputSchema[MergeLinerConfigParams](schemaOptions{
+		Required: []string{"fragments"},
+	})
+	s.Properties["fragments"].MinItems = jsonschema.Ptr(1)
+	return s
+}
+
+// MergeLinerConfig 将多个配置片段合并为一个liner配置
+func MergeLinerConfig(arguments json.RawMessage) (*responses.Result, error) {
+	var params MergeLinerConfigParams
+	if err := json.Unmarshal(arguments, &params); err != nil {
+		log.Error().Err(err).Msg("failed to parse parameters")
+		return responses.ErrorResponse(
+			fmt.Sprintf("Invalid parameters: %v", err),
+			"Please provide 'fragments' and optionally 'base_config' as YAML strings",
+		)
+	}
+
+	log.Info().
+		Bool("has_base", params.BaseConfig != "").
+		Int("fragments", len(params.Fragments)).
+		Msg("merging liner config")
+
+	if len(params.Fragments) == 0 {
+		return responses.ErrorResponse(
+			"At least one fragment is required",
+			"Pass the YAML output of the generate_* tools in 'fragments'",
+		)
+	}
+
+	// 基础配置以文档形式保留，合并结果写回时不丢失注释和键顺序
+	var base *config.Config
+	baseDoc, _ := config.ParseDocument("")
+	if strings.TrimSpace(params.BaseConfig) != "" {
+		doc, cfg, err := parseConfigDocument(params.BaseConfig)
+		if err != nil {
+			return responses.ErrorResponse(
+				fmt.Sprintf("base_config: %v", err),
+				"Please check the YAML syntax of the base configuration",
+			)
+		}
+		baseDoc, base = doc, cfg
+	}
+
+	fragments := make([]*config.Config, 0, len(params.Fragments))
+	for i, fragment := range params.Fragments {
+		cfg, err := parseConfigFragment(fragment)
+		if err != nil {
+			return responses.ErrorResponse(
+				fmt.Sprintf("fragments[%d]: %v", i, err),
+				"Please check the YAML syntax of the fragment",
+			)
+		}
+		fragments = append(fragments, cfg)
+	}
+
+	merged, conflicts := config.Merge(base, fragments...)
+	if len(conflicts) > 0 {
+		messages := make([]string, 0, len(conflicts))
+		for _, c := range conflicts {
+			messages = append(messages, "- "+c.String())
+		}
+		log.Warn().Int("conflicts", len(conflicts)).Msg("merge conflicts found")
+		return responses.ErrorResponse(
+			fmt.Sprintf("Found %d merge conflict(s):\n%s", len(conflicts), strings.Join(messages, "\n")),
+			"Rename conflicting dialers or change duplicate listen addresses, then merge again",
+		)
+	}
+
+	// 未设置全局配置时使用默认值
+	if merged.Global == (config.GlobalConfig{}) {
+		merged.Global = config.NewDefaultGlobalConfig()
+	}
+
+	if err := baseDoc.Update(merged); err != nil {
+		return responses.ErrorResponse(
+			fmt.Sprintf("Failed to update configuration: %v", err),
+			"",
+		)
+	}
+	yamlContent, err := baseDoc.String()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to convert config to YAML")
+		return responses.ErrorResponse(
+			fmt.Sprintf("Failed to generate YAML: %v", err),
+			"",
+		)
+	}
+
+	description := fmt.Sprintf("Merged %d fragment(s) into one liner configuration", len(fragments))
+	if base != nil {
+		description = fmt.Sprintf("Merged %d fragment(s) into the base liner configuration", len(fragments))
+	}
+
+	return responses.SuccessResponse(yamlContent, description)
+}
+
+// parseConfigFragment 检查YAML语法并解析配置片段
+func parseConfigFragment(content string) (*config.Config, error) {
+	if err := validation.ValidateYAML(content); err != nil {
+		return nil, err
+	}
+	cfg, err := config.FromYAML(content)
+	if err != nil {
+		return nil, fmt.Errorf("config parsing error: %w", err)
+	}
+	return cfg, nil
+}
+
+// parseConfigDocument 检查YAML语法并解析配置文档，同时返回解码后的配置
+func parseConfigDocument(content string) (*config.Document, *config.Config, error) {
+	if err := validation.ValidateYAML(content); err != nil {
+		return nil, nil, err
+	}
+	doc, err := config.ParseDocument(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config parsing error: %w", err)
+	}
+	cfg, err := doc.Config()
+	if err != nil {
+		return nil, nil, fmt.Errorf("config parsing error: %w", err)
+	}
+	return doc, cfg, nil
+}
@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/bensonfx/mcp-liner/internal/responses"
+)
+
+func TestMergeLinerConfig(t *testing.T) {
+	dialer := "dialer:\n  cloud: socks5://1.2.3.4:1080\n"
+	stream := "stream:\n  - listen: [':3389']\n    proxy_pass: 10.0.0.1:3389\n    dialer: cloud\n"
+	ssh := "ssh:\n  - listen: [':2222']\n    host_key: ssh_host_key\n"
+
+	tests := []struct {
+		name        string
+		params      MergeLinerConfigParams
+		wantIsError bool
+		wantContain string
+	}{
+		{
+			name: "merge fragments",
+			params: MergeLinerConfigParams{
+				Fragments: []string{dialer, stream, ssh},
+			},
+			wantContain: "proxy_pass: 10.0.0.1:3389",
+		},
+		{
+			name: "merge into base",
+			params: MergeLinerConfigParams{
+				BaseConfig: "global:\n  log_level: debug\n",
+				Fragments:  []string{dialer},
+			},
+			wantContain: "log_level: debug",
+		},
+		{
+			name: "base comments and unknown keys preserved",
+			params: MergeLinerConfigParams{
+				BaseConfig: "# edge node\nglobal:\n  log_level: debug # verbose\n  future_option: true\n",
+				Fragments:  []string{dialer},
+			},
+			wantContain: "# edge node\nglobal:\n  log_level: debug # verbose\n  future_option: true\n",
+		},
+		{
+			name: "dialer conflict",
+			params: MergeLinerConfigParams{
+				BaseConfig: dialer,
+				Fragments:  []string{"dialer:\n  cloud: socks5://9.9.9.9:1080\n"},
+			},
+			wantIsError: true,
+			wantContain: "dialer[cloud]",
+		},
+		{
+			name: "listen conflict",
+			params: MergeLinerConfigParams{
+				Fragments: []string{ssh, "stream:\n  - listen: [':2222']\n    proxy_pass: 127.0.0.1:22\n"},
+			},
+			wantIsError: true,
+			wantContain: "stream[:2222]",
+		},
+		{
+			name: "invalid fragment",
+			params: MergeLinerConfigParams{
+				Fragments: []string{dialer, "stream: ["},
+			},
+			wantIsError: true,
+			wantContain: "fragments[1]",
+		},
+		{
+			name:        "no fragments",
+			params:      MergeLinerConfigParams{BaseConfig: dialer},
+			wantIsError: true,
+			wantContain: "At least one fragment is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonData, err := json.Marshal(tt.params)
+			if err != nil {
+				t.Fatalf("failed to marshal params: %v", err)
+			}
+
+			result, err := MergeLinerConfig(jsonData)
+			if err != nil {
+				t.Fatalf("MergeLinerConfig() unexpected error: %v", err)
+			}
+
+			if result.IsError != tt.wantIsError {
+				t.Errorf("MergeLinerConfig() IsError = %v, want %v: %s", result.IsError, tt.wantIsError, result.Text())
+			}
+
+			if !strings.Contains(result.Text(), tt.wantContain) {
+				t.Errorf("MergeLinerConfig() result does not contain %q:\n%s", tt.wantContain, result.Text())
+			}
+
+			if tt.wantIsError {
+				return
+			}
+			// 合并结果应能重新解析为配置
+			output, ok := result.Structured.(responses.ConfigOutput)
+			if !ok {
+				t.Fatalf("expected ConfigOutput, got %T", result.Structured)
+			}
+			if _, err := config.FromYAML(output.YAML); err != nil {
+				t.Errorf("merged YAML is not a valid config: %v", err)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ func TestGeneratePolicyExamples(t *testing.T) {
 		name        string
 		params      GeneratePolicyExamplesParams
 		wantErr     bool
+		wantIsError bool
 		wantContain string
 	}{
 		{
@@ -92,6 +93,7 @@ func TestGeneratePolicyExamples(t *testing.T) {
 				PolicyType: "invalid",
 			},
 			wantErr:     false, // Error responses are returned as formatted content
+			wantIsError: true,
 			wantContain: "Unknown policy_type",
 		},
 	}
@@ -108,9 +110,13 @@ func TestGeneratePolicyExamples(t *testing.T) {
 				t.Fatalf("GeneratePolicyExamples() unexpected error: %v", err)
 			}
 
-			if !strings.Contains(result, tt.wantContain) {
+			if !strings.Contains(result.Text(), tt.wantContain) {
 				t.Errorf("GeneratePolicyExamples() result does not contain %q", tt.wantContain)
 			}
+
+			if result.IsError != tt.wantIsError {
+				t.Errorf("GeneratePolicyExamples() IsError = %v, want %v", result.IsError, tt.wantIsError)
+			}
 		})
 	}
 }
@@ -120,6 +126,7 @@ func TestGenerateRedsocksConfig(t *testing.T) {
 		name        string
 		params      GenerateRedsocksConfigParams
 		wantErr     bool
+		wantIsError bool
 		wantContain string
 	}{
 		{
@@ -156,9 +163,13 @@ func TestGenerateRedsocksConfig(t *testing.T) {
 				return
 			}
 
-			if !strings.Contains(result, tt.wantContain) {
+			if !strings.Contains(result.Text(), tt.wantContain) {
 				t.Errorf("GenerateRedsocksConfig() result does not contain %q", tt.wantContain)
 			}
+
+			if result.IsError != tt.wantIsError {
+				t.Errorf("GenerateRedsocksConfig() IsError = %v, want %v", result.IsError, tt.wantIsError)
+			}
 		})
 	}
 }
@@ -168,6 +179,7 @@ func TestGenerateRedsocksIptables(t *testing.T) {
 		name        string
 		params      GenerateRedsocksIptablesParams
 		wantErr     bool
+		wantIsError bool
 		wantContain string
 	}{
 		{
@@ -193,6 +205,34 @@ func TestGenerateRedsocksIptables(t *testing.T) {
 			wantErr:     false,
 			wantContain: "#!/bin/bash",
 		},
+		{
+			name: "nft format",
+			params: GenerateRedsocksIptablesParams{
+				RedsocksPort: 12345,
+				LANInterface: "eth0",
+				WANInterface: "eth1",
+				Format:       "nft",
+			},
+			wantErr:     false,
+			wantContain: "table inet redsocks",
+		},
+		{
+			name: "Invalid exclude CIDR",
+			params: GenerateRedsocksIptablesParams{
+				ExcludeCIDRs: []string{"10.0.0.0/8", "192.168.300.0/24"},
+			},
+			wantErr:     false,
+			wantIsError: true,
+			wantContain: `invalid exclude_cidrs entry "192.168.300.0/24"`,
+		},
+		{
+			name: "ip6tables-save format",
+			params: GenerateRedsocksIptablesParams{
+				Format: "ip6tables-save",
+			},
+			wantErr:     false,
+			wantContain: "-A REDSOCKS -d fe80::/10 -j RETURN",
+		},
 		{
 			name: "Contains loop prevention rules",
 			params: GenerateRedsocksIptablesParams{
@@ -218,6 +258,7 @@ func TestGenerateRedsocksIptables(t *testing.T) {
 				Format:       "invalid",
 			},
 			wantErr:     false, // Error responses are returned as formatted content
+			wantIsError: true,
 			wantContain: "Unknown format",
 		},
 	}
@@ -234,9 +275,13 @@ func TestGenerateRedsocksIptables(t *testing.T) {
 				t.Fatalf("GenerateRedsocksIptables() unexpected error: %v", err)
 			}
 
-			if !strings.Contains(result, tt.wantContain) {
+			if !strings.Contains(result.Text(), tt.wantContain) {
 				t.Errorf("GenerateRedsocksIptables() result does not contain %q", tt.wantContain)
 			}
+
+			if result.IsError != tt.wantIsError {
+				t.Errorf("GenerateRedsocksIptables() IsError = %v, want %v", result.IsError, tt.wantIsError)
+			}
 		})
 	}
 }
@@ -246,6 +291,7 @@ func TestGenerateSniConfig(t *testing.T) {
 		name        string
 		params      GenerateSniConfigParams
 		wantErr     bool
+		wantIsError bool
 		wantContain string
 	}{
 		{
@@ -286,9 +332,13 @@ func TestGenerateSniConfig(t *testing.T) {
 				return
 			}
 
-			if !strings.Contains(result, tt.wantContain) {
+			if !strings.Contains(result.Text(), tt.wantContain) {
 				t.Errorf("GenerateSniConfig() result does not contain %q", tt.wantContain)
 			}
+
+			if result.IsError != tt.wantIsError {
+				t.Errorf("GenerateSniConfig() IsError = %v, want %v", result.IsError, tt.wantIsError)
+			}
 		})
 	}
 }
@@ -298,6 +348,7 @@ func TestGenerateStreamConfig(t *testing.T) {
 		name        string
 		params      GenerateStreamConfigParams
 		wantErr     bool
+		wantIsError bool
 		wantContain string
 	}{
 		{
@@ -338,6 +389,7 @@ func TestGenerateStreamConfig(t *testing.T) {
 				Listen: []string{":8080"},
 			},
 			wantErr:     false, // Error responses are returned as formatted content
+			wantIsError: true,
 			wantContain: "proxy_pass is required",
 		},
 	}
@@ -355,9 +407,13 @@ func TestGenerateStreamConfig(t *testing.T) {
 				return
 			}
 
-			if !strings.Contains(result, tt.wantContain) {
+			if !strings.Contains(result.Text(), tt.wantContain) {
 				t.Errorf("GenerateStreamConfig() result does not contain %q", tt.wantContain)
 			}
+
+			if result.IsError != tt.wantIsError {
+				t.Errorf("GenerateStreamConfig() IsError = %v, want %v", result.IsError, tt.wantIsError)
+			}
 		})
 	}
 }
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/bensonfx/mcp-liner/internal/textdiff"
+	"github.com/bensonfx/mcp-liner/internal/validation"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/phuslu/log"
+)
+
+// PatchOperationParams 单个修改操作
+type PatchOperationParams struct {
+	Op    string `json:"op" jsonschema:"operation to apply"`                                                                                         // add|replace|remove
+	Path  string `json:"path" jsonschema:"target path such as https[0].web, dialer.cloud2, tunnel[1].dialer, or a JSON Pointer like /https/0/web/-"` // 目标路径
+	Value any    `json:"value,omitempty" jsonschema:"new value for add/replace; adding a non-array value to an array appends it"`                    // 新值（add/replace）
+}
+
+// PatchLinerConfigParams patch_liner_config工具的参数
+type PatchLinerConfigParams struct {
+	ConfigContent string                 `json:"config_content" jsonschema:"existing liner configuration in YAML format"` // 现有YAML配置
+	Operations    []PatchOperationParams `json:"operations" jsonschema:"operations applied in order"`                     // 修改操作
+}
+
+// PatchLinerConfigInputSchema patch_liner_config工具的输入schema
+func PatchLinerConfigInputSchema() *jsonschema.Schema {
+	s := inputSchema[PatchLinerConfigParams](schemaOptions{
+		Required: []string{"config_content", "operations"},
+	})
+	operations := s.Properties["operations"]
+	operations.MinItems = jsonschema.Ptr(1)
+	operations.Items.Required = []string{"op", "path"}
+	operations.Items.Properties["op"].Enum = []any{config.PatchAdd, config.PatchReplace, config.PatchRemove}
+	operations.Items.AdditionalProperties = nil
+	return s
+}
+
+// PatchLinerConfig 对现有liner配置应用修改操作，返回新配置和差异
+func PatchLinerConfig(arguments json.RawMessage) (*responses.Result, error) {
+	var params PatchLinerConfigParams
+	if err := json.Unmarshal(arguments, &params); err != nil {
+		log.Error().Err(err).Msg("failed to parse parameters")
+		return responses.ErrorResponse(
+			fmt.Sprintf("Invalid parameters: %v", err),
+			"Please provide 'config_content' and 'operations' parameters",
+		)
+	}
+
+	log.Info().
+		Int("operations", len(params.Operations)).
+		Msg("patching liner config")
+
+	if len(params.Operations) == 0 {
+		return responses.ErrorResponse(
+			"At least one operation is required",
+			`Example: {"op": "replace", "path": "tunnel[1].dialer", "value": "cloud2"}`,
+		)
+	}
+
+	doc, cfg, err := parseConfigDocument(params.ConfigContent)
+	if err != nil {
+		return responses.ErrorResponse(
+			fmt.Sprintf("config_content: %v", err),
+			"Please check the YAML syntax of the existing configuration",
+		)
+	}
+
+	ops := make([]config.PatchOperation, 0, len(params.Operations))
+	for _, op := range params.Operations {
+		ops = append(ops, config.PatchOperation{
+			Op:    op.Op,
+			Path:  op.Path,
+			Value: op.Value,
+		})
+	}
+
+	patched, err := config.Patch(cfg, ops)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to apply patch")
+		return responses.ErrorResponse(
+			err.Error(),
+			"Check that each path exists (use [-] or /- to append to a list) and that values match the liner config structure",
+		)
+	}
+
+	// 差异基于重新序列化的原文档，避免格式差异干扰；修改后保留原有注释和键顺序
+	before, err := doc.String()
+	if err != nil {
+		return responses.ErrorResponse(fmt.Sprintf("Failed to generate YAML: %v", err), "")
+	}
+	if err := doc.Update(patched); err != nil {
+		return responses.ErrorResponse(fmt.Sprintf("Failed to update configuration: %v", err), "")
+	}
+	after, err := doc.String()
+	if err != nil {
+		return responses.ErrorResponse(fmt.Sprintf("Failed to generate YAML: %v", err), "")
+	}
+	diff := textdiff.Unified("a/config.yaml", "b/config.yaml", before, after)
+
+	result := validation.ValidateConfig(patched)
+	validation.Annotate(result, after)
+	if !result.Valid {
+		log.Warn().Int("errors", len(result.Errors)).Msg("patched config validation failed")
+	}
+
+	return responses.PatchResponse(after, diff, result)
+}
@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/responses"
+)
+
+func TestPatchLinerConfig(t *testing.T) {
+	existing := `# production
+dialer:
+  cloud: socks5://1.2.3.4:1080
+https:
+  - listen: [':443']
+    server_name: [a.example]
+    forward:
+      policy: proxy_pass
+      dialer: cloud
+tunnel:
+  - remote_listen: ['127.0.0.1:10022']
+    proxy_pass: 127.0.0.1:22
+    dialer: cloud
+`
+
+	tests := []struct {
+		name        string
+		operations  []PatchOperationParams
+		wantIsError bool
+		wantContain []string
+	}{
+		{
+			name: "switch tunnel dialer",
+			operations: []PatchOperationParams{
+				{Op: "add", Path: "dialer.cloud2", Value: "socks5://5.6.7.8:1080"},
+				{Op: "replace", Path: "tunnel[0].dialer", Value: "cloud2"},
+			},
+			wantContain: []string{"-    dialer: cloud\n+    dialer: cloud2", "+  cloud2: socks5://5.6.7.8:1080"},
+		},
+		{
+			name: "add DoH web location",
+			operations: []PatchOperationParams{
+				{Op: "add", Path: "https[0].web", Value: map[string]any{
+					"location": "/dns-query",
+					"doh":      map[string]any{"enabled": true, "proxy_pass": "https://1.1.1.1/dns-query"},
+				}},
+			},
+			wantContain: []string{"+      - location: /dns-query", "valid"},
+		},
+		{
+			name: "patched config fails validation",
+			operations: []PatchOperationParams{
+				{Op: "replace", Path: "tunnel[0].remote_listen", Value: []string{}},
+			},
+			wantIsError: true,
+			wantContain: []string{"validation error", "-  - remote_listen: ['127.0.0.1:10022']"},
+		},
+		{
+			name: "invalid path",
+			operations: []PatchOperationParams{
+				{Op: "replace", Path: "tunnel[3].dialer", Value: "cloud2"},
+			},
+			wantIsError: true,
+			wantContain: []string{"out of range"},
+		},
+		{
+			name:        "no operations",
+			wantIsError: true,
+			wantContain: []string{"At least one operation is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 原有注释和流式列表写法应被保留
+			if !tt.wantIsError {
+				tt.wantContain = append(tt.wantContain, "# production\n", "server_name: [a.example]")
+			}
+			jsonData, err := json.Marshal(PatchLinerConfigParams{
+				ConfigContent: existing,
+				Operations:    tt.operations,
+			})
+			if err != nil {
+				t.Fatalf("failed to marshal params: %v", err)
+			}
+
+			result, err := PatchLinerConfig(jsonData)
+			if err != nil {
+				t.Fatalf("PatchLinerConfig() unexpected error: %v", err)
+			}
+
+			if result.IsError != tt.wantIsError {
+				t.Errorf("PatchLinerConfig() IsError = %v, want %v: %s", result.IsError, tt.wantIsError, result.Text())
+			}
+
+			for _, want := range tt.wantContain {
+				if !strings.Contains(result.Text(), want) {
+					t.Errorf("PatchLinerConfig() result does not contain %q:\n%s", want, result.Text())
+				}
+			}
+		})
+	}
+}
+
+func TestPatchLinerConfigStructuredOutput(t *testing.T) {
+	jsonData, _ := json.Marshal(PatchLinerConfigParams{
+		ConfigContent: "tunnel:\n  - remote_listen: ['127.0.0.1:10022']\n    proxy_pass: 127.0.0.1:22\n    dialer: local\n",
+		Operations:    []PatchOperationParams{{Op: "replace", Path: "tunnel[0].proxy_pass", Value: "127.0.0.1:2222"}},
+	})
+
+	result, err := PatchLinerConfig(jsonData)
+	if err != nil {
+		t.Fatalf("PatchLinerConfig() unexpected error: %v", err)
+	}
+	output, ok := result.Structured.(responses.PatchOutput)
+	if !ok {
+		t.Fatalf("expected PatchOutput, got %T", result.Structured)
+	}
+	if !output.Validation.Valid {
+		t.Errorf("expected valid config, got %+v", output.Validation)
+	}
+	if !strings.Contains(output.YAML, "proxy_pass: 127.0.0.1:2222") {
+		t.Errorf("YAML does not contain patched value:\n%s", output.YAML)
+	}
+	if !strings.HasPrefix(output.Diff, "--- a/config.yaml\n+++ b/config.yaml\n") {
+		t.Errorf("unexpected diff header:\n%s", output.Diff)
+	}
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+)
+
+// schemaOptions 在从参数结构体推导出的输入schema上追加的约束
+// jsonschema.For 会把所有未标记 omitempty 的字段视为必填，
+// 这里用 Required 显式覆盖，并补充枚举值和默认值
+type schemaOptions struct {
+	Required []string         // 必填字段（json名称）
+	Enum     map[string][]any // 字段 -> 允许的取值
+	Default  map[string]any   // 字段 -> 默认值，需与工具内部的默认值保持一致
+}
+
+// inputSchema 根据参数结构体 T 生成工具的输入schema
+// 字段描述来自结构体的 jsonschema tag；T 的类型在编译期确定，推导失败属于编程错误，因此直接panic
+func inputSchema[T any](opts schemaOptions) *jsonschema.Schema {
+	s, err := jsonschema.For[T]()
+	if err != nil {
+		panic(fmt.Sprintf("failed to infer input schema: %v", err))
+	}
+
+	// SDK 校验结构体时会把未填写的可选字段当作额外属性，导致 additionalProperties=false 误报；
+	// 未知参数仍会在解码阶段被 SDK 拒绝（DisallowUnknownFields）
+	s.AdditionalProperties = nil
+
+	s.Required = nil
+	for _, name := range opts.Required {
+		prop, ok := s.Properties[name]
+		if !ok {
+			panic(fmt.Sprintf("required field %q is not a property of %T", name, *new(T)))
+		}
+		// 必填的字符串字段不允许为空
+		if prop.Type == "string" {
+			prop.MinLength = jsonschema.Ptr(1)
+		}
+		s.Required = append(s.Required, name)
+	}
+
+	for name, values := range opts.Enum {
+		prop, ok := s.Properties[name]
+		if !ok {
+			panic(fmt.Sprintf("enum field %q is not a property of %T", name, *new(T)))
+		}
+		prop.Enum = values
+	}
+
+	for name, value := range opts.Default {
+		prop, ok := s.Properties[name]
+		if !ok {
+			panic(fmt.Sprintf("default field %q is not a property of %T", name, *new(T)))
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			panic(fmt.Sprintf("failed to marshal default for %q: %v", name, err))
+		}
+		prop.Default = data
+	}
+
+	return s
+}
+
+// enumValues 将字符串列表转换为schema的枚举值
+func enumValues(values []string) []any {
+	enum := make([]any, 0, len(values))
+	for _, v := range values {
+		enum = append(enum, v)
+	}
+	return enum
+}
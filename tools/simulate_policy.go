@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bensonfx/mcp-liner/internal/config"
+	"github.com/bensonfx/mcp-liner/internal/policy"
+	"github.com/bensonfx/mcp-liner/internal/responses"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/phuslu/log"
+)
+
+// SimulatedRequestParams 模拟请求
+type SimulatedRequestParams struct {
+	Host      string            `json:"host" jsonschema:"target hostname or IP"`                                   // 目标主机
+	Port      int               `json:"port,omitempty" jsonschema:"target port"`                                   // 目标端口
+	ClientIP  string            `json:"client_ip,omitempty" jsonschema:"client IP address, defaults to 127.0.0.1"` // 客户端IP
+	User      string            `json:"user,omitempty" jsonschema:"authenticated username (.User.Username)"`       // 认证用户名
+	UserAttrs map[string]string `json:"user_attrs,omitempty" jsonschema:"user attributes (.User.Attrs)"`           // 用户属性
+	Method    string            `json:"method,omitempty" jsonschema:"HTTP method, defaults to CONNECT"`            // HTTP方法
+	Headers   map[string]string `json:"headers,omitempty" jsonschema:"HTTP request headers"`                       // HTTP请求头
+}
+
+// FetchFixtureParams fetch 函数的模拟响应
+type FetchFixtureParams struct {
+	Status int    `json:"status,omitempty" jsonschema:"HTTP status code, defaults to 200"` // 状态码
+	Body   string `json:"body" jsonschema:"response body"`                                 // 响应内容
+}
+
+// PolicyFixturesParams 离线替身函数使用的数据
+type PolicyFixturesParams struct {
+	Country  map[string]string             `json:"country,omitempty" jsonschema:"IP or CIDR to country code for country() and geoip(), longest prefix wins"` // IP/CIDR -> 国家代码
+	Geosite  map[string]string             `json:"geosite,omitempty" jsonschema:"domain to category for geosite(), matches the domain and its subdomains"`   // 域名 -> 分类
+	DNS      map[string]string             `json:"dns,omitempty" jsonschema:"hostname to IP for dnsResolve() and functions that resolve hosts"`              // 主机名 -> IP
+	Fetch    map[string]FetchFixtureParams `json:"fetch,omitempty" jsonschema:"URL to response for fetch()"`                                                 // URL -> 响应
+	Files    map[string]string             `json:"files,omitempty" jsonschema:"file name to content for inFileLine(), inFileIPSet() and readFile()"`         // 文件名 -> 内容
+	Now      string                        `json:"now,omitempty" jsonschema:"time returned by now() in RFC 3339 format, defaults to the current time"`       // now() 返回的时间
+	Timezone string                        `json:"timezone,omitempty" jsonschema:"IANA time zone applied to now(), e.g. Asia/Shanghai"`                      // 时区
+}
+
+// SimulatePolicyParams simulate_policy工具的参数
+type SimulatePolicyParams struct {
+	Policy        string                   `json:"policy,omitempty" jsonschema:"inline policy template; use either this or policy_path"`                                     // 内联policy模板
+	ConfigContent string                   `json:"config_content,omitempty" jsonschema:"liner configuration in YAML format, used for policy_path and to check dialer names"` // YAML配置
+	PolicyPath    string                   `json:"policy_path,omitempty" jsonschema:"path of the policy in config_content, e.g. https[0].forward.policy or dns[0].policy"`   // 配置中policy的路径
+	Requests      []SimulatedRequestParams `json:"requests" jsonschema:"synthetic requests to evaluate"`                                                                     // 模拟请求
+	Fixtures      PolicyFixturesParams     `json:"fixtures,omitempty" jsonschema:"offline data for country, geosite, dnsResolve, fetch and file functions"`                  // 离线数据
+}
+
+// SimulatePolicyInputSchema simulate_policy工具的输入schema
+func SimulatePolicyInputSchema() *jsonschema.Schema {
+	s := inputSchema[SimulatePolicyParams](schemaOptions{
+		Required: []string{"requests"},
+	})
+	requests := s.Properties["requests"]
+	requests.MinItems = jsonschema.Ptr(1)
+	requests.Items.Required = []string{"host"}
+	requests.Items.AdditionalProperties = nil
+	fixtures := s.Properties["fixtures"]
+	fixtures.Required = nil
+	fixtures.AdditionalProperties = nil
+	fixtures.Properties["fetch"].AdditionalProperties.Required = []string{"body"}
+	return s
+}
+
+// SimulatePolicy 对一组模拟请求执行policy模板，返回每个请求选择的拨号器或动作
+func SimulatePolicy(arguments json.RawMessage) (*responses.Result, error) {
+	var params SimulatePolicyParams
+	if err := json.Unmarshal(arguments, &params); err != nil {
+		log.Error().Err(err).Msg("failed to parse parameters")
+		return responses.ErrorResponse(
+			fmt.Sprintf("Invalid parameters: %v", err),
+			"Please provide 'requests' and either 'policy' or 'config_content' with 'policy_path'",
+		)
+	}
+
+	log.Info().
+		Str("policy_path", params.PolicyPath).
+		Int("requests", len(params.Requests)).
+		Msg("simulating policy")
+
+	if len(params.Requests) == 0 {
+		return responses.ErrorResponse(
+			"At least one request is required",
+			`Example: {"host": "www.youtube.com", "port": 443, "client_ip": "10.0.0.5"}`,
+		)
+	}
+	if (params.Policy == "") == (params.PolicyPath == "") {
+		return responses.ErrorResponse(
+			"Exactly one of 'policy' or 'policy_path' must be provided",
+			"Pass the template inline in 'policy', or pass 'config_content' and a 'policy_path' such as https[0].forward.policy",
+		)
+	}
+
+	// 提供配置时检查拨号器名称
+	var dialers map[string]string
+	policyText := params.Policy
+	if strings.TrimSpace(params.ConfigContent) != "" {
+		cfg, err := parseConfigFragment(params.ConfigContent)
+		if err != nil {
+			return responses.ErrorResponse(
+				fmt.Sprintf("config_content: %v", err),
+				"Please check the YAML syntax of the configuration",
+			)
+		}
+		dialers = cfg.Dialer
+		if dialers == nil {
+			dialers = map[string]string{}
+		}
+		if params.PolicyPath != "" {
+			value, err := config.Lookup(cfg, params.PolicyPath)
+			if err != nil {
+				return responses.ErrorResponse(err.Error(), "Use a path such as https[0].forward.policy, socks[0].forward.policy or dns[0].policy")
+			}
+			text, ok := value.(string)
+			if !ok || text == "" {
+				return responses.ErrorResponse(
+					fmt.Sprintf("%s is not a policy template", params.PolicyPath),
+					"Point policy_path at a policy field such as https[0].forward.policy",
+				)
+			}
+			policyText = text
+		}
+	} else if params.PolicyPath != "" {
+		return responses.ErrorResponse(
+			"'policy_path' requires 'config_content'",
+			"Pass the liner configuration that contains the policy",
+		)
+	}
+
+	sim, err := policy.NewSimulator(params.Fixtures.toFixtures())
+	if err != nil {
+		return responses.ErrorResponse(
+			fmt.Sprintf("Invalid fixtures: %v", err),
+			"Country keys must be IPs or CIDRs, now must be RFC 3339 and timezone an IANA name",
+		)
+	}
+
+	requests := make([]policy.Request, 0, len(params.Requests))
+	for _, r := range params.Requests {
+		requests = append(requests, policy.Request(r))
+	}
+
+	results, err := sim.Run(policyText, requests, dialers)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to parse policy template")
+		return responses.ErrorResponse(
+			fmt.Sprintf("Invalid policy template: %v", err),
+			"Use validate_liner_config to locate template syntax errors",
+		)
+	}
+
+	return responses.SimulationResponse(results, dialers != nil)
+}
+
+// toFixtures 转换为模拟器使用的 fixtures
+func (p PolicyFixturesParams) toFixtures() policy.Fixtures {
+	f := policy.Fixtures{
+		Country:  p.Country,
+		Geosite:  p.Geosite,
+		DNS:      p.DNS,
+		Files:    p.Files,
+		Now:      p.Now,
+		Timezone: p.Timezone,
+	}
+	if len(p.Fetch) > 0 {
+		f.Fetch = make(map[string]policy.FetchResponse, len(p.Fetch))
+		for url, resp := range p.Fetch {
+			f.Fetch[url] = policy.FetchResponse{Status: resp.Status, Body: resp.Body}
+		}
+	}
+	return f
+}
@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/responses"
+)
+
+func TestSimulatePolicy(t *testing.T) {
+	configContent := `dialer:
+  cloud: socks5://1.2.3.4:1080
+https:
+  - listen: [':443']
+    server_name: [a.example]
+    forward:
+      policy: '{{ if eq (geosite .Request.Host) "google" }}cloud{{ else if eq (country .RealIP) "CN" }}direct{{ else }}google_dialer{{ end }}'
+`
+	fixtures := PolicyFixturesParams{
+		Country: map[string]string{"10.0.0.0/8": "CN"},
+		Geosite: map[string]string{"youtube.com": "google"},
+	}
+
+	tests := []struct {
+		name          string
+		params        SimulatePolicyParams
+		wantIsError   bool
+		wantUndefined int
+		wantContain   []string
+	}{
+		{
+			name: "policy from config",
+			params: SimulatePolicyParams{
+				ConfigContent: configContent,
+				PolicyPath:    "https[0].forward.policy",
+				Requests: []SimulatedRequestParams{
+					{Host: "www.youtube.com", Port: 443, ClientIP: "10.0.0.5"},
+					{Host: "www.baidu.com", Port: 443, ClientIP: "10.0.0.5"},
+					{Host: "example.org", ClientIP: "192.0.2.1"},
+				},
+				Fixtures: fixtures,
+			},
+			wantUndefined: 1,
+			wantContain: []string{
+				`1. www.youtube.com:443 from 10.0.0.5 → dialer "cloud"`,
+				`2. www.baidu.com:443 from 10.0.0.5 → action "direct"`,
+				`3. example.org from 192.0.2.1 → dialer "google_dialer" ⚠️ not defined`,
+				"1 picked an undefined dialer",
+			},
+		},
+		{
+			name: "inline policy without config",
+			params: SimulatePolicyParams{
+				Policy:   `{{ if .User.Attrs.vip }}vip{{ else }}normal{{ end }}`,
+				Requests: []SimulatedRequestParams{{Host: "a.example", User: "alice", UserAttrs: map[string]string{"vip": "1"}}},
+			},
+			wantContain: []string{`a.example as alice → dialer "vip"`, "dialer names were not checked"},
+		},
+		{
+			name: "both policy and path",
+			params: SimulatePolicyParams{
+				Policy:        "cloud",
+				ConfigContent: configContent,
+				PolicyPath:    "https[0].forward.policy",
+				Requests:      []SimulatedRequestParams{{Host: "a.example"}},
+			},
+			wantIsError: true,
+			wantContain: []string{"Exactly one of"},
+		},
+		{
+			name: "path without config",
+			params: SimulatePolicyParams{
+				PolicyPath: "https[0].forward.policy",
+				Requests:   []SimulatedRequestParams{{Host: "a.example"}},
+			},
+			wantIsError: true,
+			wantContain: []string{"requires 'config_content'"},
+		},
+		{
+			name: "path not found",
+			params: SimulatePolicyParams{
+				ConfigContent: configContent,
+				PolicyPath:    "socks[0].forward.policy",
+				Requests:      []SimulatedRequestParams{{Host: "a.example"}},
+			},
+			wantIsError: true,
+			wantContain: []string{`"socks" is not set`},
+		},
+		{
+			name: "invalid template",
+			params: SimulatePolicyParams{
+				Policy:   "{{ if }}",
+				Requests: []SimulatedRequestParams{{Host: "a.example"}},
+			},
+			wantIsError: true,
+			wantContain: []string{"Invalid policy template"},
+		},
+		{
+			name:        "no requests",
+			params:      SimulatePolicyParams{Policy: "cloud"},
+			wantIsError: true,
+			wantContain: []string{"At least one request is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonData, err := json.Marshal(tt.params)
+			if err != nil {
+				t.Fatalf("failed to marshal params: %v", err)
+			}
+
+			result, err := SimulatePolicy(jsonData)
+			if err != nil {
+				t.Fatalf("SimulatePolicy() unexpected error: %v", err)
+			}
+			if result.IsError != tt.wantIsError {
+				t.Errorf("SimulatePolicy() IsError = %v, want %v: %s", result.IsError, tt.wantIsError, result.Text())
+			}
+			if output, ok := result.Structured.(responses.SimulationOutput); ok && output.Undefined != tt.wantUndefined {
+				t.Errorf("SimulatePolicy() undefined = %d, want %d", output.Undefined, tt.wantUndefined)
+			}
+			for _, want := range tt.wantContain {
+				if !strings.Contains(result.Text(), want) {
+					t.Errorf("SimulatePolicy() result does not contain %q:\n%s", want, result.Text())
+				}
+			}
+		})
+	}
+}
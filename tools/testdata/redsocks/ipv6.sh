@@ -0,0 +1,71 @@
+#!/bin/bash
+# Generated iptables rules script for Redsocks transparent proxy
+# Generated by mcp-liner
+# Redsocks port: 12345
+# LAN interface: br-lan
+# WAN interface: wan
+
+set -e
+
+echo "Setting up iptables rules for Redsocks..."
+
+# Create REDSOCKS chain
+iptables -t nat -N REDSOCKS 2>/dev/null || iptables -t nat -F REDSOCKS
+
+# Exclude private addresses to prevent routing loops
+iptables -t nat -A REDSOCKS -d 0.0.0.0/8 -j RETURN
+iptables -t nat -A REDSOCKS -d 10.0.0.0/8 -j RETURN
+iptables -t nat -A REDSOCKS -d 127.0.0.0/8 -j RETURN
+iptables -t nat -A REDSOCKS -d 169.254.0.0/16 -j RETURN
+iptables -t nat -A REDSOCKS -d 172.16.0.0/12 -j RETURN
+iptables -t nat -A REDSOCKS -d 192.168.0.0/16 -j RETURN
+iptables -t nat -A REDSOCKS -d 224.0.0.0/4 -j RETURN
+iptables -t nat -A REDSOCKS -d 240.0.0.0/4 -j RETURN
+
+# Redirect to redsocks port
+iptables -t nat -A REDSOCKS -p tcp -j REDIRECT --to-ports 12345
+
+# Add PREROUTING rules
+iptables -t nat -A PREROUTING -i br-lan -p tcp --dport 80 -j REDSOCKS
+iptables -t nat -A PREROUTING -i br-lan -p tcp --dport 443 -j REDSOCKS
+
+# Enable MASQUERADE for outbound traffic
+iptables -t nat -A POSTROUTING -o wan -j MASQUERADE
+
+# Allow forwarding
+iptables -A FORWARD -i br-lan -o wan -m state --state NEW,RELATED,ESTABLISHED -j ACCEPT
+iptables -A FORWARD -i wan -o br-lan -m state --state RELATED,ESTABLISHED -j ACCEPT
+
+# ---- ip6tables rules ----
+
+# Create REDSOCKS chain
+ip6tables -t nat -N REDSOCKS 2>/dev/null || ip6tables -t nat -F REDSOCKS
+
+# Exclude private addresses to prevent routing loops
+ip6tables -t nat -A REDSOCKS -d ::/128 -j RETURN
+ip6tables -t nat -A REDSOCKS -d ::1/128 -j RETURN
+ip6tables -t nat -A REDSOCKS -d ::ffff:0.0.0.0/96 -j RETURN
+ip6tables -t nat -A REDSOCKS -d 100::/64 -j RETURN
+ip6tables -t nat -A REDSOCKS -d 2001:db8::/32 -j RETURN
+ip6tables -t nat -A REDSOCKS -d fc00::/7 -j RETURN
+ip6tables -t nat -A REDSOCKS -d fe80::/10 -j RETURN
+ip6tables -t nat -A REDSOCKS -d ff00::/8 -j RETURN
+
+# Redirect to redsocks port
+ip6tables -t nat -A REDSOCKS -p tcp -j REDIRECT --to-ports 12345
+
+# Add PREROUTING rules
+ip6tables -t nat -A PREROUTING -i br-lan -p tcp --dport 80 -j REDSOCKS
+ip6tables -t nat -A PREROUTING -i br-lan -p tcp --dport 443 -j REDSOCKS
+
+# Enable MASQUERADE for outbound traffic
+ip6tables -t nat -A POSTROUTING -o wan -j MASQUERADE
+
+# Allow forwarding
+ip6tables -A FORWARD -i br-lan -o wan -m state --state NEW,RELATED,ESTABLISHED -j ACCEPT
+ip6tables -A FORWARD -i wan -o br-lan -m state --state RELATED,ESTABLISHED -j ACCEPT
+
+echo "Redsocks iptables rules applied successfully!"
+echo "Current NAT rules:"
+iptables -t nat -L -n -v
+ip6tables -t nat -L -n -v
@@ -7,16 +7,26 @@ import (
 	"github.com/bensonfx/mcp-liner/internal/config"
 	"github.com/bensonfx/mcp-liner/internal/responses"
 	"github.com/bensonfx/mcp-liner/internal/validation"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/phuslu/log"
 )
 
 // ValidateLinerConfigParams validate_liner_config工具的参数
 type ValidateLinerConfigParams struct {
-	ConfigContent string `json:"config_content"` // YAML配置内容
+	ConfigContent string `json:"config_content" jsonschema:"liner configuration in YAML format"`                                                                                            // YAML配置内容
+	CheckFiles    bool   `json:"check_files,omitempty" jsonschema:"also check referenced certificates, keys, auth tables and directories on this machine"`                                  // 是否检查引用的文件
+	BaseDir       string `json:"base_dir,omitempty" jsonschema:"directory that relative file paths are resolved against, defaults to the working directory; files outside it are not read"` // 相对路径的基准目录
+}
+
+// ValidateLinerConfigInputSchema validate_liner_config工具的输入schema
+func ValidateLinerConfigInputSchema() *jsonschema.Schema {
+	return inputSchema[ValidateLinerConfigParams](schemaOptions{
+		Required: []string{"config_content"},
+	})
 }
 
 // ValidateLinerConfig 验证liner配置文件
-func ValidateLinerConfig(arguments json.RawMessage) (string, error) {
+func ValidateLinerConfig(arguments json.RawMessage) (*responses.Result, error) {
 	var params ValidateLinerConfigParams
 	if err := json.Unmarshal(arguments, &params); err != nil {
 		log.Error().Err(err).Msg("failed to parse parameters")
@@ -37,10 +47,17 @@ func ValidateLinerConfig(arguments json.RawMessage) (string, error) {
 		)
 	}
 
-	// 解析配置
-	cfg, err := config.FromYAML(params.ConfigContent)
+	// 严格解析配置，未知键不会被静默丢弃
+	cfg, unknownKeys, err := config.FromYAMLStrict(params.ConfigContent)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to parse config")
+		// 类型错误时按schema给出每个值的路径和位置，比 yaml 的原始错误更清楚
+		result := &validation.ValidationResult{}
+		validation.ValidateUnknownKeys(params.ConfigContent, unknownKeys, result)
+		if err := validation.ValidateSchema(params.ConfigContent, result); err == nil && !result.Valid {
+			validation.Annotate(result, params.ConfigContent)
+			return responses.ValidationResponse(result)
+		}
 		return responses.ErrorResponse(
 			fmt.Sprintf("Config parsing error: %v", err),
 			"Please ensure the YAML structure matches liner configuration format",
@@ -49,6 +66,25 @@ func ValidateLinerConfig(arguments json.RawMessage) (string, error) {
 
 	// 验证配置逻辑
 	result := validation.ValidateConfig(cfg)
+	validation.ValidateUnknownKeys(params.ConfigContent, unknownKeys, result)
+
+	// 按schema检查值的类型
+	if err := validation.ValidateSchema(params.ConfigContent, result); err != nil {
+		log.Error().Err(err).Msg("failed to check config schema")
+		return responses.ErrorResponse(
+			fmt.Sprintf("Schema check error: %v", err),
+			"Please check your YAML syntax and ensure it's properly formatted",
+		)
+	}
+
+	// 检查引用的文件
+	if params.CheckFiles {
+		log.Info().Str("base_dir", params.BaseDir).Msg("checking referenced files")
+		validation.ValidateFiles(cfg, params.BaseDir, result)
+	}
+
+	// 补充行列位置并应用 # mcp-liner:ignore 注释
+	validation.Annotate(result, params.ConfigContent)
 
 	if result.Valid {
 		log.Info().Msg("config validation passed")
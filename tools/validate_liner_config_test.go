@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bensonfx/mcp-liner/internal/responses"
+)
+
+func TestValidateLinerConfigSchema(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      ValidateLinerConfigParams
+		wantIsError bool
+		wantValid   bool
+		wantCodes   []string
+		wantContain string
+	}{
+		{
+			name:      "valid",
+			params:    ValidateLinerConfigParams{ConfigContent: "http:\n  - listen: ['127.0.0.1:80']\n"},
+			wantValid: true,
+		},
+		{
+			name:        "unknown key is reported instead of dropped",
+			params:      ValidateLinerConfigParams{ConfigContent: "http:\n  - listen: ['127.0.0.1:80']\n    forword:\n      policy: proxy_pass\n"},
+			wantIsError: true,
+			wantCodes:   []string{"unknown-key"},
+			wantContain: `http[0].forword** (line 3, column 5): unknown key "forword" in HTTPConfig, did you mean "forward"?`,
+		},
+		{
+			name:        "type error is located",
+			params:      ValidateLinerConfigParams{ConfigContent: "global:\n  log_backups: many\n  log_lvel: info\n"},
+			wantIsError: true,
+			wantCodes:   []string{"unknown-key", "invalid-type"},
+			wantContain: "line 2, column 16",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := json.Marshal(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := ValidateLinerConfig(raw)
+			if err != nil {
+				t.Fatalf("ValidateLinerConfig() error = %v", err)
+			}
+			if result.IsError != tt.wantIsError {
+				t.Fatalf("IsError = %v, want %v: %s", result.IsError, tt.wantIsError, result.Text())
+			}
+			if !strings.Contains(result.Text(), tt.wantContain) {
+				t.Errorf("result does not contain %q:\n%s", tt.wantContain, result.Text())
+			}
+			output, ok := result.Structured.(responses.ValidationOutput)
+			if !ok {
+				if len(tt.wantCodes) > 0 || tt.wantValid {
+					t.Fatalf("unexpected structured output %T", result.Structured)
+				}
+				return
+			}
+			if output.Valid != tt.wantValid {
+				t.Errorf("Valid = %v, want %v", output.Valid, tt.wantValid)
+			}
+			var codes []string
+			for _, e := range output.Errors {
+				if e.Severity == "error" {
+					codes = append(codes, e.Code)
+				}
+			}
+			if strings.Join(codes, ",") != strings.Join(tt.wantCodes, ",") {
+				t.Errorf("error codes = %v, want %v", codes, tt.wantCodes)
+			}
+		})
+	}
+}